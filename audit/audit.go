@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/light-speak/lighthouse/databases"
+	"github.com/light-speak/lighthouse/lighterr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 模型审计
+// 通过 Register 登记的模型（schema 中使用 @audited 时由生成器登记）在创建、更新、删除后，
+// 把变更前后的字段值以及操作人（auth.GetCtxUserId）、IP、User-Agent 写入 audit_logs 表，
+// 审计记录与业务写操作使用同一个连接，处于事务中时随事务一起提交或回滚
+
+// defaultMaxRows 单条语句默认最多审计的记录数
+const defaultMaxRows = 10000
+
+type options struct {
+	exclude    []string
+	connection string
+	perTenant  bool
+	maxRows    int
+}
+
+// Option 审计选项
+type Option func(o *options)
+
+// Exclude 不记录的字段，支持 Go 字段名、列名和 GraphQL 字段名，如密码、令牌
+func Exclude(fields ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, fields...)
+	}
+}
+
+// OnConnection 模型所在的命名连接，History 从该连接读取审计记录，默认使用 default 连接
+func OnConnection(name string) Option {
+	return func(o *options) {
+		o.connection = name
+	}
+}
+
+// OnTenantConnection 模型位于 ctx 中租户对应的命名连接，见 databases.TenantConnection
+func OnTenantConnection() Option {
+	return func(o *options) {
+		o.perTenant = true
+	}
+}
+
+// MaxRows 单条批量更新、删除最多审计的记录数，默认 10000
+// 受影响的记录超过上限时不逐条记录，只输出错误日志，避免把大量记录读入内存
+func MaxRows(n int) Option {
+	return func(o *options) {
+		o.maxRows = n
+	}
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[reflect.Type]*options{}
+)
+
+func init() {
+	databases.RegisterPlugin(plugin{})
+}
+
+// Register 登记需要审计的模型，model 为模型指针，如 &models.User{}
+func Register(model any, opts ...Option) {
+	o := &options{connection: databases.DefaultConnection, maxRows: defaultMaxRows}
+	for _, opt := range opts {
+		opt(o)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[modelType(model)] = o
+}
+
+// registered 返回模型的审计选项，未登记时返回 nil
+func registered(t reflect.Type) *options {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[t]
+}
+
+func modelType(model any) reflect.Type {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
+}
+
+// History 查询一条记录的审计历史，按时间倒序
+// 审计记录与模型写在同一个连接中，从 Register 时指定的连接读取
+func History(ctx context.Context, model any, id any) ([]*Log, error) {
+	conn, err := connection(ctx, model)
+	if err != nil {
+		return nil, lighterr.NewDatabaseError("数据库连接失败", err)
+	}
+	db, err := conn.GetDB(ctx)
+	if err != nil {
+		return nil, lighterr.NewDatabaseError("数据库连接失败", err)
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, lighterr.NewInternalError("模型解析失败", err)
+	}
+
+	logs := make([]*Log, 0)
+	err = db.WithContext(ctx).
+		Where("auditable_type = ? AND auditable_id = ?", stmt.Schema.Name, fmt.Sprint(id)).
+		Order("id DESC").
+		Find(&logs).Error
+	if err != nil {
+		return nil, lighterr.NewDatabaseError("查询失败", err)
+	}
+	return logs, nil
+}
+
+// connection 返回模型所在的连接，未登记的模型使用 default 连接
+func connection(ctx context.Context, model any) (*databases.LightDatabase, error) {
+	o := registered(modelType(model))
+	switch {
+	case o == nil:
+		return databases.Connection(databases.DefaultConnection)
+	case o.perTenant:
+		return databases.TenantConnection(ctx)
+	}
+	return databases.Connection(o.connection)
+}
+
+// excluded 判断字段是否不记录
+func (o *options) excluded(f *schema.Field) bool {
+	for _, name := range o.exclude {
+		if strings.EqualFold(name, f.Name) || strings.EqualFold(name, f.DBName) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/light-speak/lighthouse/databases"
+	"github.com/light-speak/lighthouse/routers/auth"
+	"gorm.io/gorm/logger"
+)
+
+type auditUser struct {
+	ID       uint `gorm:"primaryKey"`
+	Name     string
+	Age      int
+	Password string
+}
+
+// requestContext 经过 auth 中间件得到带用户、IP、User-Agent 的 ctx
+func requestContext(t *testing.T) context.Context {
+	t.Helper()
+	var ctx context.Context
+	handler := auth.XUserMiddleware()(auth.AdminAuthMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})))
+	r := httptest.NewRequest(http.MethodPost, "/query", nil)
+	r.RemoteAddr = "10.0.0.1"
+	r.Header.Set("X-User-Id", "7")
+	r.Header.Set("User-Agent", "lighthouse-test")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	return ctx
+}
+
+func TestAudit(t *testing.T) {
+	cfg := databases.DefaultConfig()
+	cfg.Driver = databases.DriverSQLite
+	cfg.Name = ":memory:"
+	if err := databases.Init(cfg); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	t.Cleanup(databases.LightDatabaseClient.CloseConnections)
+	Register(&auditUser{}, Exclude("password"))
+
+	ctx := requestContext(t)
+	db, _ := databases.LightDatabaseClient.GetDB(ctx)
+	if err := db.AutoMigrate(&auditUser{}, &Log{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+
+	user := &auditUser{Name: "alice", Age: 30, Password: "secret"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := db.Model(user).Updates(map[string]any{"age": 31, "password": "changed"}).Error; err != nil {
+		t.Fatalf("Updates() error = %v", err)
+	}
+	if err := db.Model(&auditUser{}).Where("name = ?", "alice").Update("name", "alicia").Error; err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := db.Delete(&auditUser{}, user.ID).Error; err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	history, err := History(ctx, &auditUser{}, user.ID)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(history) != 4 {
+		t.Fatalf("history length = %d; expected 4", len(history))
+	}
+
+	decode := func(s string) map[string]any {
+		if s == "" {
+			return nil
+		}
+		values := map[string]any{}
+		if err := json.Unmarshal([]byte(s), &values); err != nil {
+			t.Fatalf("Unmarshal(%q) error = %v", s, err)
+		}
+		return values
+	}
+	tests := []struct {
+		name     string
+		log      *Log
+		event    Event
+		oldValue map[string]any
+		newValue map[string]any
+	}{
+		{"Test Audit Created", history[3], EventCreated, nil, map[string]any{"id": float64(user.ID), "name": "alice", "age": float64(30)}},
+		{"Test Audit Updated By Primary Key", history[2], EventUpdated, map[string]any{"age": float64(30)}, map[string]any{"age": float64(31)}},
+		{"Test Audit Updated By Where", history[1], EventUpdated, map[string]any{"name": "alice"}, map[string]any{"name": "alicia"}},
+		{"Test Audit Deleted", history[0], EventDeleted, map[string]any{"id": float64(user.ID), "name": "alicia", "age": float64(31)}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.log.Event != test.event {
+				t.Errorf("event = %s; expected %s", test.log.Event, test.event)
+			}
+			if old, _ := json.Marshal(decode(test.log.OldValues)); string(old) != mustJSON(t, test.oldValue) {
+				t.Errorf("old values = %s; expected %s", old, mustJSON(t, test.oldValue))
+			}
+			if values, _ := json.Marshal(decode(test.log.NewValues)); string(values) != mustJSON(t, test.newValue) {
+				t.Errorf("new values = %s; expected %s", values, mustJSON(t, test.newValue))
+			}
+			if test.log.UserID != 7 || test.log.IP != "10.0.0.1" || test.log.UserAgent != "lighthouse-test" {
+				t.Errorf("actor = (%d, %s, %s); expected (7, 10.0.0.1, lighthouse-test)", test.log.UserID, test.log.IP, test.log.UserAgent)
+			}
+		})
+	}
+}
+
+func mustJSON(t *testing.T, v map[string]any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	return string(data)
+}
+
+type auditItem struct {
+	ID  uint `gorm:"primaryKey"`
+	Qty int
+}
+
+func TestAuditBatch(t *testing.T) {
+	cfg := databases.DefaultConfig()
+	cfg.Driver = databases.DriverSQLite
+	cfg.Name = ":memory:"
+	cfg.Connections = map[string]*databases.DatabaseConfig{
+		"archive": {Driver: databases.DriverSQLite, Name: filepath.Join(t.TempDir(), "archive.db"), LogLevel: logger.Silent},
+	}
+	if err := databases.Init(cfg); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	t.Cleanup(databases.LightDatabaseClient.CloseConnections)
+	Register(&auditItem{}, OnConnection("archive"), MaxRows(600))
+
+	ctx := context.Background()
+	conn, err := databases.Connection("archive")
+	if err != nil {
+		t.Fatalf("Connection() error = %v", err)
+	}
+	db, _ := conn.GetDB(ctx)
+	if err := db.AutoMigrate(&auditItem{}, &Log{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	create := func(n int) {
+		items := make([]*auditItem, n)
+		for i := range items {
+			items[i] = &auditItem{}
+		}
+		if err := db.Create(&items).Error; err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	updated := func() int64 {
+		var count int64
+		db.Model(&Log{}).Where("event = ?", EventUpdated).Count(&count)
+		return count
+	}
+
+	t.Run("Test Audit Batch Update In Chunks", func(t *testing.T) {
+		create(550)
+		if err := db.Model(&auditItem{}).Where("qty = ?", 0).Update("qty", 1).Error; err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if count := updated(); count != 550 {
+			t.Errorf("updated logs = %d; expected 550", count)
+		}
+	})
+
+	t.Run("Test Audit History On Model Connection", func(t *testing.T) {
+		history, err := History(ctx, &auditItem{}, 1)
+		if err != nil {
+			t.Fatalf("History() error = %v", err)
+		}
+		if len(history) != 2 || history[0].Event != EventUpdated || history[1].Event != EventCreated {
+			t.Errorf("history = %+v", history)
+		}
+	})
+
+	t.Run("Test Audit Skips Over MaxRows", func(t *testing.T) {
+		create(100)
+		if err := db.Model(&auditItem{}).Where("qty >= ?", 0).Update("qty", 2).Error; err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if count := updated(); count != 550 {
+			t.Errorf("updated logs = %d; expected 550", count)
+		}
+	})
+}
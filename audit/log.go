@@ -0,0 +1,31 @@
+package audit
+
+import "time"
+
+// Event 审计事件
+type Event string
+
+const (
+	EventCreated Event = "created"
+	EventUpdated Event = "updated"
+	EventDeleted Event = "deleted"
+)
+
+// Log 审计记录表模型，需要加入项目的 migrateModels 中迁移
+// OldValues / NewValues 为 JSON，更新时只包含变化的字段
+type Log struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	AuditableType string    `gorm:"type:varchar(191);index:idx_audit_auditable,priority:1;not null" json:"auditableType"`
+	AuditableID   string    `gorm:"type:varchar(191);index:idx_audit_auditable,priority:2;not null" json:"auditableId"`
+	Event         Event     `gorm:"type:varchar(16);not null" json:"event"`
+	OldValues     string    `gorm:"type:text" json:"oldValues"`
+	NewValues     string    `gorm:"type:text" json:"newValues"`
+	UserID        uint      `gorm:"index;not null;default:0" json:"userId"`
+	IP            string    `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent     string    `gorm:"type:varchar(512)" json:"userAgent"`
+	CreatedAt     time.Time `gorm:"index" json:"createdAt"`
+}
+
+func (Log) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,283 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/light-speak/lighthouse/databases"
+	"github.com/light-speak/lighthouse/logs"
+	"github.com/light-speak/lighthouse/routers/auth"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// snapshotKey 更新、删除前的记录快照保存在语句的 InstanceSet 中
+const snapshotKey = "lighthouse:audit_snapshot"
+
+// batchSize 读取快照与更新后记录时每批的记录数
+const batchSize = 500
+
+var errTooManyRows = errors.New("too many rows to audit")
+
+type plugin struct{}
+
+func (plugin) Name() string {
+	return "lighthouse:audit"
+}
+
+func (plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("lighthouse:audit_create", afterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("lighthouse:audit_before_update", snapshot); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("lighthouse:audit_update", afterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("lighthouse:audit_before_delete", snapshot); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register("lighthouse:audit_delete", afterDelete)
+}
+
+// audited 返回当前语句模型的审计选项，模型未登记时返回 nil
+func audited(db *gorm.DB) *options {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	return registered(db.Statement.Schema.ModelType)
+}
+
+func afterCreate(db *gorm.DB) {
+	o := audited(db)
+	if o == nil {
+		return
+	}
+	s := db.Statement.Schema
+	entries := make([]*Log, 0)
+	eachRecord(db.Statement.ReflectValue, func(rv reflect.Value) {
+		entries = append(entries, newLog(db, EventCreated, primaryKey(db.Statement.Context, s, rv), nil, values(db.Statement.Context, s, o, rv)))
+	})
+	write(db, entries)
+}
+
+// snapshot 在更新、删除之前分批读取受影响的记录
+// 按主键更新时读取该记录；批量操作按 WHERE 条件读取，没有条件的全表操作以及超过 MaxRows 的操作不记录
+// 快照读取固定在主库，避免读写分离读到从库上的旧值
+func snapshot(db *gorm.DB) {
+	o := audited(db)
+	if o == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return
+	}
+	stmt := db.Statement
+	query := db.Session(&gorm.Session{NewDB: true, SkipHooks: true, Context: databases.UsePrimary(stmt.Context)}).Model(reflect.New(stmt.Schema.ModelType).Interface())
+	if stmt.Unscoped {
+		query = query.Unscoped()
+	}
+
+	ids := make([]any, 0)
+	eachRecord(stmt.ReflectValue, func(rv reflect.Value) {
+		if id, zero := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, rv); !zero {
+			ids = append(ids, id)
+		}
+	})
+	switch {
+	case len(ids) > 0:
+		query = query.Where(clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: stmt.Schema.PrioritizedPrimaryField.DBName}, Values: ids})
+		if where, ok := stmt.Clauses["WHERE"]; ok {
+			query = query.Clauses(where.Expression)
+		}
+	default:
+		where, ok := stmt.Clauses["WHERE"]
+		if !ok {
+			return
+		}
+		query = query.Clauses(where.Expression)
+	}
+
+	// 每批只保留审计字段的值，不持有整批模型
+	snapshots := make(map[string]map[string]any)
+	rows := reflect.New(reflect.SliceOf(reflect.PointerTo(stmt.Schema.ModelType)))
+	err := query.FindInBatches(rows.Interface(), batchSize, func(tx *gorm.DB, batch int) error {
+		if o.maxRows > 0 && len(snapshots)+rows.Elem().Len() > o.maxRows {
+			return errTooManyRows
+		}
+		for i := 0; i < rows.Elem().Len(); i++ {
+			rv := rows.Elem().Index(i).Elem()
+			snapshots[primaryKey(stmt.Context, stmt.Schema, rv)] = values(stmt.Context, stmt.Schema, o, rv)
+		}
+		return nil
+	}).Error
+	if errors.Is(err, errTooManyRows) {
+		logs.Error().Str("model", stmt.Schema.Name).Int("max_rows", o.maxRows).Msg("too many rows affected, audit skipped")
+		return
+	}
+	if err != nil {
+		logs.Error().Err(err).Str("model", stmt.Schema.Name).Msg("failed to load audit snapshot")
+		return
+	}
+	db.InstanceSet(snapshotKey, snapshots)
+}
+
+func afterUpdate(db *gorm.DB) {
+	o := audited(db)
+	if o == nil || db.RowsAffected == 0 {
+		return
+	}
+	snapshots := loadSnapshots(db)
+	if len(snapshots) == 0 {
+		return
+	}
+
+	// 分批在主库重新读取更新后的记录，得到数据库中的最终值（包括表达式更新）
+	stmt := db.Statement
+	ctx := databases.UsePrimary(stmt.Context)
+	ids := make([]string, 0, len(snapshots))
+	for id := range snapshots {
+		ids = append(ids, id)
+	}
+	for start := 0; start < len(ids); start += batchSize {
+		chunk := ids[start:min(start+batchSize, len(ids))]
+		rows := reflect.New(reflect.SliceOf(reflect.PointerTo(stmt.Schema.ModelType)))
+		err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true, Context: ctx}).Unscoped().
+			Where(clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: stmt.Schema.PrioritizedPrimaryField.DBName}, Values: toAny(chunk)}).
+			Find(rows.Interface()).Error
+		if err != nil {
+			logs.Error().Err(err).Str("model", stmt.Schema.Name).Msg("failed to load audited records")
+			return
+		}
+
+		entries := make([]*Log, 0, len(chunk))
+		for i := 0; i < rows.Elem().Len(); i++ {
+			rv := rows.Elem().Index(i).Elem()
+			id := primaryKey(stmt.Context, stmt.Schema, rv)
+			oldValues, newValues := diff(snapshots[id], values(stmt.Context, stmt.Schema, o, rv))
+			if len(newValues) == 0 {
+				continue
+			}
+			entries = append(entries, newLog(db, EventUpdated, id, oldValues, newValues))
+		}
+		write(db, entries)
+	}
+}
+
+func afterDelete(db *gorm.DB) {
+	o := audited(db)
+	if o == nil || db.RowsAffected == 0 {
+		return
+	}
+	snapshots := loadSnapshots(db)
+	entries := make([]*Log, 0, min(len(snapshots), batchSize))
+	for id, old := range snapshots {
+		entries = append(entries, newLog(db, EventDeleted, id, old, nil))
+		if len(entries) == batchSize {
+			write(db, entries)
+			entries = entries[:0]
+		}
+	}
+	write(db, entries)
+}
+
+func loadSnapshots(db *gorm.DB) map[string]map[string]any {
+	v, ok := db.InstanceGet(snapshotKey)
+	if !ok {
+		return nil
+	}
+	snapshots, _ := v.(map[string]map[string]any)
+	return snapshots
+}
+
+func newLog(db *gorm.DB, event Event, id string, oldValues, newValues map[string]any) *Log {
+	ctx := db.Statement.Context
+	return &Log{
+		AuditableType: db.Statement.Schema.Name,
+		AuditableID:   id,
+		Event:         event,
+		OldValues:     encode(oldValues),
+		NewValues:     encode(newValues),
+		UserID:        auth.GetCtxUserId(ctx),
+		IP:            auth.GetCtxClientIP(ctx),
+		UserAgent:     auth.GetCtxUserAgent(ctx),
+	}
+}
+
+// write 使用业务语句的连接（事务中时为当前事务）写入审计记录
+func write(db *gorm.DB, entries []*Log) {
+	if len(entries) == 0 {
+		return
+	}
+	err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true, Context: db.Statement.Context}).Create(&entries).Error
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("write audit log: %w", err))
+	}
+}
+
+// values 读取记录中需要审计的字段，key 为列名
+func values(ctx context.Context, s *schema.Schema, o *options, rv reflect.Value) map[string]any {
+	result := make(map[string]any, len(s.Fields))
+	for _, f := range s.Fields {
+		if f.DBName == "" || f.AutoUpdateTime > 0 || o.excluded(f) {
+			continue
+		}
+		value, _ := f.ValueOf(ctx, rv)
+		result[f.DBName] = value
+	}
+	return result
+}
+
+// diff 比较更新前后的值，只保留变化的字段
+func diff(oldValues, newValues map[string]any) (map[string]any, map[string]any) {
+	changedOld := make(map[string]any)
+	changedNew := make(map[string]any)
+	for k, v := range newValues {
+		if encode(map[string]any{k: oldValues[k]}) != encode(map[string]any{k: v}) {
+			changedOld[k] = oldValues[k]
+			changedNew[k] = v
+		}
+	}
+	return changedOld, changedNew
+}
+
+func encode(v map[string]any) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func primaryKey(ctx context.Context, s *schema.Schema, rv reflect.Value) string {
+	if s.PrioritizedPrimaryField == nil {
+		return ""
+	}
+	id, _ := s.PrioritizedPrimaryField.ValueOf(ctx, rv)
+	return fmt.Sprint(id)
+}
+
+func eachRecord(rv reflect.Value, fn func(rv reflect.Value)) {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fn(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
+
+func toAny(values []string) []any {
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
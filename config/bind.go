@@ -0,0 +1,179 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 支持的结构体标签
+//
+//	env:"DB_HOST"        配置项名称，没有 env 标签的结构体字段会递归绑定
+//	default:"localhost"  配置项未设置时使用的默认值
+//	required:"true"      必填，未设置且没有默认值时返回错误
+//	secret:"true"        敏感信息，Dump 时脱敏
+//	sep:","              切片的分隔符，默认为逗号
+//	prefix:"DB_CONN_"    map[string]T 字段，Dump 时按 prefix + 大写的键 + "_" 展开，值由调用方通过 BindPrefix 绑定
+const (
+	tagEnv      = "env"
+	tagDefault  = "default"
+	tagRequired = "required"
+	tagSecret   = "secret"
+	tagSep      = "sep"
+	tagPrefix   = "prefix"
+)
+
+// ErrRequired 必填配置项未设置
+var ErrRequired = errors.New("is required")
+
+// FieldError 单个配置项的绑定错误
+type FieldError struct {
+	Key   string
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("config %s (%s): %v", e.Key, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Bind 按结构体标签把配置绑定到 target，target 必须是结构体指针
+// 未设置的配置项保留字段原值，所有字段的错误合并后返回
+func Bind(target any) error {
+	return BindPrefix(target, "")
+}
+
+// BindPrefix 与 Bind 相同，配置项名称为 prefix 加上 env 标签，用于 DB_CONN_<NAME>_* 这类名称不固定的配置
+func BindPrefix(target any, prefix string) error {
+	if err := Load(); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: bind target must be a non-nil struct pointer, got %T", target)
+	}
+
+	var errs []error
+	bindStruct(v.Elem(), prefix, &errs)
+	return errors.Join(errs...)
+}
+
+func bindStruct(v reflect.Value, prefix string, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		key := field.Tag.Get(tagEnv)
+		if key == "" {
+			if fv.Kind() == reflect.Struct && field.Type != durationType {
+				bindStruct(fv, prefix, errs)
+			}
+			continue
+		}
+		key = prefix + key
+
+		raw, ok := Lookup(key)
+		if !ok {
+			raw, ok = field.Tag.Lookup(tagDefault)
+		}
+		if !ok {
+			if field.Tag.Get(tagRequired) == "true" && fv.IsZero() {
+				*errs = append(*errs, &FieldError{Key: key, Field: field.Name, Err: ErrRequired})
+			}
+			continue
+		}
+		if err := setValue(fv, raw, separator(field)); err != nil {
+			*errs = append(*errs, &FieldError{Key: key, Field: field.Name, Err: err})
+		}
+	}
+}
+
+func separator(field reflect.StructField) string {
+	if sep := field.Tag.Get(tagSep); sep != "" {
+		return sep
+	}
+	return ","
+}
+
+func setValue(v reflect.Value, raw string, sep string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	// Duration 支持 10s、1m 等格式，纯数字按秒处理
+	if v.Type() == durationType {
+		if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			v.SetInt(seconds * int64(time.Second))
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(raw), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(raw), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := strings.Split(raw, sep)
+		slice := reflect.MakeSlice(v.Type(), 0, len(parts))
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			item := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(item, part, sep); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, item)
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
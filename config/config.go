@@ -0,0 +1,267 @@
+// Package config 统一加载框架与项目的配置
+//
+// 配置按以下顺序叠加，后者覆盖前者：
+//
+//  1. 结构体 default 标签或 DefaultConfig 中的默认值
+//  2. YAML 文件（CONFIG_FILE 指定，或工作目录下的 config.yaml / config.yml）
+//  3. .env
+//  4. .env.<APP_ENV>
+//  5. 进程环境变量
+//
+// 为兼容直接读取环境变量的代码（例如 utils.GetEnv），加载后的文件配置会写入尚未设置的环境变量
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// 配置来源
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+)
+
+var (
+	mu       sync.RWMutex
+	loaded   bool
+	loadErr  error
+	files    []string
+	sources  = map[string]string{}
+	exported = map[string]bool{}
+)
+
+// Load 加载配置文件，只在首次调用时读取，之后直接返回首次加载的结果
+func Load() error {
+	mu.RLock()
+	if loaded {
+		defer mu.RUnlock()
+		return loadErr
+	}
+	mu.RUnlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !loaded {
+		loadErr = load()
+		loaded = true
+	}
+	return loadErr
+}
+
+// Reload 重新读取配置文件，之前由文件写入的环境变量会先被清除
+func Reload() error {
+	mu.Lock()
+	defer mu.Unlock()
+	loadErr = load()
+	loaded = true
+	return loadErr
+}
+
+// Lookup 返回配置项的值，空字符串视为未设置
+func Lookup(key string) (string, bool) {
+	_ = Load()
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+// Source 返回配置项的来源，未设置时返回空字符串
+func Source(key string) string {
+	if _, ok := Lookup(key); !ok {
+		return ""
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	if source, ok := sources[key]; ok {
+		return source
+	}
+	return SourceEnv
+}
+
+// Keys 返回已设置的、以 prefix 开头的配置项名称，按名称排序
+func Keys(prefix string) []string {
+	_ = Load()
+	keys := make([]string, 0)
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(key, prefix) && value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Files 返回已加载的配置文件，按优先级从低到高排列
+func Files() []string {
+	_ = Load()
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]string(nil), files...)
+}
+
+type layer struct {
+	name   string
+	values map[string]string
+}
+
+// load 调用方需持有 mu
+func load() error {
+	for key := range exported {
+		os.Unsetenv(key)
+	}
+	files = nil
+	sources = map[string]string{}
+	exported = map[string]bool{}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	dotenv, err := readDotenv(filepath.Join(dir, ".env"))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// APP_ENV 与 CONFIG_FILE 本身也可以写在 .env 中
+	lookup := func(key string, layers ...map[string]string) string {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+		for _, values := range layers {
+			if value := values[key]; value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	var envLayer layer
+	if appEnv := lookup("APP_ENV", dotenv); appEnv != "" {
+		name := ".env." + appEnv
+		values, err := readDotenv(filepath.Join(dir, name))
+		if err != nil {
+			errs = append(errs, err)
+		}
+		envLayer = layer{name: name, values: values}
+	}
+
+	var yamlLayer layer
+	configFile, explicit := lookup("CONFIG_FILE", envLayer.values, dotenv), true
+	if configFile == "" {
+		explicit = false
+		for _, name := range []string{"config.yaml", "config.yml"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				configFile = name
+				break
+			}
+		}
+	}
+	if configFile != "" {
+		path := configFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		values, err := readYAML(path, explicit)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		yamlLayer = layer{name: filepath.Base(configFile), values: values}
+	}
+
+	layers := []layer{yamlLayer, {name: ".env", values: dotenv}, envLayer}
+	merged := map[string]string{}
+	for _, l := range layers {
+		if l.values == nil {
+			continue
+		}
+		files = append(files, l.name)
+		for key, value := range l.values {
+			merged[key] = value
+			sources[key] = l.name
+		}
+	}
+
+	for key, value := range merged {
+		if _, ok := os.LookupEnv(key); ok {
+			delete(sources, key)
+			continue
+		}
+		os.Setenv(key, value)
+		exported[key] = true
+	}
+	return errors.Join(errs...)
+}
+
+// readDotenv 读取 dotenv 文件，文件不存在时返回 nil
+func readDotenv(path string) (map[string]string, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	return values, nil
+}
+
+// readYAML 读取 YAML 配置，嵌套的 key 以下划线连接并转为大写
+// 例如 db: {host: localhost} 对应 DB_HOST，数组以逗号连接
+func readYAML(path string, explicit bool) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var root map[string]any
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	values := map[string]string{}
+	flatten("", root, values)
+	return values, nil
+}
+
+func flatten(prefix string, node map[string]any, values map[string]string) {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := strings.ToUpper(key)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		switch value := node[key].(type) {
+		case map[string]any:
+			flatten(name, value, values)
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			values[name] = strings.Join(items, ",")
+		case nil:
+			values[name] = ""
+		default:
+			values[name] = fmt.Sprint(value)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Host     string        `env:"TEST_CFG_HOST" default:"localhost"`
+	Port     int           `env:"TEST_CFG_PORT"`
+	Debug    bool          `env:"TEST_CFG_DEBUG"`
+	Timeout  time.Duration `env:"TEST_CFG_TIMEOUT"`
+	Tags     []string      `env:"TEST_CFG_TAGS"`
+	Password string        `env:"TEST_CFG_PASSWORD" secret:"true"`
+	Token    string        `env:"TEST_CFG_TOKEN" required:"true"`
+
+	Nested struct {
+		Region string `env:"TEST_CFG_REGION"`
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("config.yaml", "test_cfg:\n  host: yaml\n  port: 1\n  tags: [a, b]\n")
+	write(".env", "APP_ENV=testing\nTEST_CFG_PORT=2\nTEST_CFG_DEBUG=true\n")
+	write(".env.testing", "TEST_CFG_DEBUG=false\nTEST_CFG_TIMEOUT=15\n")
+	// 在恢复工作目录后重新加载，清除测试写入的环境变量
+	t.Cleanup(func() { _ = Reload() })
+	t.Chdir(dir)
+	t.Setenv("TEST_CFG_TOKEN", "real")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		source string
+	}{
+		{"TEST_CFG_HOST", "yaml", "config.yaml"},
+		{"TEST_CFG_TAGS", "a,b", "config.yaml"},
+		{"TEST_CFG_PORT", "2", ".env"},
+		{"TEST_CFG_DEBUG", "false", ".env.testing"},
+		{"TEST_CFG_TIMEOUT", "15", ".env.testing"},
+		{"TEST_CFG_TOKEN", "real", SourceEnv},
+		{"TEST_CFG_REGION", "", ""},
+	}
+	for _, test := range tests {
+		t.Run("Test Load "+test.key, func(t *testing.T) {
+			value, _ := Lookup(test.key)
+			if value != test.value {
+				t.Errorf("Lookup(%s) = %q; expected %q", test.key, value, test.value)
+			}
+			if source := Source(test.key); source != test.source {
+				t.Errorf("Source(%s) = %q; expected %q", test.key, source, test.source)
+			}
+		})
+	}
+
+	t.Run("Test Bind layered values", func(t *testing.T) {
+		cfg := &testConfig{}
+		if err := Bind(cfg); err != nil {
+			t.Fatalf("Bind() error: %v", err)
+		}
+		if cfg.Host != "yaml" || cfg.Port != 2 || cfg.Debug || cfg.Timeout != 15*time.Second || len(cfg.Tags) != 2 {
+			t.Errorf("Bind() = %+v", cfg)
+		}
+	})
+}
+
+func TestBindErrors(t *testing.T) {
+	t.Setenv("TEST_CFG_PORT", "not-a-number")
+	t.Setenv("TEST_CFG_TOKEN", "")
+
+	cfg := &testConfig{}
+	err := Bind(cfg)
+	if !errors.Is(err, ErrRequired) {
+		t.Errorf("Bind() error = %v; expected ErrRequired", err)
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("Bind() error = %v; expected FieldError", err)
+	}
+	if cfg.Host != "localhost" {
+		t.Errorf("Host = %q; expected default localhost", cfg.Host)
+	}
+	if err := Bind(testConfig{}); err == nil {
+		t.Error("Bind(non-pointer) expected error")
+	}
+}
+
+func TestDumpRedactsSecrets(t *testing.T) {
+	cfg := &testConfig{Password: "hunter2", Tags: []string{"a", "b"}, Timeout: time.Minute}
+	cfg.Nested.Region = "cn"
+
+	values := map[string]Field{}
+	for _, f := range Dump(cfg) {
+		values[f.Key] = f
+	}
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"TEST_CFG_PASSWORD", Redacted},
+		{"TEST_CFG_TAGS", "a,b"},
+		{"TEST_CFG_TIMEOUT", "1m0s"},
+		{"TEST_CFG_REGION", "cn"},
+		{"TEST_CFG_TOKEN", ""},
+	}
+	for _, test := range tests {
+		t.Run("Test Dump "+test.key, func(t *testing.T) {
+			if got := values[test.key].Value; got != test.expected {
+				t.Errorf("Dump %s = %q; expected %q", test.key, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestBindPrefix(t *testing.T) {
+	t.Setenv("TEST_PFX_MAIN_HOST", "db.internal")
+	t.Setenv("TEST_PFX_MAIN_PASSWORD", "hunter2")
+
+	type conn struct {
+		Host     string `env:"HOST" default:"localhost"`
+		Password string `env:"PASSWORD" secret:"true"`
+	}
+	cfg := struct {
+		Conns map[string]*conn `prefix:"TEST_PFX_"`
+	}{Conns: map[string]*conn{}}
+	for _, name := range []string{"main", "replica"} {
+		c := &conn{}
+		if err := BindPrefix(c, "TEST_PFX_"+strings.ToUpper(name)+"_"); err != nil {
+			t.Fatal(err)
+		}
+		cfg.Conns[name] = c
+	}
+
+	values := map[string]string{}
+	for _, f := range Dump(&cfg) {
+		values[f.Key] = f.Value
+	}
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"TEST_PFX_MAIN_HOST", "db.internal"},
+		{"TEST_PFX_MAIN_PASSWORD", Redacted},
+		{"TEST_PFX_REPLICA_HOST", "localhost"},
+	}
+	for _, test := range tests {
+		t.Run("Test Prefix "+test.key, func(t *testing.T) {
+			if got := values[test.key]; got != test.expected {
+				t.Errorf("Dump %s = %q; expected %q", test.key, got, test.expected)
+			}
+		})
+	}
+	if keys := Keys("TEST_PFX_"); len(keys) != 2 || keys[0] != "TEST_PFX_MAIN_HOST" {
+		t.Errorf("Keys() = %v", keys)
+	}
+}
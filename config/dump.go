@@ -0,0 +1,189 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Redacted 敏感配置项在 Dump 中的显示值
+const Redacted = "******"
+
+// Field 配置项的当前值
+type Field struct {
+	Key      string
+	Value    string
+	Source   string
+	Secret   bool
+	Required bool
+}
+
+// Dump 按结构体标签列出 target 中所有配置项的当前值与来源，敏感信息会被脱敏
+func Dump(target any) []Field {
+	v := reflect.ValueOf(target)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []Field
+	dumpStruct(v, "", &fields)
+	return fields
+}
+
+func dumpStruct(v reflect.Value, prefix string, fields *[]Field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		key := field.Tag.Get(tagEnv)
+		if key == "" {
+			switch {
+			case fv.Kind() == reflect.Struct && field.Type != durationType:
+				dumpStruct(fv, prefix, fields)
+			case fv.Kind() == reflect.Map && field.Tag.Get(tagPrefix) != "":
+				dumpMap(fv, prefix+field.Tag.Get(tagPrefix), fields)
+			}
+			continue
+		}
+		key = prefix + key
+
+		f := Field{
+			Key:      key,
+			Value:    formatValue(fv, separator(field)),
+			Source:   Source(key),
+			Secret:   field.Tag.Get(tagSecret) == "true",
+			Required: field.Tag.Get(tagRequired) == "true",
+		}
+		if f.Source == "" {
+			f.Source = SourceDefault
+		}
+		if f.Secret && f.Value != "" {
+			f.Value = Redacted
+		}
+		*fields = append(*fields, f)
+	}
+}
+
+// dumpMap 按键排序展开 map[string]T，每个值的配置项名称为 prefix + 大写的键 + "_" + env 标签
+func dumpMap(v reflect.Value, prefix string, fields *[]Field) {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, key := range keys {
+		elem := v.MapIndex(key)
+		for elem.Kind() == reflect.Pointer || elem.Kind() == reflect.Interface {
+			if elem.IsNil() {
+				break
+			}
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Struct {
+			dumpStruct(elem, prefix+strings.ToUpper(key.String())+"_", fields)
+		}
+	}
+}
+
+func formatValue(v reflect.Value, sep string) string {
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatValue(v.Index(i), sep)
+		}
+		return strings.Join(items, sep)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Section 一组可在 config:show 中展示的配置
+type Section struct {
+	Name string
+	Load func() (any, error)
+}
+
+var (
+	sectionMu sync.RWMutex
+	sections  = map[string]Section{}
+)
+
+// Register 注册一组配置，load 返回绑定后的配置结构体
+func Register(name string, load func() (any, error)) {
+	sectionMu.Lock()
+	defer sectionMu.Unlock()
+	sections[name] = Section{Name: name, Load: load}
+}
+
+// Sections 返回所有已注册的配置，按名称排序
+func Sections() []Section {
+	sectionMu.RLock()
+	defer sectionMu.RUnlock()
+	result := make([]Section, 0, len(sections))
+	for _, s := range sections {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Print 输出已注册配置的当前值，names 为空时输出全部
+func Print(w io.Writer, names ...string) error {
+	if err := Load(); err != nil {
+		fmt.Fprintf(w, "load error: %v\n", err)
+	}
+	appEnv, _ := Lookup("APP_ENV")
+	fmt.Fprintf(w, "APP_ENV: %s\n", appEnv)
+	fmt.Fprintf(w, "Files:   %s\n", strings.Join(Files(), ", "))
+
+	filter := map[string]bool{}
+	for _, name := range names {
+		filter[name] = true
+	}
+
+	found := false
+	for _, section := range Sections() {
+		if len(filter) > 0 && !filter[section.Name] {
+			continue
+		}
+		found = true
+
+		fmt.Fprintf(w, "\n[%s]\n", section.Name)
+		cfg, err := section.Load()
+		if err != nil {
+			fmt.Fprintf(w, "  error: %v\n", strings.ReplaceAll(err.Error(), "\n", "\n         "))
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, f := range Dump(cfg) {
+			fmt.Fprintf(tw, "  %s\t%s\t(%s)\n", f.Key, f.Value, f.Source)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if !found && len(filter) > 0 {
+		return fmt.Errorf("unknown config section: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package databases
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+
+	"github.com/light-speak/lighthouse/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	roleMain    = "main"
+	roleReplica = "replica"
+)
+
+var poolLabels = []string{"connection", "role", "host"}
+
+// poolHost 返回连接池指标中的 host 标签，格式为 host:port/name，SQLite 使用数据库文件名
+// 同一台机器上不同端口或数据库的连接各自对应一组指标
+func poolHost(cfg *DatabaseConfig) string {
+	if cfg == nil {
+		return ""
+	}
+	if cfg.Driver == DriverSQLite || len(cfg.Hosts) == 0 {
+		return cfg.Name
+	}
+	return net.JoinHostPort(cfg.Hosts[0], cfg.Port) + "/" + cfg.Name
+}
+
+type replicaPool struct {
+	host string
+	db   *sql.DB
+}
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName("lighthouse", "database_pool", name), help, poolLabels, nil)
+}
+
+// poolCollector 采集默认连接、从库和命名连接的 sql.DBStats，只读取已建立的连接，不会触发连接
+type poolCollector struct {
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	open         *prometheus.Desc
+	maxOpen      *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+}
+
+func newPoolCollector() *poolCollector {
+	return &poolCollector{
+		inUse:        poolDesc("in_use", "Number of connections currently in use"),
+		idle:         poolDesc("idle", "Number of idle connections"),
+		open:         poolDesc("open", "Number of established connections, both in use and idle"),
+		maxOpen:      poolDesc("max_open", "Maximum number of open connections"),
+		waitCount:    poolDesc("wait_count_total", "Total number of connections waited for"),
+		waitDuration: poolDesc("wait_duration_seconds_total", "Total time blocked waiting for a new connection"),
+	}
+}
+
+func init() {
+	metrics.RegisterCollector(newPoolCollector())
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.open
+	ch <- c.maxOpen
+	ch <- c.waitCount
+	ch <- c.waitDuration
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collectDatabase(ch, DefaultConnection, LightDatabaseClient)
+	for name, l := range namedConnections() {
+		c.collectDatabase(ch, name, l)
+	}
+}
+
+func (c *poolCollector) collectDatabase(ch chan<- prometheus.Metric, name string, l *LightDatabase) {
+	if l == nil {
+		return
+	}
+
+	l.mu.RLock()
+	if !l.Completed || l.MainDB == nil {
+		l.mu.RUnlock()
+		return
+	}
+	host := l.host
+	main, _ := l.MainDB.DB()
+	// 配置了完全相同的从库时按序号区分，避免重复的标签导致整个采集失败
+	replicas := make([]replicaPool, 0, len(l.replicas))
+	seen := make(map[string]bool, len(l.replicas))
+	for i, r := range l.replicas {
+		if r.db == nil {
+			continue
+		}
+		sqlDB, err := r.db.DB()
+		if err != nil {
+			continue
+		}
+		label := poolHost(r.config)
+		if label == "" {
+			label = r.host
+		}
+		if seen[label] {
+			label = fmt.Sprintf("%s#%d", label, i)
+		}
+		seen[label] = true
+		replicas = append(replicas, replicaPool{host: label, db: sqlDB})
+	}
+	l.mu.RUnlock()
+
+	if main != nil {
+		c.collectStats(ch, main.Stats(), name, roleMain, host)
+	}
+	for _, r := range replicas {
+		c.collectStats(ch, r.db.Stats(), name, roleReplica, r.host)
+	}
+}
+
+func (c *poolCollector) collectStats(ch chan<- prometheus.Metric, stats sql.DBStats, labels ...string) {
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse), labels...)
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle), labels...)
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections), labels...)
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections), labels...)
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount), labels...)
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds(), labels...)
+}
@@ -0,0 +1,72 @@
+package databases
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// collectPools 采集 l 的连接池指标，返回 in_use 指标的 role/host 标签
+func collectPools(t *testing.T, l *LightDatabase) []string {
+	t.Helper()
+	c := newPoolCollector()
+	ch := make(chan prometheus.Metric, 100)
+	c.collectDatabase(ch, "test", l)
+	close(ch)
+
+	var series []string
+	for metric := range ch {
+		if metric.Desc() != c.inUse {
+			continue
+		}
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		labels := map[string]string{}
+		for _, label := range m.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		if labels["connection"] != "test" {
+			t.Errorf("connection label = %s", labels["connection"])
+		}
+		series = append(series, labels["role"]+"/"+labels["host"])
+	}
+	sort.Strings(series)
+	return series
+}
+
+func TestPoolCollector(t *testing.T) {
+	a, _, _ := openReplica(t, "10.0.0.2", 1)
+	b, _, _ := openReplica(t, "10.0.0.3", 1)
+	main, _, _ := openReplica(t, "10.0.0.1", 1)
+	// 同一台机器上的三个从库：两个端口不同，一个与第一个完全相同
+	sameHost := func(port string) *replica {
+		r, _, _ := openReplica(t, "10.0.0.5", 1)
+		r.config = &DatabaseConfig{Driver: DriverMySQL, Hosts: []string{"10.0.0.5"}, Port: port, Name: "app"}
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		db       *LightDatabase
+		expected string
+	}{
+		{"Test Not Connected", &LightDatabase{}, ""},
+		{"Test Main Only", &LightDatabase{MainDB: main.db, Completed: true, host: "10.0.0.1"}, "main/10.0.0.1"},
+		{"Test Replicas", &LightDatabase{MainDB: main.db, Completed: true, host: "10.0.0.1", replicas: []*replica{a, b, {host: "10.0.0.4"}}},
+			"main/10.0.0.1,replica/10.0.0.2,replica/10.0.0.3"},
+		{"Test Replicas On Same Host", &LightDatabase{MainDB: main.db, Completed: true, host: "10.0.0.1", replicas: []*replica{sameHost("3306"), sameHost("3307"), sameHost("3306")}},
+			"main/10.0.0.1,replica/10.0.0.5:3306/app,replica/10.0.0.5:3306/app#2,replica/10.0.0.5:3307/app"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := strings.Join(collectPools(t, test.db), ","); got != test.expected {
+				t.Errorf("got %s, expected %s", got, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package databases
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/light-speak/lighthouse/logs"
+)
+
+// DefaultConnection 默认连接名，对应 LightDatabaseClient
+const DefaultConnection = "default"
+
+// namedConnection 命名连接，启动时连接失败的会在下次 Connection 调用时重连
+type namedConnection struct {
+	mu     sync.Mutex
+	config *DatabaseConfig
+	db     *LightDatabase
+}
+
+var (
+	connMu      sync.RWMutex
+	connections = map[string]*namedConnection{}
+)
+
+// initConnections 注册配置中的所有命名连接，首次通过 Connection 获取时才会连接
+func initConnections(cfg *DatabaseConfig) {
+	registered := make(map[string]*namedConnection, len(cfg.Connections))
+	for name, config := range cfg.Connections {
+		registered[strings.ToLower(name)] = &namedConnection{config: config}
+	}
+	connMu.Lock()
+	connections = registered
+	connMu.Unlock()
+}
+
+func (c *namedConnection) connect(name string) {
+	loc, err := time.LoadLocation(c.config.Timezone)
+	if err != nil {
+		c.db = &LightDatabase{Completed: false, Error: err}
+		return
+	}
+	db, err := initDB(name, c.config, loc, c.config.Timezone)
+	if err != nil {
+		logs.Error().Err(err).Str("connection", name).Msg("database connection init error")
+		c.db = &LightDatabase{Completed: false, Error: err}
+		return
+	}
+
+	ld := &LightDatabase{MainDB: db, Completed: true, config: c.config, host: poolHost(c.config)}
+	ld.refreshSlaveDBs()
+	if err := ld.registerResolver(); err != nil {
+		logs.Error().Err(err).Str("connection", name).Msg("failed to register database resolver")
+	}
+	c.db = ld
+	logs.Info().Str("connection", name).Str("driver", string(c.config.Driver)).Msg("database connection initialized successfully")
+}
+
+// Connection 获取命名连接，name 为空或 default 时返回默认连接
+// 连接名不区分大小写，对应环境变量 DB_CONN_<NAME>_*
+func Connection(name string) (*LightDatabase, error) {
+	name = strings.ToLower(name)
+	if name == "" || name == DefaultConnection {
+		if LightDatabaseClient == nil {
+			return nil, fmt.Errorf("database is not initialized")
+		}
+		return LightDatabaseClient, nil
+	}
+
+	initMu.Lock()
+	_, err := loadConfigLocked()
+	isClosed := closed
+	initMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if isClosed {
+		return nil, ErrClosed
+	}
+
+	connMu.RLock()
+	conn, ok := connections[name]
+	connMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("database connection %s is not configured", name)
+	}
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if !conn.db.completed() {
+		conn.connect(name)
+	}
+	if !conn.db.completed() {
+		return nil, fmt.Errorf("database connection %s is not completed, error: %v", name, conn.db.Error)
+	}
+	return conn.db, nil
+}
+
+// ConnectionNames 返回所有命名连接的名称（不含 default）
+func ConnectionNames() []string {
+	initMu.Lock()
+	_, err := loadConfigLocked()
+	initMu.Unlock()
+	if err != nil {
+		return nil
+	}
+
+	connMu.RLock()
+	defer connMu.RUnlock()
+	names := make([]string, 0, len(connections))
+	for name := range connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// namedConnections 返回已连接的命名连接快照，不触发连接
+func namedConnections() map[string]*LightDatabase {
+	connMu.RLock()
+	defer connMu.RUnlock()
+	result := make(map[string]*LightDatabase, len(connections))
+	for name, conn := range connections {
+		conn.mu.Lock()
+		if conn.db != nil {
+			result[name] = conn.db
+		}
+		conn.mu.Unlock()
+	}
+	return result
+}
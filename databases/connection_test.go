@@ -0,0 +1,136 @@
+package databases
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/light-speak/lighthouse/config"
+	"gorm.io/gorm/logger"
+)
+
+func TestParseConnections(t *testing.T) {
+	t.Setenv("DB_CONN_ANALYTICS_DRIVER", "sqlite")
+	t.Setenv("DB_CONN_ANALYTICS_NAME", "analytics.db")
+	t.Setenv("DB_CONN_ANALYTICS_LOG_LEVEL", "error")
+	t.Setenv("DB_CONN_LEGACY_BILLING_HOST", "billing.internal")
+	t.Setenv("DB_CONN_LEGACY_BILLING_MAX_OPEN_CONNS", "7")
+	t.Setenv("DB_CONN_LEGACY_BILLING_PREPARE_STMT", "true")
+	t.Setenv("DB_CONN_LEGACY_BILLING_PASSWORD", "secret")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	connections := cfg.Connections
+	if len(connections) != 2 {
+		t.Fatalf("got %d connections, expected 2", len(connections))
+	}
+
+	analytics, billing := connections["analytics"], connections["legacy_billing"]
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"Test Driver", analytics.Driver == DriverSQLite && billing.Driver == cfg.Driver},
+		{"Test Name", analytics.Name == "analytics.db" && billing.Name == "legacy_billing"},
+		{"Test Host And Port", billing.Hosts[0] == "billing.internal" && billing.Port == billing.Driver.defaultPort()},
+		{"Test Log Level", analytics.LogLevel == logger.Error},
+		{"Test Pool Override", billing.MaxOpenConns == 7 && billing.PrepareStmt && billing.Password == "secret"},
+		{"Test Pool Inherited", analytics.MaxOpenConns == cfg.MaxOpenConns && analytics.MaxIdleConns == cfg.MaxIdleConns},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !test.ok {
+				t.Errorf("unexpected connections: analytics=%+v legacy_billing=%+v", analytics, billing)
+			}
+		})
+	}
+
+	t.Run("Test Config Dump", func(t *testing.T) {
+		env, err := loadEnv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := map[string]string{}
+		for _, f := range config.Dump(env) {
+			values[f.Key] = f.Value
+		}
+		if values["DB_CONN_LEGACY_BILLING_PASSWORD"] != config.Redacted || values["DB_CONN_ANALYTICS_NAME"] != "analytics.db" {
+			t.Errorf("unexpected dump: %v", values)
+		}
+	})
+
+	t.Run("Test Unsupported Driver", func(t *testing.T) {
+		t.Setenv("DB_CONN_BAD_DRIVER", "oracle")
+		if _, err := LoadConfig(); err == nil {
+			t.Error("expected an error for an unsupported driver")
+		}
+	})
+}
+
+func TestConnection(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "later")
+	cfg := DefaultConfig()
+	cfg.Timezone = "UTC"
+	cfg.Connections = map[string]*DatabaseConfig{
+		"analytics": {Driver: DriverSQLite, Name: filepath.Join(dir, "analytics.db"), LogLevel: logger.Silent},
+	}
+	cfg.normalize()
+
+	saved := databaseConfig
+	defer func() {
+		databaseConfig = saved
+		initConnections(&DatabaseConfig{})
+	}()
+	databaseConfig = cfg
+	initConnections(cfg)
+
+	t.Run("Test Failed Connection Returns Error", func(t *testing.T) {
+		if _, err := Connection("analytics"); err == nil {
+			t.Error("expected an error while the database directory is missing")
+		}
+	})
+
+	t.Run("Test Reconnect On Next Call", func(t *testing.T) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		ld, err := Connection("ANALYTICS")
+		if err != nil {
+			t.Fatal(err)
+		}
+		db, err := ld.DB(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.AutoMigrate(&routeUser{}); err != nil {
+			t.Fatal(err)
+		}
+		err = ld.WithTx(context.Background(), func(ctx context.Context) error {
+			tx, _ := ld.GetDB(ctx)
+			return tx.Create(&routeUser{Name: "a"}).Error
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var count int64
+		db.Model(&routeUser{}).Count(&count)
+		if count != 1 {
+			t.Errorf("count = %d, expected 1", count)
+		}
+	})
+
+	t.Run("Test Unknown Connection", func(t *testing.T) {
+		if _, err := Connection("missing"); err == nil {
+			t.Error("expected an error for an unknown connection")
+		}
+	})
+
+	t.Run("Test Connection Names", func(t *testing.T) {
+		if names := ConnectionNames(); len(names) != 1 || names[0] != "analytics" {
+			t.Errorf("names = %v", names)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Driver 数据库驱动
+type Driver string
+
+const (
+	DriverMySQL    Driver = "mysql"
+	DriverPostgres Driver = "postgres"
+	DriverSQLite   Driver = "sqlite" // 纯 Go 实现，无需 cgo，DB_NAME 为数据库文件路径，:memory: 表示内存库
+)
+
+// defaultPort 驱动的默认端口
+func (d Driver) defaultPort() string {
+	switch d {
+	case DriverPostgres:
+		return "5432"
+	case DriverSQLite:
+		return ""
+	default:
+		return "3306"
+	}
+}
+
+func parseDriver(driver string) (Driver, error) {
+	switch Driver(strings.ToLower(driver)) {
+	case DriverMySQL, "":
+		return DriverMySQL, nil
+	case DriverPostgres, "postgresql", "pgsql":
+		return DriverPostgres, nil
+	case DriverSQLite, "sqlite3":
+		return DriverSQLite, nil
+	}
+	return "", fmt.Errorf("unsupported database driver: %s", driver)
+}
+
+// Dialect 返回当前主库使用的驱动
+func Dialect() Driver {
+	return getConfig().Driver
+}
+
+// dialector 按驱动构建 DSN 并创建 gorm.Dialector
+func dialector(config *DatabaseConfig, timezone string) gorm.Dialector {
+	switch config.Driver {
+	case DriverPostgres:
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=%s connect_timeout=10",
+			pgQuote(config.Hosts[0]), // 使用第一个host
+			pgQuote(config.Port),
+			pgQuote(config.User),
+			pgQuote(config.Password),
+			pgQuote(config.Name),
+			pgQuote(config.SSLMode),
+			pgQuote(timezone),
+		)
+		return postgres.Open(dsn)
+	case DriverSQLite:
+		name := config.Name
+		// 内存库使用共享缓存，保证连接池中的连接看到同一个数据库
+		if name == ":memory:" {
+			name = "file::memory:?cache=shared"
+		}
+		sep := "?"
+		if strings.Contains(name, "?") {
+			sep = "&"
+		}
+		return sqlite.Open(name + sep + "_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	default:
+		// URL encode timezone (e.g., Asia/Shanghai -> Asia%2FShanghai)
+		encodedTZ := strings.ReplaceAll(timezone, "/", "%2F")
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4",
+			config.User,
+			config.Password,
+			config.Hosts[0], // 使用第一个host
+			config.Port,
+			config.Name,
+		)
+		dsn += "&parseTime=True&loc=" + encodedTZ + "&timeout=10s&readTimeout=30s&writeTimeout=30s"
+		return mysql.Open(dsn)
+	}
+}
+
+// pgQuote 按 libpq 规则给 key=value 的值加单引号，值中的 \ 和 ' 用 \ 转义，允许空格和空值
+func pgQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// replicationLag 读取从库的复制延迟
+func replicationLag(ctx context.Context, driver Driver, sqlDB *sql.DB) (time.Duration, error) {
+	switch driver {
+	case DriverPostgres:
+		return postgresReplicationLag(ctx, sqlDB)
+	case DriverSQLite:
+		return 0, nil
+	default:
+		return mysqlReplicationLag(ctx, sqlDB)
+	}
+}
+
+// postgresReplicationLag 通过最后一次回放事务的时间计算延迟，主库返回 0
+func postgresReplicationLag(ctx context.Context, sqlDB *sql.DB) (time.Duration, error) {
+	var seconds sql.NullFloat64
+	err := sqlDB.QueryRowContext(ctx,
+		"SELECT CASE WHEN pg_is_in_recovery() THEN EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) ELSE 0 END",
+	).Scan(&seconds)
+	if err != nil {
+		return 0, err
+	}
+	// NULL 表示从库还没有回放过任何事务
+	if !seconds.Valid {
+		return 0, nil
+	}
+	return time.Duration(seconds.Float64 * float64(time.Second)), nil
+}
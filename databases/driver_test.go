@@ -0,0 +1,44 @@
+package databases
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+)
+
+func TestPostgresDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+	}{
+		{"Test Plain Password", "secret"},
+		{"Test Empty Password", ""},
+		{"Test Password With Space", "pass word"},
+		{"Test Password With Quote And Backslash", `it's a \ test`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &DatabaseConfig{
+				Driver:   DriverPostgres,
+				Hosts:    []string{"127.0.0.1"},
+				Port:     "5432",
+				User:     "postgres",
+				Password: test.password,
+				Name:     "my db",
+				SSLMode:  "disable",
+			}
+			dsn := dialector(config, "Asia/Shanghai").(*postgres.Dialector).Config.DSN
+			parsed, err := pgconn.ParseConfig(dsn)
+			if err != nil {
+				t.Fatalf("ParseConfig(%q) error: %v", dsn, err)
+			}
+			if parsed.Password != test.password || parsed.Database != "my db" || parsed.User != "postgres" {
+				t.Errorf("ParseConfig(%q) = %q@%q, password %q", dsn, parsed.User, parsed.Database, parsed.Password)
+			}
+			if tz := parsed.RuntimeParams["TimeZone"]; tz != "Asia/Shanghai" {
+				t.Errorf("TimeZone = %q, expected Asia/Shanghai", tz)
+			}
+		})
+	}
+}
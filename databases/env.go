@@ -1,18 +1,21 @@
 package databases
 
 import (
-	"os"
-	"path/filepath"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
-	"github.com/light-speak/lighthouse/utils"
+	"github.com/light-speak/lighthouse/config"
 	"gorm.io/gorm/logger"
 )
 
+// databaseConfig 当前使用的配置，由 Init 设置或在首次使用时从环境变量加载
 var databaseConfig *DatabaseConfig
 
 type DatabaseConfig struct {
+	Driver   Driver // mysql | postgres | sqlite
+	SSLMode  string // PostgreSQL sslmode
 	Hosts    []string
 	Port     string
 	User     string
@@ -33,6 +36,22 @@ type DatabaseConfig struct {
 	ConnMaxLifetime int  // 连接最大生命周期（分钟）
 	ConnMaxIdleTime int  // 空闲连接最大存活时间（分钟）
 	PrepareStmt     bool // 是否启用 prepared statement 缓存
+
+	// 读写分离配置
+	StickyWindow int // 写操作后会话粘滞主库的时间（秒）
+
+	// 从库负载均衡与健康检查配置
+	SlaveWeights        []int  // 从库权重，与 Slave.Hosts 一一对应
+	SlavePolicy         string // 负载均衡策略 random | round_robin | least_conn
+	HealthCheckInterval int    // 从库健康检查间隔（秒），0 表示关闭
+	MaxReplicaLag       int    // 最大复制延迟（秒），超过则剔除，0 表示不检查
+
+	// 慢查询配置
+	SlowThreshold time.Duration // 慢查询阈值，默认 200ms
+	SlowLogSize   int           // 内存中保留的慢查询条数，默认 100
+
+	// 命名连接，通过 DB_CONN_<NAME>_* 声明，key 为小写的连接名
+	Connections map[string]*DatabaseConfig
 }
 
 type LogLevel string
@@ -44,6 +63,21 @@ const (
 	LogLevelError LogLevel = "error"
 )
 
+func parseLogLevel(level string, fallback logger.LogLevel) logger.LogLevel {
+	switch LogLevel(level) {
+	case LogLevelDebug:
+		return logger.Info
+	case LogLevelInfo:
+		return logger.Info
+	case LogLevelWarn:
+		return logger.Warn
+	case LogLevelError:
+		return logger.Error
+	default:
+		return fallback
+	}
+}
+
 func parseHosts(hostStr string) []string {
 	if hostStr == "" {
 		return []string{"localhost"}
@@ -51,96 +85,397 @@ func parseHosts(hostStr string) []string {
 	return strings.Split(hostStr, ",")
 }
 
+// DefaultConfig 返回数据库默认配置
+func DefaultConfig() *DatabaseConfig {
+	return &DatabaseConfig{
+		Driver:              DriverMySQL,
+		SSLMode:             "disable",
+		Hosts:               []string{"localhost"},
+		User:                "root",
+		Password:            "",
+		Name:                "example",
+		LogLevel:            logger.Info,
+		Timezone:            "Asia/Shanghai",
+		MaxIdleConns:        10,
+		MaxOpenConns:        100,
+		ConnMaxLifetime:     30,
+		ConnMaxIdleTime:     3,
+		StickyWindow:        5,
+		SlavePolicy:         string(SlavePolicyRandom),
+		HealthCheckInterval: 10,
+		MaxReplicaLag:       30,
+		SlowThreshold:       defaultSlowThreshold,
+		SlowLogSize:         100,
+	}
+}
+
+// endpoint 复制连接信息
+func (c *DatabaseConfig) endpoint() *DatabaseConfig {
+	return &DatabaseConfig{
+		Hosts:    c.Hosts,
+		Port:     c.Port,
+		User:     c.User,
+		Password: c.Password,
+		Name:     c.Name,
+	}
+}
+
+// envConfig 数据库相关的配置项，由 LoadConfig 转换为 DatabaseConfig
+type envConfig struct {
+	Driver   string `env:"DB_DRIVER"`
+	SSLMode  string `env:"DB_SSL_MODE"`
+	Host     string `env:"DB_HOST"`
+	Port     string `env:"DB_PORT"`
+	User     string `env:"DB_USER"`
+	Password string `env:"DB_PASSWORD" secret:"true"`
+	Name     string `env:"DB_NAME"`
+
+	// 主从配置，未开启从库时 DB_MAIN_* 也会覆盖 DB_HOST 等配置
+	EnableSlave   bool   `env:"DB_ENABLE_SLAVE"`
+	MainHost      string `env:"DB_MAIN_HOST"`
+	MainPort      string `env:"DB_MAIN_PORT"`
+	MainUser      string `env:"DB_MAIN_USER"`
+	MainPassword  string `env:"DB_MAIN_PASSWORD" secret:"true"`
+	SlaveHost     string `env:"DB_SLAVE_HOST"`
+	SlavePort     string `env:"DB_SLAVE_PORT"`
+	SlaveUser     string `env:"DB_SLAVE_USER"`
+	SlavePassword string `env:"DB_SLAVE_PASSWORD" secret:"true"`
+
+	LogLevel string `env:"DB_LOG_LEVEL"`
+	Timezone string `env:"DB_TIMEZONE"`
+
+	// 连接池配置
+	MaxIdleConns    int  `env:"DB_MAX_IDLE_CONNS"`
+	MaxOpenConns    int  `env:"DB_MAX_OPEN_CONNS"`
+	ConnMaxLifetime int  `env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime int  `env:"DB_CONN_MAX_IDLE_TIME"`
+	PrepareStmt     bool `env:"DB_PREPARE_STMT"`
+
+	// 读写分离、负载均衡与健康检查配置
+	StickyWindow        int    `env:"DB_STICKY_WINDOW"`
+	SlaveWeights        []int  `env:"DB_SLAVE_WEIGHTS"`
+	SlavePolicy         string `env:"DB_SLAVE_POLICY"`
+	HealthCheckInterval int    `env:"DB_HEALTH_CHECK_INTERVAL"`
+	MaxReplicaLag       int    `env:"DB_SLAVE_MAX_LAG"`
+
+	// 慢查询配置，DB_SLOW_THRESHOLD 纯数字按秒处理
+	SlowThreshold time.Duration `env:"DB_SLOW_THRESHOLD"`
+	SlowLogSize   int           `env:"DB_SLOW_LOG_SIZE"`
+
+	// 命名连接，键为小写的连接名，由 loadEnv 按 DB_CONN_<NAME>_* 绑定
+	Connections map[string]*connectionEnv `prefix:"DB_CONN_"`
+}
+
+// connectionEnv 命名连接的配置项，名称为 DB_CONN_<NAME>_ 加上 env 标签
+type connectionEnv struct {
+	Driver   string `env:"DRIVER"`
+	SSLMode  string `env:"SSL_MODE"`
+	Host     string `env:"HOST"`
+	Port     string `env:"PORT"`
+	User     string `env:"USER"`
+	Password string `env:"PASSWORD" secret:"true"`
+	Name     string `env:"NAME"`
+	LogLevel string `env:"LOG_LEVEL"`
+
+	MaxIdleConns    int  `env:"MAX_IDLE_CONNS"`
+	MaxOpenConns    int  `env:"MAX_OPEN_CONNS"`
+	ConnMaxLifetime int  `env:"CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime int  `env:"CONN_MAX_IDLE_TIME"`
+	PrepareStmt     bool `env:"PREPARE_STMT"`
+}
+
+// loadEnv 读取数据库配置项，未设置的使用 DefaultConfig 中的默认值
+func loadEnv() (*envConfig, error) {
+	def := DefaultConfig()
+	env := &envConfig{
+		Driver:              string(def.Driver),
+		SSLMode:             def.SSLMode,
+		User:                def.User,
+		Name:                def.Name,
+		LogLevel:            string(LogLevelInfo),
+		Timezone:            def.Timezone,
+		MaxIdleConns:        def.MaxIdleConns,
+		MaxOpenConns:        def.MaxOpenConns,
+		ConnMaxLifetime:     def.ConnMaxLifetime,
+		ConnMaxIdleTime:     def.ConnMaxIdleTime,
+		StickyWindow:        def.StickyWindow,
+		SlavePolicy:         def.SlavePolicy,
+		HealthCheckInterval: def.HealthCheckInterval,
+		MaxReplicaLag:       def.MaxReplicaLag,
+		SlowThreshold:       def.SlowThreshold,
+		SlowLogSize:         def.SlowLogSize,
+	}
+	if err := config.Bind(env); err != nil {
+		return nil, err
+	}
+	connections, err := bindConnections(env)
+	if err != nil {
+		return nil, err
+	}
+	env.Connections = connections
+	return env, nil
+}
+
 func init() {
-	databaseConfig = &DatabaseConfig{
-		Hosts:    []string{"localhost"},
-		Port:     "3306",
-		User:     "root",
-		Password: "",
-		Name:     "example",
-		LogLevel: logger.Info,
-		Timezone: "Asia/Shanghai",
-		Main: &DatabaseConfig{
-			Hosts:    []string{"localhost"},
-			Port:     "3306",
-			User:     "root",
-			Password: "",
-			Name:     "example",
-		},
-		Slave: &DatabaseConfig{
-			Hosts:    []string{"localhost"},
-			Port:     "3306",
-			User:     "root",
-			Password: "",
-			Name:     "example",
-		},
-	}
-
-	if curPath, err := os.Getwd(); err == nil {
-		_ = godotenv.Load(filepath.Join(curPath, ".env"))
-	}
-
-	databaseConfig.EnableSlave = utils.GetEnv("DB_ENABLE_SLAVE", "false") == "true"
-
-	if databaseConfig.EnableSlave {
-		databaseConfig.Main.Hosts = parseHosts(utils.GetEnv("DB_MAIN_HOST", strings.Join(databaseConfig.Main.Hosts, ",")))
-		databaseConfig.Main.Port = utils.GetEnv("DB_MAIN_PORT", databaseConfig.Main.Port)
-		databaseConfig.Main.User = utils.GetEnv("DB_MAIN_USER", databaseConfig.Main.User)
-		databaseConfig.Main.Password = utils.GetEnv("DB_MAIN_PASSWORD", databaseConfig.Main.Password)
-		databaseConfig.Main.Name = utils.GetEnv("DB_NAME", databaseConfig.Main.Name)
-
-		databaseConfig.Slave.Hosts = parseHosts(utils.GetEnv("DB_SLAVE_HOST", strings.Join(databaseConfig.Slave.Hosts, ",")))
-		databaseConfig.Slave.Port = utils.GetEnv("DB_SLAVE_PORT", databaseConfig.Slave.Port)
-		databaseConfig.Slave.User = utils.GetEnv("DB_SLAVE_USER", databaseConfig.Slave.User)
-		databaseConfig.Slave.Password = utils.GetEnv("DB_SLAVE_PASSWORD", databaseConfig.Slave.Password)
-		databaseConfig.Slave.Name = utils.GetEnv("DB_NAME", databaseConfig.Slave.Name)
+	config.Register("database", func() (any, error) { return loadEnv() })
+}
 
-	} else {
-		databaseConfig.Main.Hosts = parseHosts(utils.GetEnv("DB_HOST", strings.Join(databaseConfig.Hosts, ",")))
-		databaseConfig.Main.Port = utils.GetEnv("DB_PORT", databaseConfig.Port)
-		databaseConfig.Main.User = utils.GetEnv("DB_USER", databaseConfig.User)
-		databaseConfig.Main.Password = utils.GetEnv("DB_PASSWORD", databaseConfig.Password)
-		databaseConfig.Main.Name = utils.GetEnv("DB_NAME", databaseConfig.Name)
+// or 返回第一个非空值
+func or(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
 
-		// 兼容一下单服务但是填的是Main的情况
-		mainHost := utils.GetEnv("DB_MAIN_HOST", "-")
-		if mainHost != "-" {
-			databaseConfig.Main.Hosts = parseHosts(mainHost)
+// LoadConfig 从配置文件与环境变量加载数据库配置
+func LoadConfig() (*DatabaseConfig, error) {
+	env, err := loadEnv()
+	if err != nil {
+		return nil, err
+	}
+	cfg := DefaultConfig()
+
+	// 驱动决定默认端口，需要先于连接配置读取
+	driver, err := parseDriver(env.Driver)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Driver = driver
+	cfg.SSLMode = env.SSLMode
+	cfg.Port = driver.defaultPort()
+	cfg.EnableSlave = env.EnableSlave
+
+	if cfg.EnableSlave {
+		cfg.Main = &DatabaseConfig{
+			Hosts:    parseHosts(or(env.MainHost, strings.Join(cfg.Hosts, ","))),
+			Port:     or(env.MainPort, cfg.Port),
+			User:     or(env.MainUser, cfg.User),
+			Password: env.MainPassword,
+			Name:     env.Name,
 		}
-		mainPort := utils.GetEnv("DB_MAIN_PORT", "-")
-		if mainPort != "-" {
-			databaseConfig.Main.Port = mainPort
+		cfg.Slave = &DatabaseConfig{
+			Hosts:    parseHosts(or(env.SlaveHost, strings.Join(cfg.Hosts, ","))),
+			Port:     or(env.SlavePort, cfg.Port),
+			User:     or(env.SlaveUser, cfg.User),
+			Password: env.SlavePassword,
+			Name:     env.Name,
 		}
-		mainUser := utils.GetEnv("DB_MAIN_USER", "-")
-		if mainUser != "-" {
-			databaseConfig.Main.User = mainUser
+	} else {
+		// 兼容一下单服务但是填的是Main的情况
+		cfg.Main = &DatabaseConfig{
+			Hosts:    parseHosts(or(env.MainHost, env.Host, strings.Join(cfg.Hosts, ","))),
+			Port:     or(env.MainPort, env.Port, cfg.Port),
+			User:     or(env.MainUser, env.User),
+			Password: or(env.MainPassword, env.Password),
+			Name:     env.Name,
 		}
-		mainPassword := utils.GetEnv("DB_MAIN_PASSWORD", "-")
-		if mainPassword != "-" {
-			databaseConfig.Main.Password = mainPassword
+		cfg.Slave = cfg.endpoint()
+	}
+
+	cfg.LogLevel = parseLogLevel(env.LogLevel, logger.Info)
+	cfg.Timezone = env.Timezone
+	cfg.MaxIdleConns = env.MaxIdleConns
+	cfg.MaxOpenConns = env.MaxOpenConns
+	cfg.ConnMaxLifetime = env.ConnMaxLifetime
+	cfg.ConnMaxIdleTime = env.ConnMaxIdleTime
+	cfg.PrepareStmt = env.PrepareStmt
+	cfg.StickyWindow = env.StickyWindow
+	cfg.SlaveWeights = env.SlaveWeights
+	cfg.SlavePolicy = env.SlavePolicy
+	cfg.HealthCheckInterval = env.HealthCheckInterval
+	cfg.MaxReplicaLag = env.MaxReplicaLag
+	cfg.SlowThreshold = env.SlowThreshold
+	cfg.SlowLogSize = env.SlowLogSize
+
+	connections, err := parseConnections(cfg, env.Connections)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Connections = connections
+
+	cfg.normalize()
+	return cfg, nil
+}
+
+// normalize 补全代码中构造的配置：未设置 Main 时使用顶层的连接信息，
+// 主从库与命名连接未设置的驱动、端口、连接池配置沿用顶层配置
+func (c *DatabaseConfig) normalize() {
+	def := DefaultConfig()
+	if c.Driver == "" {
+		c.Driver = DriverMySQL
+	}
+	if c.Timezone == "" {
+		c.Timezone = def.Timezone
+	}
+	if c.LogLevel == 0 {
+		c.LogLevel = def.LogLevel
+	}
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = def.MaxIdleConns
+	}
+	if c.MaxOpenConns == 0 {
+		c.MaxOpenConns = def.MaxOpenConns
+	}
+	if c.ConnMaxLifetime == 0 {
+		c.ConnMaxLifetime = def.ConnMaxLifetime
+	}
+	if c.ConnMaxIdleTime == 0 {
+		c.ConnMaxIdleTime = def.ConnMaxIdleTime
+	}
+	if c.SlavePolicy == "" {
+		c.SlavePolicy = def.SlavePolicy
+	}
+	if c.SlowThreshold == 0 {
+		c.SlowThreshold = def.SlowThreshold
+	}
+	if c.SlowLogSize == 0 {
+		c.SlowLogSize = def.SlowLogSize
+	}
+	if c.Main == nil {
+		c.Main = c.endpoint()
+	}
+	if c.Slave == nil {
+		c.Slave = &DatabaseConfig{}
+		c.EnableSlave = false
+	}
+
+	for _, conn := range []*DatabaseConfig{c.Main, c.Slave} {
+		conn.inherit(c)
+	}
+	for _, conn := range c.Connections {
+		conn.inherit(c)
+		if conn.Timezone == "" {
+			conn.Timezone = c.Timezone
 		}
 	}
+}
 
-	switch LogLevel(utils.GetEnv("DB_LOG_LEVEL", string(LogLevelInfo))) {
-	case LogLevelDebug:
-		databaseConfig.LogLevel = logger.Info
-	case LogLevelInfo:
-		databaseConfig.LogLevel = logger.Info
-	case LogLevelWarn:
-		databaseConfig.LogLevel = logger.Warn
-	case LogLevelError:
-		databaseConfig.LogLevel = logger.Error
-	default:
-		databaseConfig.LogLevel = logger.Info
+// inherit 未设置的驱动、端口与连接池配置沿用 from
+func (c *DatabaseConfig) inherit(from *DatabaseConfig) {
+	if c.Driver == "" {
+		c.Driver = from.Driver
+	}
+	if c.SSLMode == "" {
+		c.SSLMode = from.SSLMode
 	}
+	if len(c.Hosts) == 0 {
+		c.Hosts = []string{"localhost"}
+	}
+	if c.Port == "" {
+		c.Port = c.Driver.defaultPort()
+	}
+	if c.LogLevel == 0 {
+		c.LogLevel = from.LogLevel
+	}
+	if c.Timezone == "" {
+		c.Timezone = from.Timezone
+	}
+	if c.SlowThreshold == 0 {
+		c.SlowThreshold = from.SlowThreshold
+	}
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = from.MaxIdleConns
+	}
+	if c.MaxOpenConns == 0 {
+		c.MaxOpenConns = from.MaxOpenConns
+	}
+	if c.ConnMaxLifetime == 0 {
+		c.ConnMaxLifetime = from.ConnMaxLifetime
+	}
+	if c.ConnMaxIdleTime == 0 {
+		c.ConnMaxIdleTime = from.ConnMaxIdleTime
+	}
+	if !c.PrepareStmt {
+		c.PrepareStmt = from.PrepareStmt
+	}
+}
 
-	// 时区配置，默认 Asia/Shanghai
-	databaseConfig.Timezone = utils.GetEnv("DB_TIMEZONE", databaseConfig.Timezone)
+const connectionPrefix = "DB_CONN_"
 
-	// 连接池配置
-	databaseConfig.MaxIdleConns = utils.GetEnvInt("DB_MAX_IDLE_CONNS", 10)
-	databaseConfig.MaxOpenConns = utils.GetEnvInt("DB_MAX_OPEN_CONNS", 100)
-	databaseConfig.ConnMaxLifetime = utils.GetEnvInt("DB_CONN_MAX_LIFETIME", 30)
-	databaseConfig.ConnMaxIdleTime = utils.GetEnvInt("DB_CONN_MAX_IDLE_TIME", 3)
-	databaseConfig.PrepareStmt = utils.GetEnv("DB_PREPARE_STMT", "false") == "true"
+// connectionKeys 命名连接支持的配置项，按长度倒序匹配，避免 _NAME 误匹配
+var connectionKeys = []string{
+	"_CONN_MAX_IDLE_TIME",
+	"_CONN_MAX_LIFETIME",
+	"_MAX_IDLE_CONNS",
+	"_MAX_OPEN_CONNS",
+	"_PREPARE_STMT",
+	"_LOG_LEVEL",
+	"_SSL_MODE",
+	"_PASSWORD",
+	"_DRIVER",
+	"_HOST",
+	"_PORT",
+	"_USER",
+	"_NAME",
+}
+
+// bindConnections 按已设置的 DB_CONN_<NAME>_* 配置项绑定命名连接
+// 例如 DB_CONN_ANALYTICS_HOST、DB_CONN_LEGACY_BILLING_NAME，未设置的驱动、用户、日志级别与连接池配置沿用全局配置
+func bindConnections(env *envConfig) (map[string]*connectionEnv, error) {
+	prefixes := map[string]string{}
+	for _, key := range config.Keys(connectionPrefix) {
+		rest := strings.TrimPrefix(key, connectionPrefix)
+		for _, suffix := range connectionKeys {
+			if name, ok := strings.CutSuffix(rest, suffix); ok && name != "" {
+				prefixes[strings.ToLower(name)] = connectionPrefix + name + "_"
+				break
+			}
+		}
+	}
+
+	connections := make(map[string]*connectionEnv, len(prefixes))
+	var errs []error
+	for name, prefix := range prefixes {
+		c := &connectionEnv{
+			Driver:          env.Driver,
+			SSLMode:         env.SSLMode,
+			Host:            "localhost",
+			User:            or(env.MainUser, env.User),
+			Name:            name,
+			LogLevel:        env.LogLevel,
+			MaxIdleConns:    env.MaxIdleConns,
+			MaxOpenConns:    env.MaxOpenConns,
+			ConnMaxLifetime: env.ConnMaxLifetime,
+			ConnMaxIdleTime: env.ConnMaxIdleTime,
+			PrepareStmt:     env.PrepareStmt,
+		}
+		if err := config.BindPrefix(c, prefix); err != nil {
+			errs = append(errs, err)
+		}
+		connections[name] = c
+	}
+	return connections, errors.Join(errs...)
+}
+
+// parseConnections 把命名连接的配置项转换为 DatabaseConfig
+func parseConnections(cfg *DatabaseConfig, envs map[string]*connectionEnv) (map[string]*DatabaseConfig, error) {
+	connections := make(map[string]*DatabaseConfig, len(envs))
+	for name, env := range envs {
+		driver, err := parseDriver(env.Driver)
+		if err != nil {
+			return nil, fmt.Errorf("database connection %s: %w", name, err)
+		}
+		c := &DatabaseConfig{
+			Driver:          driver,
+			SSLMode:         env.SSLMode,
+			Hosts:           []string{env.Host},
+			Port:            or(env.Port, driver.defaultPort()),
+			User:            env.User,
+			Password:        env.Password,
+			Name:            env.Name,
+			Timezone:        cfg.Timezone,
+			LogLevel:        parseLogLevel(env.LogLevel, cfg.LogLevel),
+			MaxIdleConns:    env.MaxIdleConns,
+			MaxOpenConns:    env.MaxOpenConns,
+			ConnMaxLifetime: env.ConnMaxLifetime,
+			ConnMaxIdleTime: env.ConnMaxIdleTime,
+			PrepareStmt:     env.PrepareStmt,
+		}
+		c.inherit(cfg)
+		connections[name] = c
+	}
+	return connections, nil
 }
@@ -0,0 +1,175 @@
+package databases
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// Operator 过滤操作符
+type Operator string
+
+const (
+	OpEq      Operator = "eq"
+	OpNe      Operator = "ne"
+	OpIn      Operator = "in"
+	OpNotIn   Operator = "not_in"
+	OpLike    Operator = "like" // 包含匹配，Value 中的 % 和 _ 按普通字符处理
+	OpGt      Operator = "gt"
+	OpGte     Operator = "gte"
+	OpLt      Operator = "lt"
+	OpLte     Operator = "lte"
+	OpBetween Operator = "between" // 闭区间，Value 为两个元素的切片
+	OpIsNull  Operator = "is_null" // Value 为 true 时匹配 NULL，false 时匹配非 NULL
+)
+
+// likeEscape LIKE 的转义字符，不使用反斜杠以兼容 MySQL 字符串转义和 SQLite
+const likeEscape = "!"
+
+var likeReplacer = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// Filter 结构化过滤条件，对应 GraphQL 的过滤输入
+// Field 为 GraphQL 字段名、Go 字段名或列名，只能使用 Repository 白名单中的字段，值全部以参数形式绑定
+// 同时设置时，Field 条件、And 中的所有条件以及 Or 中任意一个条件需要同时满足
+type Filter struct {
+	Field string
+	Op    Operator
+	Value any
+	And   []*Filter
+	Or    []*Filter
+}
+
+// Where 创建单个字段的过滤条件
+func Where(field string, op Operator, value any) *Filter {
+	return &Filter{Field: field, Op: op, Value: value}
+}
+
+// And 组合多个条件，全部满足时匹配
+func And(filters ...*Filter) *Filter {
+	return &Filter{And: filters}
+}
+
+// Or 组合多个条件，任意一个满足时匹配
+func Or(filters ...*Filter) *Filter {
+	return &Filter{Or: filters}
+}
+
+// build 将过滤条件转换为 SQL 表达式，resolve 负责把字段名转换为列名并校验白名单
+func (f *Filter) build(resolve func(field string) (string, error)) (clause.Expression, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	exprs := make([]clause.Expression, 0, 1+len(f.And))
+	if f.Field != "" {
+		column, err := resolve(f.Field)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := f.condition(column)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	for _, sub := range f.And {
+		expr, err := sub.build(resolve)
+		if err != nil {
+			return nil, err
+		}
+		if expr != nil {
+			exprs = append(exprs, expr)
+		}
+	}
+
+	if len(f.Or) > 0 {
+		ors := make([]clause.Expression, 0, len(f.Or))
+		for _, sub := range f.Or {
+			expr, err := sub.build(resolve)
+			if err != nil {
+				return nil, err
+			}
+			if expr != nil {
+				ors = append(ors, expr)
+			}
+		}
+		if len(ors) > 0 {
+			exprs = append(exprs, clause.Or(ors...))
+		}
+	}
+
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+	return clause.And(exprs...), nil
+}
+
+func (f *Filter) condition(name string) (clause.Expression, error) {
+	column := clause.Column{Table: clause.CurrentTable, Name: name}
+	switch f.Op {
+	case OpEq, "":
+		return clause.Eq{Column: column, Value: f.Value}, nil
+	case OpNe:
+		return clause.Neq{Column: column, Value: f.Value}, nil
+	case OpGt:
+		return clause.Gt{Column: column, Value: f.Value}, nil
+	case OpGte:
+		return clause.Gte{Column: column, Value: f.Value}, nil
+	case OpLt:
+		return clause.Lt{Column: column, Value: f.Value}, nil
+	case OpLte:
+		return clause.Lte{Column: column, Value: f.Value}, nil
+	case OpIn, OpNotIn:
+		values, err := filterValues(f.Field, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		if f.Op == OpNotIn {
+			return clause.Not(clause.IN{Column: column, Values: values}), nil
+		}
+		return clause.IN{Column: column, Values: values}, nil
+	case OpBetween:
+		values, err := filterValues(f.Field, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != 2 {
+			return nil, fmt.Errorf("filter %s: between requires exactly 2 values", f.Field)
+		}
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []any{column, values[0], values[1]}}, nil
+	case OpLike:
+		value, ok := f.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("filter %s: like requires a string value", f.Field)
+		}
+		pattern := "%" + likeReplacer.Replace(value) + "%"
+		return clause.Expr{SQL: "? LIKE ? ESCAPE '" + likeEscape + "'", Vars: []any{column, pattern}}, nil
+	case OpIsNull:
+		isNull, ok := f.Value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("filter %s: is_null requires a bool value", f.Field)
+		}
+		if isNull {
+			return clause.Eq{Column: column, Value: nil}, nil
+		}
+		return clause.Neq{Column: column, Value: nil}, nil
+	default:
+		return nil, fmt.Errorf("filter %s: unsupported operator %q", f.Field, f.Op)
+	}
+}
+
+// filterValues 将切片类型的过滤值展开
+func filterValues(field string, value any) ([]any, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("filter %s: value must be a list", field)
+	}
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, nil
+}
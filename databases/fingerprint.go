@@ -0,0 +1,157 @@
+package databases
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// listPattern 把 IN (?, ?, ?) 与 VALUES (?, ?), (?, ?) 归一为 (?+)
+	listPattern  = regexp.MustCompile(`\(\s*\?(\s*,\s*\?)*\s*\)`)
+	multiPattern = regexp.MustCompile(`\(\?\+\)(\s*,\s*\(\?\+\))+`)
+)
+
+// Fingerprint 把 SQL 归一为指纹：去掉注释与字面量，合并空白并转为小写，
+// 参数个数不同的 IN 列表与多行 VALUES 视为同一条语句
+//
+//	SELECT * FROM users WHERE id IN (1, 2, 3) AND name = 'a' -> select * from users where id in (?+) and name = ?
+func Fingerprint(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+
+	space := false
+	n := len(sql)
+	for i := 0; i < n; {
+		c := sql[i]
+		switch {
+		// 注释
+		case c == '-' && i+1 < n && sql[i+1] == '-':
+			for i < n && sql[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+		case c == '/' && i+1 < n && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = n
+			} else {
+				i += end + 4
+			}
+			space = true
+			continue
+
+		// 空白合并为一个空格
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			i++
+			continue
+
+		// 字符串字面量，支持 '' 与 \' 转义
+		case c == '\'':
+			i++
+			for i < n {
+				if sql[i] == '\\' {
+					i += 2
+					continue
+				}
+				if sql[i] == '\'' {
+					if i+1 < n && sql[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+			writeToken(&b, &space, "?")
+			continue
+
+		// 数字字面量与 PostgreSQL 的 $1 占位符，标识符中的数字保留
+		case isDigit(c) && !prevIdent(sql, i), c == '$' && i+1 < n && isDigit(sql[i+1]):
+			i++
+			for i < n && (isDigit(sql[i]) || sql[i] == '.' || sql[i] == 'x' || sql[i] == 'X' || isHex(sql[i])) {
+				i++
+			}
+			writeToken(&b, &space, "?")
+			continue
+		}
+
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		b.WriteByte(c)
+		i++
+	}
+
+	fp := listPattern.ReplaceAllString(b.String(), "(?+)")
+	return multiPattern.ReplaceAllString(fp, "(?+)")
+}
+
+func writeToken(b *strings.Builder, space *bool, token string) {
+	if *space && b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	*space = false
+	b.WriteString(token)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// prevIdent 判断 i 之前的字符是否属于标识符，例如 table1、`t2`.id
+func prevIdent(sql string, i int) bool {
+	if i == 0 {
+		return false
+	}
+	p := sql[i-1]
+	return p == '_' || isDigit(p) || (p >= 'a' && p <= 'z') || (p >= 'A' && p <= 'Z')
+}
+
+// sqlOperation 返回 SQL 的操作类型，只读取第一个关键字，跳过开头的空白、括号与注释
+func sqlOperation(sql string) string {
+	i, n := 0, len(sql)
+skip:
+	for i < n {
+		switch c := sql[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '(':
+			i++
+			continue
+		case c == '-' && i+1 < n && sql[i+1] == '-':
+			for i < n && sql[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < n && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return "other"
+			}
+			i += end + 4
+			continue
+		}
+		break skip
+	}
+	start := i
+	for i < n && ((sql[i] >= 'a' && sql[i] <= 'z') || (sql[i] >= 'A' && sql[i] <= 'Z')) {
+		i++
+	}
+	word := strings.ToLower(sql[start:i])
+	switch word {
+	case "select", "insert", "update", "delete", "replace":
+		return word
+	case "with":
+		return "select"
+	}
+	return "other"
+}
@@ -0,0 +1,134 @@
+package databases
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/light-speak/lighthouse/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"gorm.io/gorm/logger"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		sql       string
+		expected  string
+		operation string
+	}{
+		{"SELECT * FROM `users` WHERE id = 1", "select * from `users` where id = ?", "select"},
+		{"select *  from users\n where name = 'it''s' and note = 'a\\'b'", "select * from users where name = ? and note = ?", "select"},
+		{"SELECT * FROM users WHERE id IN (1, 2, 3)", "select * from users where id in (?+)", "select"},
+		{"SELECT * FROM users WHERE id IN (7)", "select * from users where id in (?+)", "select"},
+		{"INSERT INTO t1 (a,b) VALUES (1,'x'),(2,'y')", "insert into t1 (a,b) values (?+)", "insert"},
+		{"UPDATE users SET score = -3.5, hash = 0xFF WHERE id = $1", "update users set score = -?, hash = ? where id = ?", "update"},
+		{"/* trace */ DELETE FROM logs -- cleanup\nWHERE created_at < '2024-01-01'", "delete from logs where created_at < ?", "delete"},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", "with t as (select ?) select * from t", "select"},
+		{"SHOW TABLES", "show tables", "other"},
+	}
+
+	for _, test := range tests {
+		t.Run("Test Fingerprint "+test.expected, func(t *testing.T) {
+			result := Fingerprint(test.sql)
+			if result != test.expected {
+				t.Errorf("Fingerprint(%q) = %q; expected %q", test.sql, result, test.expected)
+			}
+			if op := sqlOperation(test.sql); op != test.operation {
+				t.Errorf("sqlOperation(%q) = %q; expected %q", test.sql, op, test.operation)
+			}
+		})
+	}
+}
+
+func TestSlowLogRing(t *testing.T) {
+	log := newSlowLog(3)
+	for i := 1; i <= 5; i++ {
+		log.add(SlowQuery{Fingerprint: strconv.Itoa(i % 4), Rows: int64(i)})
+	}
+	entries := log.snapshot()
+	if len(entries) != 3 || entries[0].Rows != 3 || entries[2].Rows != 5 {
+		t.Fatalf("snapshot() = %+v; expected rows 3..5", entries)
+	}
+
+	log.resize(2)
+	entries = log.snapshot()
+	if len(entries) != 2 || entries[0].Rows != 4 || entries[1].Rows != 5 {
+		t.Errorf("snapshot() after resize = %+v; expected rows 4..5", entries)
+	}
+	if len(log.counts) != 2 || log.counts["0"] != 1 || log.counts["1"] != 1 {
+		t.Errorf("counts = %v; expected fingerprints 0 and 1", log.counts)
+	}
+}
+
+func TestTraceMetrics(t *testing.T) {
+	slowQueries.reset()
+	defer slowQueries.reset()
+	fc := func() (string, int64) { return "SELECT * FROM users WHERE id = 42", 1 }
+	fingerprint := "select * from users where id = ?"
+
+	t.Run("Test Fast Query", func(t *testing.T) {
+		l := &DBLogger{LogLevel: logger.Silent, SlowThreshold: time.Hour, Connection: "trace_fast"}
+		l.Trace(context.Background(), time.Now(), fc, nil)
+		if c := histogramCount(t, "select", "trace_fast", otherFingerprint); c != 1 {
+			t.Errorf("sample count = %d, expected 1", c)
+		}
+		if len(slowQueries.snapshot()) != 0 {
+			t.Error("fast query should not be recorded")
+		}
+	})
+
+	t.Run("Test Slow Query", func(t *testing.T) {
+		l := &DBLogger{LogLevel: logger.Silent, SlowThreshold: time.Nanosecond, Connection: "trace_slow"}
+		l.Trace(context.Background(), time.Now().Add(-time.Millisecond), fc, nil)
+		entries := slowQueries.snapshot()
+		if len(entries) != 1 || entries[0].Fingerprint != fingerprint || entries[0].Operation != "select" {
+			t.Errorf("slow queries = %+v", entries)
+		}
+		if c := histogramCount(t, "select", "trace_slow", fingerprint); c != 1 {
+			t.Errorf("sample count = %d, expected 1", c)
+		}
+	})
+
+	t.Run("Test Fast Query With Slow Fingerprint", func(t *testing.T) {
+		l := &DBLogger{LogLevel: logger.Silent, SlowThreshold: time.Hour, Connection: "trace_slow"}
+		l.Trace(context.Background(), time.Now(), fc, nil)
+		l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+		if c := histogramCount(t, "select", "trace_slow", fingerprint); c != 2 {
+			t.Errorf("sample count = %d, expected 2", c)
+		}
+		if c := histogramCount(t, "select", "trace_slow", otherFingerprint); c != 1 {
+			t.Errorf("sample count = %d, expected 1", c)
+		}
+	})
+
+	t.Run("Test Evicted Fingerprint Is Removed", func(t *testing.T) {
+		slowQueries.reset()
+		ch := make(chan prometheus.Metric, 64)
+		go func() {
+			metrics.DBQueryDuration.Collect(ch)
+			close(ch)
+		}()
+		for metric := range ch {
+			var m dto.Metric
+			if err := metric.Write(&m); err != nil {
+				t.Fatal(err)
+			}
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "fingerprint" && label.GetValue() == fingerprint {
+					t.Error("series of an evicted fingerprint should be deleted")
+				}
+			}
+		}
+	})
+}
+
+func histogramCount(t *testing.T, labels ...string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := metrics.DBQueryDuration.WithLabelValues(labels...).(prometheus.Histogram).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
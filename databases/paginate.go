@@ -0,0 +1,190 @@
+package databases
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/light-speak/lighthouse/lighterr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PageDataField 分页结果在 GraphQL 中的列表字段名，Paginate 从该字段的选择集中读取需要预加载的关联
+const PageDataField = "data"
+
+// Pagination 分页参数
+// Cursor 为 nil 时使用 offset 分页，非 nil 时使用游标（keyset）分页，空字符串表示第一页
+type Pagination struct {
+	Page   int // 页码，从 1 开始，仅 offset 分页使用
+	Size   int // 每页条数，<= 0 时为 15，不超过 Repository 的最大条数
+	Cursor *string
+}
+
+// Page 分页结果
+type Page[T any] struct {
+	Items           []*T
+	Cursors         []string // 每条记录的游标，与 Items 一一对应，offset 分页时为记录的偏移量
+	Total           int64    // 总条数，仅 offset 分页返回
+	Page            int      // 当前页码，仅 offset 分页返回
+	Size            int
+	HasNextPage     bool
+	HasPreviousPage bool
+	EndCursor       string // 最后一条记录的游标，仅游标分页返回，作为下一页的 Cursor
+}
+
+// Paginate 分页查询
+// 游标分页按排序字段加主键定位，不需要统计总数，适合深分页；排序字段应为非空字段
+func (r *Repository[T]) Paginate(ctx context.Context, q *Query, p Pagination) (*Page[T], error) {
+	db, sorts, err := r.prepare(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	size := p.Size
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	if r.options.maxPageSize > 0 && size > r.options.maxPageSize {
+		size = r.options.maxPageSize
+	}
+
+	if p.Cursor != nil {
+		return r.paginateCursor(ctx, db, q, sorts, *p.Cursor, size)
+	}
+
+	page := p.Page
+	if page <= 0 {
+		page = 1
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, lighterr.NewDatabaseError("查询失败", err)
+	}
+
+	items := make([]*T, 0, size)
+	query := r.preload(ctx, r.order(db, sorts), q, true)
+	if err := query.Offset((page - 1) * size).Limit(size).Find(&items).Error; err != nil {
+		return nil, lighterr.NewDatabaseError("查询失败", err)
+	}
+	offset := (page - 1) * size
+	cursors := make([]string, len(items))
+	for i := range items {
+		cursors[i] = offsetCursor(offset + i)
+	}
+	return &Page[T]{
+		Items:           items,
+		Cursors:         cursors,
+		Total:           total,
+		Page:            page,
+		Size:            size,
+		HasNextPage:     int64(page*size) < total,
+		HasPreviousPage: page > 1,
+	}, nil
+}
+
+func (r *Repository[T]) paginateCursor(ctx context.Context, db *gorm.DB, q *Query, sorts []resolvedSort, cursor string, size int) (*Page[T], error) {
+	if cursor != "" {
+		values, err := decodeCursor(cursor, sorts)
+		if err != nil {
+			return nil, lighterr.NewInvalidInputError("游标无效", err)
+		}
+		db = db.Where(keysetCondition(sorts, values))
+	}
+
+	// 多取一条用于判断是否还有下一页
+	items := make([]*T, 0, size+1)
+	query := r.preload(ctx, r.order(db, sorts), q, true)
+	if err := query.Limit(size + 1).Find(&items).Error; err != nil {
+		return nil, lighterr.NewDatabaseError("查询失败", err)
+	}
+
+	page := &Page[T]{Size: size, HasPreviousPage: cursor != ""}
+	if len(items) > size {
+		items = items[:size]
+		page.HasNextPage = true
+	}
+	page.Items = items
+	page.Cursors = make([]string, len(items))
+	for i, item := range items {
+		encoded, err := encodeCursor(ctx, sorts, item)
+		if err != nil {
+			return nil, lighterr.NewInternalError("生成游标失败", err)
+		}
+		page.Cursors[i] = encoded
+	}
+	if len(items) > 0 {
+		page.EndCursor = page.Cursors[len(items)-1]
+	}
+	return page, nil
+}
+
+// keysetCondition 生成 (a > ?) OR (a = ? AND b > ?) ... 形式的条件，支持各字段不同的排序方向
+func keysetCondition(sorts []resolvedSort, values []any) clause.Expression {
+	ors := make([]clause.Expression, 0, len(sorts))
+	for i, s := range sorts {
+		ands := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, clause.Eq{Column: sortColumn(sorts[j]), Value: values[j]})
+		}
+		if s.desc {
+			ands = append(ands, clause.Lt{Column: sortColumn(s), Value: values[i]})
+		} else {
+			ands = append(ands, clause.Gt{Column: sortColumn(s), Value: values[i]})
+		}
+		ors = append(ors, clause.And(ands...))
+	}
+	return clause.Or(ors...)
+}
+
+func sortColumn(s resolvedSort) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: s.field.DBName}
+}
+
+// encodeCursor 将记录的排序字段值编码为游标
+func encodeCursor(ctx context.Context, sorts []resolvedSort, item any) (string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(item))
+	values := make([]any, len(sorts))
+	for i, s := range sorts {
+		values[i], _ = s.field.ValueOf(ctx, rv)
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// offsetCursor offset 分页时每条记录的游标
+func offsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// decodeCursor 解码游标，并按字段类型还原每个值，排序条件与生成游标时不一致会返回错误
+func decodeCursor(cursor string, sorts []resolvedSort) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, err
+	}
+	if len(raws) != len(sorts) {
+		return nil, errors.New("cursor does not match the sort fields")
+	}
+
+	values := make([]any, len(sorts))
+	for i, s := range sorts {
+		value := reflect.New(s.field.FieldType)
+		if err := json.Unmarshal(raws[i], value.Interface()); err != nil {
+			return nil, fmt.Errorf("cursor field %s: %w", s.field.Name, err)
+		}
+		values[i] = value.Elem().Interface()
+	}
+	return values, nil
+}
@@ -0,0 +1,41 @@
+package databases
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	pluginMu sync.Mutex
+	plugins  []gorm.Plugin
+)
+
+func init() {
+	RegisterPlugin(tenantPlugin{})
+}
+
+// RegisterPlugin 注册在每个数据库连接（默认连接、从库、命名连接）上启用的 gorm 插件
+// 只对之后建立的连接生效，需要在 Init 之前注册，通常放在 init 中
+func RegisterPlugin(p gorm.Plugin) {
+	pluginMu.Lock()
+	defer pluginMu.Unlock()
+	for _, registered := range plugins {
+		if registered.Name() == p.Name() {
+			return
+		}
+	}
+	plugins = append(plugins, p)
+}
+
+func usePlugins(db *gorm.DB) error {
+	pluginMu.Lock()
+	registered := append([]gorm.Plugin(nil), plugins...)
+	pluginMu.Unlock()
+	for _, p := range registered {
+		if err := db.Use(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package databases
+
+import (
+	"context"
+	"math"
+	"reflect"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// PaginateType @paginate 指令的分页方式
+type PaginateType string
+
+const (
+	PaginateCursor PaginateType = "CURSOR"
+	PaginateOffset PaginateType = "OFFSET"
+)
+
+// PageInfo Relay 风格分页信息，对应生成的 GraphQL PageInfo 类型
+// Total、CurrentPage、LastPage 仅 offset 分页返回
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+	Total           *int32  `json:"total,omitempty"`
+	CurrentPage     *int32  `json:"currentPage,omitempty"`
+	LastPage        *int32  `json:"lastPage,omitempty"`
+}
+
+// PageInfo 返回分页结果对应的 PageInfo
+func (p *Page[T]) PageInfo() *PageInfo {
+	info := &PageInfo{
+		HasNextPage:     p.HasNextPage,
+		HasPreviousPage: p.HasPreviousPage,
+	}
+	if len(p.Cursors) > 0 {
+		info.StartCursor = &p.Cursors[0]
+		info.EndCursor = &p.Cursors[len(p.Cursors)-1]
+	}
+	if p.Page > 0 {
+		lastPage := int64(1)
+		if p.Size > 0 && p.Total > 0 {
+			lastPage = (p.Total + int64(p.Size) - 1) / int64(p.Size)
+		}
+		info.Total = int32Ptr(p.Total)
+		info.CurrentPage = int32Ptr(int64(p.Page))
+		info.LastPage = int32Ptr(lastPage)
+	}
+	return info
+}
+
+// PaginationFromContext 按当前字段的 @paginate 指令和 first/after/page 参数构建分页参数
+// 用于 @paginate 字段的 resolver，不在 GraphQL 请求中或字段没有 @paginate 时返回空的 offset 分页参数
+func PaginationFromContext(ctx context.Context) Pagination {
+	var p Pagination
+	if !graphql.HasOperationContext(ctx) {
+		return p
+	}
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Field.Definition == nil {
+		return p
+	}
+	directive := fc.Field.Definition.Directives.ForName("paginate")
+	if directive == nil {
+		return p
+	}
+
+	settings := directive.ArgumentMap(nil)
+	size, _ := intValue(settings["defaultSize"])
+	if first, ok := intValue(fc.Args["first"]); ok && first > 0 {
+		size = first
+	}
+	if maxSize, ok := intValue(settings["maxSize"]); ok && maxSize > 0 && size > maxSize {
+		size = maxSize
+	}
+	p.Size = size
+
+	if mode, _ := settings["type"].(string); PaginateType(mode) == PaginateOffset {
+		p.Page, _ = intValue(fc.Args["page"])
+		return p
+	}
+	cursor := ""
+	if after, ok := fc.Args["after"].(*string); ok && after != nil {
+		cursor = *after
+	} else if after, ok := fc.Args["after"].(string); ok {
+		cursor = after
+	}
+	p.Cursor = &cursor
+	return p
+}
+
+// intValue 读取 GraphQL 参数中的整数，兼容 int32/int64 及其指针
+func intValue(v any) (int, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return 0, false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(rv.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+func int32Ptr(v int64) *int32 {
+	if v > math.MaxInt32 {
+		v = math.MaxInt32
+	}
+	n := int32(v)
+	return &n
+}
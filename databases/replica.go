@@ -0,0 +1,353 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/light-speak/lighthouse/logs"
+	"gorm.io/gorm"
+)
+
+// SlavePolicy 从库负载均衡策略
+type SlavePolicy string
+
+const (
+	SlavePolicyRandom     SlavePolicy = "random"      // 加权随机
+	SlavePolicyRoundRobin SlavePolicy = "round_robin" // 平滑加权轮询
+	SlavePolicyLeastConn  SlavePolicy = "least_conn"  // 最少连接（按 sql.DBStats.InUse / 权重）
+)
+
+// replica 从库节点
+type replica struct {
+	host    string
+	config  *DatabaseConfig
+	weight  int
+	db      *gorm.DB // 启动时连接失败则为 nil，由健康检查负责重连
+	healthy bool
+	lag     time.Duration
+	err     error
+	current int // 平滑加权轮询的当前权重
+}
+
+// ReplicaStatus 从库状态
+type ReplicaStatus struct {
+	Host      string        `json:"host"`
+	Weight    int           `json:"weight"`
+	Connected bool          `json:"connected"`
+	Healthy   bool          `json:"healthy"`
+	Lag       time.Duration `json:"lag"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// newReplicas 按配置创建从库节点并尝试连接，连接失败的节点保留下来等待健康检查重连
+func newReplicas(cfg *DatabaseConfig, loc *time.Location) []*replica {
+	replicas := make([]*replica, 0, len(cfg.Slave.Hosts))
+	for i, host := range cfg.Slave.Hosts {
+		slaveConfig := *cfg.Slave
+		slaveConfig.Hosts = []string{host}
+
+		weight := 1
+		if i < len(cfg.SlaveWeights) && cfg.SlaveWeights[i] > 0 {
+			weight = cfg.SlaveWeights[i]
+		}
+
+		r := &replica{
+			host:   host,
+			config: &slaveConfig,
+			weight: weight,
+		}
+		slaveDB, err := initDB(DefaultConnection, &slaveConfig, loc, cfg.Timezone)
+		if err != nil {
+			logs.Error().Err(err).Str("host", host).Msg("slave database init error, will retry in health check")
+			r.err = err
+		} else {
+			r.db = slaveDB
+			r.healthy = true
+		}
+		replicas = append(replicas, r)
+	}
+	return replicas
+}
+
+// refreshSlaveDBs 根据已连接的从库重建 SlaveDBs，没有从库时使用主库，调用方需持有写锁
+func (l *LightDatabase) refreshSlaveDBs() {
+	slaveDBs := make([]*gorm.DB, 0, len(l.replicas))
+	for _, r := range l.replicas {
+		if r.db != nil {
+			slaveDBs = append(slaveDBs, r.db)
+		}
+	}
+	if len(slaveDBs) == 0 {
+		slaveDBs = []*gorm.DB{l.MainDB}
+	}
+	l.SlaveDBs = slaveDBs
+}
+
+// settings 返回连接的配置，未设置时（如测试中直接构造的 LightDatabase）返回空配置，调用方需持有锁
+func (l *LightDatabase) settings() *DatabaseConfig {
+	if l.config == nil {
+		return &DatabaseConfig{}
+	}
+	return l.config
+}
+
+// slaveDBs 返回 SlaveDBs 的快照
+func (l *LightDatabase) slaveDBs() []*gorm.DB {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]*gorm.DB(nil), l.SlaveDBs...)
+}
+
+// pickSlave 按负载均衡策略从健康的从库中选择一个，没有健康从库时回退到主库
+func (l *LightDatabase) pickSlave() *gorm.DB {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.replicas) == 0 {
+		if len(l.SlaveDBs) == 0 {
+			return l.MainDB
+		}
+		return l.SlaveDBs[rand.Intn(len(l.SlaveDBs))]
+	}
+
+	healthy := make([]*replica, 0, len(l.replicas))
+	for _, r := range l.replicas {
+		if r.healthy && r.db != nil {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		return l.MainDB
+	}
+
+	switch SlavePolicy(l.settings().SlavePolicy) {
+	case SlavePolicyRoundRobin:
+		return pickRoundRobin(healthy).db
+	case SlavePolicyLeastConn:
+		return pickLeastConn(healthy).db
+	default:
+		return pickRandom(healthy).db
+	}
+}
+
+func pickRandom(replicas []*replica) *replica {
+	total := 0
+	for _, r := range replicas {
+		total += r.weight
+	}
+	n := rand.Intn(total)
+	for _, r := range replicas {
+		n -= r.weight
+		if n < 0 {
+			return r
+		}
+	}
+	return replicas[len(replicas)-1]
+}
+
+// pickRoundRobin 平滑加权轮询（与 nginx 相同的算法）
+func pickRoundRobin(replicas []*replica) *replica {
+	total := 0
+	var best *replica
+	for _, r := range replicas {
+		r.current += r.weight
+		total += r.weight
+		if best == nil || r.current > best.current {
+			best = r
+		}
+	}
+	best.current -= total
+	return best
+}
+
+func pickLeastConn(replicas []*replica) *replica {
+	var best *replica
+	bestScore := 0.0
+	for _, r := range replicas {
+		inUse := 0
+		if sqlDB, err := r.db.DB(); err == nil {
+			inUse = sqlDB.Stats().InUse
+		}
+		score := float64(inUse) / float64(r.weight)
+		if best == nil || score < bestScore {
+			best = r
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// ReplicaStatus 返回所有从库的健康状态
+func (l *LightDatabase) ReplicaStatus() []ReplicaStatus {
+	if l == nil {
+		return nil
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	status := make([]ReplicaStatus, 0, len(l.replicas))
+	for _, r := range l.replicas {
+		s := ReplicaStatus{
+			Host:      r.host,
+			Weight:    r.weight,
+			Connected: r.db != nil,
+			Healthy:   r.healthy,
+			Lag:       r.lag,
+		}
+		if r.err != nil {
+			s.Error = r.err.Error()
+		}
+		status = append(status, s)
+	}
+	return status
+}
+
+// startHealthCheck 启动从库后台健康检查
+func (l *LightDatabase) startHealthCheck(loc *time.Location) {
+	l.mu.RLock()
+	cfg := l.settings()
+	l.mu.RUnlock()
+	interval := time.Duration(cfg.HealthCheckInterval) * time.Second
+	if interval <= 0 || len(l.replicas) == 0 {
+		return
+	}
+
+	l.stopHealth = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.stopHealth:
+				return
+			case <-ticker.C:
+				l.checkReplicas(loc)
+			}
+		}
+	}()
+	logs.Info().Dur("interval", interval).Int("replicas", len(l.replicas)).Msg("database replica health check started")
+}
+
+// checkReplicas 检查所有从库，剔除不可用或延迟过大的从库，恢复后重新加入
+func (l *LightDatabase) checkReplicas(loc *time.Location) {
+	l.mu.RLock()
+	replicas := append([]*replica(nil), l.replicas...)
+	maxLag := time.Duration(l.settings().MaxReplicaLag) * time.Second
+	l.mu.RUnlock()
+
+	for _, r := range replicas {
+		l.mu.RLock()
+		db := r.db
+		l.mu.RUnlock()
+
+		// 启动时连接失败的从库，尝试重新连接
+		if db == nil {
+			newDB, err := initDB(DefaultConnection, r.config, loc, r.config.Timezone)
+			if err != nil {
+				l.setReplicaHealth(r, false, 0, err)
+				continue
+			}
+			l.mu.Lock()
+			r.db = newDB
+			l.refreshSlaveDBs()
+			l.mu.Unlock()
+			db = newDB
+			logs.Info().Str("host", r.host).Msg("slave database connected")
+		}
+
+		lag, err := probeReplica(db, r.config.Driver, maxLag)
+		if err == nil && maxLag > 0 && lag > maxLag {
+			err = errors.New("replication lag " + lag.String() + " exceeds limit")
+		}
+		l.setReplicaHealth(r, err == nil, lag, err)
+	}
+}
+
+func (l *LightDatabase) setReplicaHealth(r *replica, healthy bool, lag time.Duration, err error) {
+	l.mu.Lock()
+	changed := r.healthy != healthy
+	r.healthy = healthy
+	r.lag = lag
+	r.err = err
+	if changed {
+		r.current = 0
+	}
+	l.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if healthy {
+		logs.Info().Str("host", r.host).Dur("lag", lag).Msg("slave database recovered, added back to rotation")
+	} else {
+		logs.Warn().Err(err).Str("host", r.host).Msg("slave database unhealthy, removed from rotation")
+	}
+}
+
+// probeReplica ping 从库，maxLag 大于 0 时读取复制延迟
+func probeReplica(db *gorm.DB, driver Driver, maxLag time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return 0, err
+	}
+	if maxLag <= 0 {
+		return 0, nil
+	}
+	return replicationLag(ctx, driver, sqlDB)
+}
+
+// mysqlReplicationLag 通过 SHOW REPLICA STATUS 读取复制延迟，兼容旧版本的 SHOW SLAVE STATUS
+func mysqlReplicationLag(ctx context.Context, sqlDB *sql.DB) (time.Duration, error) {
+	rows, err := sqlDB.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = sqlDB.QueryContext(ctx, "SHOW SLAVE STATUS")
+		if err != nil {
+			return 0, err
+		}
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	// 不是从库（没有复制状态），视为无延迟
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		// NULL 表示复制线程已停止
+		if values[i] == nil {
+			return 0, errors.New("replication is not running")
+		}
+		seconds, err := strconv.Atoi(string(values[i]))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, nil
+}
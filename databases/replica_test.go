@@ -0,0 +1,128 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var errReplicaDown = errors.New("replica down")
+
+// fakeConnector 只支持 Ping 的驱动，down 为 true 时 Ping 失败
+type fakeConnector struct {
+	down atomic.Bool
+}
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.down.Load() {
+		return nil, errReplicaDown
+	}
+	return &fakeConn{connector: c}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver { return nil }
+
+type fakeConn struct {
+	connector *fakeConnector
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, errReplicaDown }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errReplicaDown }
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	if c.connector.down.Load() {
+		return errReplicaDown
+	}
+	return nil
+}
+
+func openReplica(t *testing.T, host string, weight int) (*replica, *fakeConnector, *sql.DB) {
+	t.Helper()
+	connector := &fakeConnector{}
+	sqlDB := sql.OpenDB(connector)
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &replica{host: host, config: &DatabaseConfig{}, weight: weight, db: db, healthy: true}, connector, sqlDB
+}
+
+func TestReplicaBalancing(t *testing.T) {
+	t.Run("Test Round Robin Is Smooth And Weighted", func(t *testing.T) {
+		a, b, c := &replica{host: "a", weight: 5}, &replica{host: "b", weight: 1}, &replica{host: "c", weight: 1}
+		var got string
+		for i := 0; i < 7; i++ {
+			got += pickRoundRobin([]*replica{a, b, c}).host
+		}
+		if got != "aabacaa" {
+			t.Errorf("got %s, expected aabacaa", got)
+		}
+	})
+
+	t.Run("Test Random Respects Weights", func(t *testing.T) {
+		a, b := &replica{host: "a", weight: 3}, &replica{host: "b", weight: 1}
+		counts := map[string]int{}
+		for i := 0; i < 4000; i++ {
+			counts[pickRandom([]*replica{a, b}).host]++
+		}
+		if counts["a"] < 2700 || counts["a"] > 3300 {
+			t.Errorf("weight 3 replica picked %d of 4000 times", counts["a"])
+		}
+	})
+
+	t.Run("Test Least Conn", func(t *testing.T) {
+		a, _, aDB := openReplica(t, "a", 1)
+		b, _, _ := openReplica(t, "b", 1)
+		cfg := &DatabaseConfig{SlavePolicy: string(SlavePolicyLeastConn)}
+		l := &LightDatabase{MainDB: openFake(t, &fakePool{}), Completed: true, config: cfg, replicas: []*replica{a, b}}
+		l.refreshSlaveDBs()
+
+		conn, err := aDB.Conn(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		for i := 0; i < 3; i++ {
+			if l.pickSlave() != b.db {
+				t.Fatal("replica with fewer connections in use should be picked")
+			}
+		}
+	})
+
+	t.Run("Test Health Eviction And Recovery", func(t *testing.T) {
+		r, connector, _ := openReplica(t, "a", 1)
+		main := openFake(t, &fakePool{})
+		cfg := &DatabaseConfig{SlavePolicy: string(SlavePolicyRandom)}
+		l := &LightDatabase{MainDB: main, Completed: true, config: cfg, replicas: []*replica{r}}
+		l.refreshSlaveDBs()
+
+		connector.down.Store(true)
+		l.checkReplicas(nil)
+		if l.pickSlave() != main {
+			t.Error("unhealthy replica should fall back to the primary")
+		}
+		if status := l.ReplicaStatus(); status[0].Healthy || status[0].Error == "" {
+			t.Errorf("unexpected status %+v", status[0])
+		}
+
+		connector.down.Store(false)
+		l.checkReplicas(nil)
+		if l.pickSlave() != r.db {
+			t.Error("recovered replica should be added back")
+		}
+		if status := l.ReplicaStatus(); !status[0].Healthy {
+			t.Errorf("unexpected status %+v", status[0])
+		}
+	})
+}
@@ -0,0 +1,359 @@
+package databases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/light-speak/lighthouse/lighterr"
+	"github.com/light-speak/lighthouse/routers/with"
+	"github.com/light-speak/lighthouse/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	defaultPageSize    = 15
+	defaultMaxPageSize = 100
+)
+
+// Sort 排序条件，Field 需要在 Repository 的排序白名单中
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Query 查询条件
+type Query struct {
+	Filter *Filter
+	Sort   []Sort // 为空时使用 Repository 的默认排序
+	// Preload 始终预加载的关联，GraphQL 选择集中的关联会自动预加载，不需要写在这里
+	Preload []string
+	Scopes  []func(*gorm.DB) *gorm.DB
+}
+
+// Repository 基于 gorm 的通用查询层，负责过滤、排序、分页以及按 GraphQL 选择集预加载关联
+// 读操作通过 DB(ctx) 路由到从库，UsePrimary、写后粘滞以及 WithTx 事务中的读取使用主库或当前事务
+type Repository[T any] struct {
+	options repositoryOptions
+
+	once    sync.Once
+	schema  *schema.Schema
+	sorts   map[string]bool
+	filters map[string]bool
+	err     error
+}
+
+type repositoryOptions struct {
+	connection  string
+	perTenant   bool
+	sortFields  []string
+	filterField []string
+	defaultSort []Sort
+	maxPageSize int
+}
+
+// RepositoryOption Repository 配置项
+type RepositoryOption func(o *repositoryOptions)
+
+// OnConnection 使用命名连接，默认使用 default 连接
+func OnConnection(name string) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.connection = name
+	}
+}
+
+// OnTenantConnection 使用 ctx 中租户对应的命名连接，见 TenantConnection
+func OnTenantConnection() RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.perTenant = true
+	}
+}
+
+// WithSortFields 允许排序的字段，主键始终可以排序
+func WithSortFields(fields ...string) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.sortFields = append(o.sortFields, fields...)
+	}
+}
+
+// WithFilterFields 允许过滤的字段，主键始终可以过滤
+func WithFilterFields(fields ...string) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.filterField = append(o.filterField, fields...)
+	}
+}
+
+// WithDefaultSort 未指定排序时使用的排序，默认按主键升序，其中的字段会自动加入排序白名单
+func WithDefaultSort(sorts ...Sort) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.defaultSort = sorts
+	}
+}
+
+// WithMaxPageSize 每页最大条数，默认 100
+func WithMaxPageSize(size int) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.maxPageSize = size
+	}
+}
+
+// NewRepository 创建 Repository，T 为 gorm 模型
+func NewRepository[T any](opts ...RepositoryOption) *Repository[T] {
+	r := &Repository[T]{
+		options: repositoryOptions{
+			connection:  DefaultConnection,
+			maxPageSize: defaultMaxPageSize,
+		},
+	}
+	for _, opt := range opts {
+		opt(&r.options)
+	}
+	return r
+}
+
+// Find 查询所有符合条件的记录
+func (r *Repository[T]) Find(ctx context.Context, q *Query) ([]*T, error) {
+	db, sorts, err := r.prepare(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*T, 0)
+	db = r.order(db, sorts)
+	if err := r.preload(ctx, db, q, false).Find(&items).Error; err != nil {
+		return nil, lighterr.NewDatabaseError("查询失败", err)
+	}
+	return items, nil
+}
+
+// First 查询第一条符合条件的记录，没有记录时返回 NotFound 错误
+func (r *Repository[T]) First(ctx context.Context, q *Query) (*T, error) {
+	db, sorts, err := r.prepare(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	item := new(T)
+	db = r.order(db, sorts)
+	if err := r.preload(ctx, db, q, false).Limit(1).Take(item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, lighterr.NewNotFoundError("记录不存在", err)
+		}
+		return nil, lighterr.NewDatabaseError("查询失败", err)
+	}
+	return item, nil
+}
+
+// prepare 获取连接并应用 Scopes 和过滤条件，返回可复用的查询与解析后的排序
+func (r *Repository[T]) prepare(ctx context.Context, q *Query) (*gorm.DB, []resolvedSort, error) {
+	if q == nil {
+		q = &Query{}
+	}
+
+	var conn *LightDatabase
+	var err error
+	if r.options.perTenant {
+		conn, err = TenantConnection(ctx)
+	} else {
+		conn, err = Connection(r.options.connection)
+	}
+	if err != nil {
+		return nil, nil, lighterr.NewDatabaseError("数据库连接失败", err)
+	}
+	db, err := conn.DB(ctx)
+	if err != nil {
+		return nil, nil, lighterr.NewDatabaseError("数据库连接失败", err)
+	}
+	if err := r.parse(db); err != nil {
+		return nil, nil, lighterr.NewInternalError("模型解析失败", err)
+	}
+
+	db = db.Model(new(T)).Scopes(q.Scopes...)
+	expr, err := q.Filter.build(func(field string) (string, error) {
+		return r.column(field, r.filters)
+	})
+	if err != nil {
+		return nil, nil, lighterr.NewInvalidInputError(err.Error(), err)
+	}
+	if expr != nil {
+		db = db.Where(expr)
+	}
+
+	sorts, err := r.resolveSorts(q.Sort)
+	if err != nil {
+		return nil, nil, lighterr.NewInvalidInputError(err.Error(), err)
+	}
+	return db.Session(&gorm.Session{}), sorts, nil
+}
+
+// parse 解析模型结构并把白名单转换为列名，只在第一次查询时执行
+func (r *Repository[T]) parse(db *gorm.DB) error {
+	r.once.Do(func() {
+		stmt := &gorm.Statement{DB: db}
+		if r.err = stmt.Parse(new(T)); r.err != nil {
+			return
+		}
+		r.schema = stmt.Schema
+
+		whitelist := func(fields []string) map[string]bool {
+			columns := make(map[string]bool, len(fields)+1)
+			for _, f := range r.schema.PrimaryFields {
+				columns[f.DBName] = true
+			}
+			for _, name := range fields {
+				field := r.lookupField(name)
+				if field == nil {
+					r.err = errors.Join(r.err, fmt.Errorf("model %s has no field %s", r.schema.Name, name))
+					continue
+				}
+				columns[field.DBName] = true
+			}
+			return columns
+		}
+		// 默认排序的字段无需再加入白名单
+		sortFields := append([]string(nil), r.options.sortFields...)
+		for _, sort := range r.options.defaultSort {
+			sortFields = append(sortFields, sort.Field)
+		}
+		r.sorts = whitelist(sortFields)
+		r.filters = whitelist(r.options.filterField)
+	})
+	return r.err
+}
+
+// lookupField 按 Go 字段名、列名、GraphQL 字段名查找模型字段
+func (r *Repository[T]) lookupField(name string) *schema.Field {
+	if field := r.schema.LookUpField(name); field != nil {
+		return field
+	}
+	if field := r.schema.LookUpField(utils.SnakeCase(name)); field != nil {
+		return field
+	}
+	for _, field := range r.schema.Fields {
+		if strings.EqualFold(field.Name, name) {
+			return field
+		}
+	}
+	return nil
+}
+
+// column 返回字段对应的列名，不在白名单中时返回错误
+func (r *Repository[T]) column(name string, allowed map[string]bool) (string, error) {
+	field := r.lookupField(name)
+	if field == nil || field.DBName == "" || !allowed[field.DBName] {
+		return "", fmt.Errorf("field %s is not allowed", name)
+	}
+	return field.DBName, nil
+}
+
+type resolvedSort struct {
+	field *schema.Field
+	desc  bool
+}
+
+// resolveSorts 解析排序条件，并追加主键保证排序稳定（游标分页依赖这一点）
+func (r *Repository[T]) resolveSorts(sorts []Sort) ([]resolvedSort, error) {
+	if len(sorts) == 0 {
+		sorts = r.options.defaultSort
+	}
+
+	resolved := make([]resolvedSort, 0, len(sorts)+1)
+	seen := make(map[string]bool, len(sorts)+1)
+	for _, s := range sorts {
+		column, err := r.column(s.Field, r.sorts)
+		if err != nil {
+			return nil, err
+		}
+		if seen[column] {
+			continue
+		}
+		seen[column] = true
+		resolved = append(resolved, resolvedSort{field: r.schema.LookUpField(column), desc: s.Desc})
+	}
+	for _, field := range r.schema.PrimaryFields {
+		if !seen[field.DBName] {
+			resolved = append(resolved, resolvedSort{field: field})
+		}
+	}
+	return resolved, nil
+}
+
+func (r *Repository[T]) order(db *gorm.DB, sorts []resolvedSort) *gorm.DB {
+	for _, s := range sorts {
+		db = db.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: s.field.DBName},
+			Desc:   s.desc,
+		})
+	}
+	return db
+}
+
+// preload 预加载 Query.Preload 中的关联，以及 GraphQL 选择集中选中的关联
+// paged 为 true 时从分页结果的列表字段（Connection 的 edges.node 或 data）下读取选择集
+func (r *Repository[T]) preload(ctx context.Context, db *gorm.DB, q *Query, paged bool) *gorm.DB {
+	seen := make(map[string]bool)
+	if q != nil {
+		for _, name := range q.Preload {
+			if !seen[name] {
+				seen[name] = true
+				db = db.Preload(name)
+			}
+		}
+	}
+
+	fields := selection(ctx)
+	if fields != nil && paged {
+		if edges, ok := fields.Fields["edges"]; ok {
+			fields = edges.Fields["node"]
+		} else {
+			fields = fields.Fields[PageDataField]
+		}
+	}
+	for _, name := range relationPaths(fields, r.schema, "") {
+		if !seen[name] {
+			seen[name] = true
+			db = db.Preload(name)
+		}
+	}
+	return db
+}
+
+// selection 返回当前 resolver 的选择集，不在 GraphQL 请求中时返回 nil
+func selection(ctx context.Context) *with.Fields {
+	if !graphql.HasOperationContext(ctx) || graphql.GetFieldContext(ctx) == nil {
+		return nil
+	}
+	return with.With(ctx)
+}
+
+// relationPaths 将选择集中的字段匹配为模型关联，返回 gorm Preload 使用的路径，如 Posts.Comments
+func relationPaths(fields *with.Fields, s *schema.Schema, prefix string) []string {
+	if fields == nil || s == nil {
+		return nil
+	}
+
+	paths := make([]string, 0)
+	for name, sub := range fields.Fields {
+		rel := s.Relationships.Relations[utils.UcFirst(name)]
+		if rel == nil {
+			for relName, candidate := range s.Relationships.Relations {
+				if strings.EqualFold(relName, name) {
+					rel = candidate
+					break
+				}
+			}
+		}
+		if rel == nil {
+			continue
+		}
+		path := prefix + rel.Name
+		paths = append(paths, path)
+		paths = append(paths, relationPaths(sub, rel.FieldSchema, path+".")...)
+	}
+	return paths
+}
@@ -0,0 +1,160 @@
+package databases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/light-speak/lighthouse/lighterr"
+	"github.com/light-speak/lighthouse/routers/with"
+)
+
+type repoUser struct {
+	ID    uint `gorm:"primaryKey"`
+	Name  string
+	Age   int
+	Email string
+	Posts []*repoPost `gorm:"foreignKey:UserID"`
+}
+
+type repoPost struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint
+	Title  string
+}
+
+func setupRepository(t *testing.T) *Repository[repoUser] {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Driver = DriverSQLite
+	cfg.Name = ":memory:"
+	cfg.normalize()
+	if err := Init(cfg); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	t.Cleanup(LightDatabaseClient.CloseConnections)
+
+	db, _ := LightDatabaseClient.GetDB(context.Background())
+	if err := db.AutoMigrate(&repoUser{}, &repoPost{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	users := []*repoUser{
+		{Name: "alice", Age: 30, Email: "alice@example.com"},
+		{Name: "bob", Age: 25, Email: "bob@example.com"},
+		{Name: "carol", Age: 30, Email: "carol_100%@example.com"},
+		{Name: "dave", Age: 41, Email: "dave@example.com"},
+		{Name: "erin", Age: 19, Email: "erin@example.com"},
+	}
+	if err := db.Create(users).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return NewRepository[repoUser](
+		WithSortFields("age", "name"),
+		WithFilterFields("name", "age", "email"),
+	)
+}
+
+func names(users []*repoUser) []string {
+	result := make([]string, len(users))
+	for i, u := range users {
+		result[i] = u.Name
+	}
+	return result
+}
+
+func TestRepositoryFind(t *testing.T) {
+	repo := setupRepository(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		query    *Query
+		expected []string
+	}{
+		{"Test Find All", nil, []string{"alice", "bob", "carol", "dave", "erin"}},
+		{"Test Find Eq", &Query{Filter: Where("age", OpEq, 30)}, []string{"alice", "carol"}},
+		{"Test Find In", &Query{Filter: Where("name", OpIn, []string{"bob", "erin"})}, []string{"bob", "erin"}},
+		{"Test Find Like Escaped", &Query{Filter: Where("email", OpLike, "_100%")}, []string{"carol"}},
+		{"Test Find Between", &Query{Filter: Where("age", OpBetween, []int{25, 30})}, []string{"alice", "bob", "carol"}},
+		{"Test Find Or", &Query{Filter: Or(Where("age", OpLt, 20), Where("age", OpGt, 40))}, []string{"dave", "erin"}},
+		{"Test Find Sort", &Query{Sort: []Sort{{Field: "age", Desc: true}, {Field: "name"}}}, []string{"dave", "alice", "carol", "bob", "erin"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users, err := repo.Find(ctx, test.query)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			result := names(users)
+			if len(result) != len(test.expected) {
+				t.Fatalf("Find() = %v; expected %v", result, test.expected)
+			}
+			for i := range result {
+				if result[i] != test.expected[i] {
+					t.Fatalf("Find() = %v; expected %v", result, test.expected)
+				}
+			}
+		})
+	}
+
+	t.Run("Test Find Rejects Fields Outside Whitelist", func(t *testing.T) {
+		_, err := repo.Find(ctx, &Query{Sort: []Sort{{Field: "email"}}})
+		var gqlErr *lighterr.GraphQLError
+		if !errors.As(err, &gqlErr) || gqlErr.Code != lighterr.ErrorCodeInvalidInput {
+			t.Errorf("Find() error = %v; expected invalid input", err)
+		}
+	})
+
+	t.Run("Test Relation Paths From Selection", func(t *testing.T) {
+		fields := &with.Fields{Fields: map[string]*with.Fields{
+			"name":  {Field: "name"},
+			"posts": {Field: "posts", Fields: map[string]*with.Fields{"title": {Field: "title"}}},
+		}}
+		paths := relationPaths(fields, repo.schema, "")
+		if len(paths) != 1 || paths[0] != "Posts" {
+			t.Errorf("relationPaths() = %v; expected [Posts]", paths)
+		}
+	})
+}
+
+func TestRepositoryPaginate(t *testing.T) {
+	repo := setupRepository(t)
+	ctx := context.Background()
+	query := &Query{Sort: []Sort{{Field: "age", Desc: true}}}
+
+	t.Run("Test Paginate Offset", func(t *testing.T) {
+		page, err := repo.Paginate(ctx, query, Pagination{Page: 2, Size: 2})
+		if err != nil {
+			t.Fatalf("Paginate() error = %v", err)
+		}
+		if page.Total != 5 || !page.HasNextPage || len(page.Items) != 2 || page.Items[0].Name != "carol" {
+			t.Errorf("Paginate() = %+v, items %v", page, names(page.Items))
+		}
+	})
+
+	t.Run("Test Paginate Cursor", func(t *testing.T) {
+		cursor := ""
+		result := make([]string, 0)
+		for i := 0; i < 5; i++ {
+			page, err := repo.Paginate(ctx, query, Pagination{Size: 2, Cursor: &cursor})
+			if err != nil {
+				t.Fatalf("Paginate() error = %v", err)
+			}
+			result = append(result, names(page.Items)...)
+			if !page.HasNextPage {
+				break
+			}
+			cursor = page.EndCursor
+		}
+		expected := []string{"dave", "alice", "carol", "bob", "erin"}
+		if len(result) != len(expected) {
+			t.Fatalf("Paginate() = %v; expected %v", result, expected)
+		}
+		for i := range result {
+			if result[i] != expected[i] {
+				t.Fatalf("Paginate() = %v; expected %v", result, expected)
+			}
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package databases
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 读写分离
+// DB(ctx) 返回的连接会通过 GORM callback 自动把 SELECT 路由到从库，
+// 写操作、事务内查询、加锁查询以及粘滞窗口内的读操作都留在主库
+
+type routeContextKey struct{}
+type primaryContextKey struct{}
+type stickyContextKey struct{}
+
+// DB 获取自动读写分离的数据库连接
+func (l *LightDatabase) DB(ctx context.Context) (*gorm.DB, error) {
+	db, err := l.GetDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return db.WithContext(context.WithValue(ctx, routeContextKey{}, true)), nil
+}
+
+// UsePrimary 强制 ctx 下的所有查询走主库
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// WithSticky 为 ctx 开启一个粘滞作用域
+// 作用域内一旦发生写操作，后续的读操作都会在主库执行
+func WithSticky(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(stickyContextKey{}).(*stickyState); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, stickyContextKey{}, &stickyState{})
+}
+
+// StickyMiddleware 为每个请求开启粘滞作用域
+// keyFunc 返回非空值时（例如 Session ID 或用户 ID），同一个 key 的请求共享粘滞状态，
+// 写操作后的 DB_STICKY_WINDOW 秒内都会读主库；keyFunc 为 nil 或返回空值时仅在当前请求内粘滞
+func StickyMiddleware(keyFunc func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state := &stickyState{}
+			if keyFunc != nil {
+				if key := keyFunc(r); key != "" {
+					state = sessionSticky(key, time.Duration(getConfig().StickyWindow)*time.Second)
+				}
+			}
+			ctx := context.WithValue(r.Context(), stickyContextKey{}, state)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// IsSticky 判断 ctx 当前是否处于粘滞状态
+func IsSticky(ctx context.Context) bool {
+	if state, ok := ctx.Value(stickyContextKey{}).(*stickyState); ok {
+		return state.sticky()
+	}
+	return false
+}
+
+type stickyState struct {
+	mu     sync.Mutex
+	window time.Duration // 为 0 时在整个作用域内粘滞
+	wrote  bool
+	until  time.Time
+}
+
+func (s *stickyState) markWrite() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wrote = true
+	s.until = time.Now().Add(s.window)
+}
+
+func (s *stickyState) sticky() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.wrote {
+		return false
+	}
+	if s.window <= 0 {
+		return true
+	}
+	return time.Now().Before(s.until)
+}
+
+func (s *stickyState) expired() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.wrote || time.Now().After(s.until)
+}
+
+var (
+	stickyMu       sync.Mutex
+	stickySessions = map[string]*stickyState{}
+	stickySweepAt  time.Time
+)
+
+// sessionSticky 获取会话级别的粘滞状态，顺便清理过期的会话
+func sessionSticky(key string, window time.Duration) *stickyState {
+	if window <= 0 {
+		return &stickyState{}
+	}
+
+	stickyMu.Lock()
+	defer stickyMu.Unlock()
+
+	now := time.Now()
+	if now.After(stickySweepAt) {
+		for k, s := range stickySessions {
+			if s.expired() {
+				delete(stickySessions, k)
+			}
+		}
+		stickySweepAt = now.Add(time.Minute)
+	}
+
+	state, ok := stickySessions[key]
+	if !ok {
+		state = &stickyState{window: window}
+		stickySessions[key] = state
+	}
+	return state
+}
+
+// registerResolver 在主库上注册读写分离与粘滞相关的 callback
+func (l *LightDatabase) registerResolver() error {
+	cb := l.MainDB.Callback()
+	if err := cb.Query().Before("gorm:query").Register("lighthouse:route_query", l.routeRead); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("lighthouse:route_row", l.routeRead); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("lighthouse:sticky_create", markWrite); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("lighthouse:sticky_update", markWrite); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("lighthouse:sticky_delete", markWrite); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("lighthouse:sticky_raw", markWrite)
+}
+
+// routeRead 把满足条件的读操作切换到从库连接池
+func (l *LightDatabase) routeRead(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Context == nil {
+		return
+	}
+	ctx := db.Statement.Context
+	if routed, _ := ctx.Value(routeContextKey{}).(bool); !routed {
+		return
+	}
+	if ctx.Value(primaryContextKey{}) != nil || IsSticky(ctx) {
+		return
+	}
+	// 事务内的查询必须留在事务连接上
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return
+	}
+	// SELECT ... FOR UPDATE 等加锁读
+	if _, ok := db.Statement.Clauses["FOR"]; ok {
+		return
+	}
+	// Raw 语句只路由只读 SQL
+	if db.Statement.SQL.Len() > 0 && !isReadSQL(db.Statement.SQL.String()) {
+		return
+	}
+
+	slave := l.pickSlave()
+	if slave == nil || slave == l.MainDB {
+		return
+	}
+	db.Statement.ConnPool = slave.Statement.ConnPool
+}
+
+// markWrite 在写操作成功后标记粘滞
+func markWrite(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Context == nil {
+		return
+	}
+	if state, ok := db.Statement.Context.Value(stickyContextKey{}).(*stickyState); ok {
+		state.markWrite()
+	}
+}
+
+func isReadSQL(sql string) bool {
+	sql = strings.ToLower(strings.TrimSpace(sql))
+	if !strings.HasPrefix(sql, "select") && !strings.HasPrefix(sql, "show") {
+		return false
+	}
+	return !strings.Contains(sql, "for update") && !strings.Contains(sql, "lock in share mode") && !strings.Contains(sql, "for share")
+}
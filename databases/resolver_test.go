@@ -0,0 +1,243 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+var errFakePool = errors.New("fake pool")
+
+// fakePool 只记录语句的连接池，用于在没有数据库的情况下测试路由和事务
+type fakePool struct {
+	mu         sync.Mutex
+	statements []string
+	commits    int
+	rollbacks  int
+}
+
+func (p *fakePool) record(query string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.statements = append(p.statements, query)
+}
+
+func (p *fakePool) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.statements)
+}
+
+func (p *fakePool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errFakePool
+}
+
+func (p *fakePool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	p.record(query)
+	return fakeResult{}, nil
+}
+
+func (p *fakePool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	p.record(query)
+	return nil, errFakePool
+}
+
+func (p *fakePool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	p.record(query)
+	return nil
+}
+
+func (p *fakePool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &fakeTx{fakePool: p}, nil
+}
+
+type fakeTx struct {
+	*fakePool
+}
+
+func (t *fakeTx) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.commits++
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollbacks++
+	return nil
+}
+
+type fakeResult struct{}
+
+func (fakeResult) LastInsertId() (int64, error) { return 1, nil }
+func (fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+func openFake(t *testing.T, pool *fakePool) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: pool, SkipInitializeWithVersion: true}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+type routeUser struct {
+	ID   uint
+	Name string
+}
+
+func TestReadWriteRouting(t *testing.T) {
+	main, slave := &fakePool{}, &fakePool{}
+	l := &LightDatabase{MainDB: openFake(t, main), Completed: true}
+	l.SlaveDBs = []*gorm.DB{openFake(t, slave)}
+	if err := l.registerResolver(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		run     func(ctx context.Context, db *gorm.DB)
+		toSlave bool
+	}{
+		{"Test Read Goes To Slave", func(ctx context.Context, db *gorm.DB) {
+			db.Find(&[]routeUser{})
+		}, true},
+		{"Test Raw Select Goes To Slave", func(ctx context.Context, db *gorm.DB) {
+			db.Raw("SELECT * FROM route_users").Scan(&[]routeUser{})
+		}, true},
+		{"Test Raw Lock Stays On Primary", func(ctx context.Context, db *gorm.DB) {
+			db.Raw("SELECT * FROM route_users FOR UPDATE").Scan(&[]routeUser{})
+		}, false},
+		{"Test Locking Read Stays On Primary", func(ctx context.Context, db *gorm.DB) {
+			db.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&[]routeUser{})
+		}, false},
+		{"Test UsePrimary", func(ctx context.Context, db *gorm.DB) {
+			db.WithContext(UsePrimary(ctx)).Find(&[]routeUser{})
+		}, false},
+		{"Test Transaction Stays On Primary", func(ctx context.Context, db *gorm.DB) {
+			_ = db.Transaction(func(tx *gorm.DB) error {
+				tx.Find(&[]routeUser{})
+				return nil
+			})
+		}, false},
+		{"Test Sticky After Write", func(ctx context.Context, db *gorm.DB) {
+			ctx = WithSticky(ctx)
+			db = db.WithContext(ctx)
+			db.Create(&routeUser{Name: "a"})
+			db.Find(&[]routeUser{})
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := l.DB(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := slave.count()
+			test.run(context.Background(), db)
+			if toSlave := slave.count() > before; toSlave != test.toSlave {
+				t.Errorf("read on slave = %v, expected %v", toSlave, test.toSlave)
+			}
+		})
+	}
+
+	t.Run("Test GetDB Is Not Routed", func(t *testing.T) {
+		db, _ := l.GetDB(context.Background())
+		before := slave.count()
+		db.Find(&[]routeUser{})
+		if slave.count() != before {
+			t.Error("GetDB should always use the primary")
+		}
+	})
+
+	t.Run("Test Session Sticky Middleware", func(t *testing.T) {
+		cfg := getConfig()
+		window := cfg.StickyWindow
+		cfg.StickyWindow = 5
+		defer func() { cfg.StickyWindow = window }()
+
+		var toSlave bool
+		handler := StickyMiddleware(func(r *http.Request) string { return r.Header.Get("X-Session") })(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				db, _ := l.DB(r.Context())
+				if r.Method == http.MethodPost {
+					db.Create(&routeUser{Name: "b"})
+					return
+				}
+				before := slave.count()
+				db.Find(&[]routeUser{})
+				toSlave = slave.count() > before
+			}))
+		for _, method := range []string{http.MethodPost, http.MethodGet} {
+			r := httptest.NewRequest(method, "/", nil)
+			r.Header.Set("X-Session", "s1")
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+		}
+		if toSlave {
+			t.Error("read after write in the same session should use the primary")
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Session", "s2")
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		if !toSlave {
+			t.Error("read in another session should use the slave")
+		}
+	})
+}
+
+func TestRepositoryRouting(t *testing.T) {
+	main, slave := &fakePool{}, &fakePool{}
+	l := &LightDatabase{MainDB: openFake(t, main), Completed: true}
+	l.SlaveDBs = []*gorm.DB{openFake(t, slave)}
+	if err := l.registerResolver(); err != nil {
+		t.Fatal(err)
+	}
+	l.ready.Store(true)
+	client := LightDatabaseClient
+	LightDatabaseClient = l
+	defer func() { LightDatabaseClient = client }()
+
+	repo := NewRepository[routeUser]()
+	tests := []struct {
+		name    string
+		ctx     func() context.Context
+		toSlave bool
+	}{
+		{"Test Read Goes To Slave", context.Background, true},
+		{"Test UsePrimary", func() context.Context { return UsePrimary(context.Background()) }, false},
+		{"Test Sticky After Write", func() context.Context {
+			ctx := WithSticky(context.Background())
+			db, _ := l.DB(ctx)
+			db.Create(&routeUser{Name: "a"})
+			return ctx
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := test.ctx()
+			before, mainBefore := slave.count(), main.count()
+			_, _ = repo.Find(ctx, nil)
+			if toSlave := slave.count() > before; toSlave != test.toSlave {
+				t.Errorf("read on slave = %v, expected %v", toSlave, test.toSlave)
+			}
+			if !test.toSlave && main.count() == mainBefore {
+				t.Error("read should go to the primary")
+			}
+		})
+	}
+}
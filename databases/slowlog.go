@@ -0,0 +1,175 @@
+package databases
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/light-speak/lighthouse/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultSlowThreshold 未配置 DB_SLOW_THRESHOLD 时的慢查询阈值
+const defaultSlowThreshold = 200 * time.Millisecond
+
+// otherFingerprint 不在慢查询记录中的查询在耗时指标中的 fingerprint 标签
+const otherFingerprint = "other"
+
+// SlowQuery 一条慢查询记录，只保存指纹，不保存含参数的原始 SQL
+type SlowQuery struct {
+	Fingerprint      string        `json:"fingerprint"`
+	Operation        string        `json:"operation"`
+	GraphQLOperation string        `json:"graphqlOperation,omitempty"`
+	GraphQLField     string        `json:"graphqlField,omitempty"`
+	Duration         time.Duration `json:"duration"`
+	Rows             int64         `json:"rows"`
+	Time             time.Time     `json:"time"`
+}
+
+// slowLog 固定容量的环形缓冲区，写满后覆盖最早的记录
+type slowLog struct {
+	mu      sync.Mutex
+	entries []SlowQuery
+	next    int
+	full    bool
+	// counts 每个指纹在环中的记录数，只有其中的指纹会作为查询耗时指标的 fingerprint 标签
+	counts  map[string]int
+	tracked atomic.Int32
+}
+
+var slowQueries = newSlowLog(100)
+
+func newSlowLog(size int) *slowLog {
+	if size <= 0 {
+		size = 100
+	}
+	return &slowLog{entries: make([]SlowQuery, size), counts: map[string]int{}}
+}
+
+func (s *slowLog) add(q SlowQuery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.full {
+		s.release(s.entries[s.next].Fingerprint)
+	}
+	s.entries[s.next] = q
+	s.counts[q.Fingerprint]++
+	s.tracked.Store(int32(len(s.counts)))
+	s.next = (s.next + 1) % len(s.entries)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// release 指纹的最后一条记录被覆盖时删除它的耗时指标，标签数量不超过环的容量，调用方需持有 mu
+func (s *slowLog) release(fingerprint string) {
+	s.counts[fingerprint]--
+	if s.counts[fingerprint] > 0 {
+		return
+	}
+	delete(s.counts, fingerprint)
+	s.tracked.Store(int32(len(s.counts)))
+	metrics.DBQueryDuration.DeletePartialMatch(prometheus.Labels{"fingerprint": fingerprint})
+}
+
+// fingerprintLabel 返回查询耗时指标的 fingerprint 标签：指纹在慢查询记录中时为指纹，否则为 other
+// 没有慢查询记录时不计算指纹
+func (s *slowLog) fingerprintLabel(sql string) string {
+	if s.tracked.Load() == 0 {
+		return otherFingerprint
+	}
+	fingerprint := Fingerprint(sql)
+	s.mu.Lock()
+	_, ok := s.counts[fingerprint]
+	s.mu.Unlock()
+	if !ok {
+		return otherFingerprint
+	}
+	return fingerprint
+}
+
+// resize 调整容量，保留最近的记录
+func (s *slowLog) resize(size int) {
+	if size <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if size == len(s.entries) {
+		return
+	}
+	recent := s.snapshot()
+	if len(recent) > size {
+		for _, q := range recent[:len(recent)-size] {
+			s.release(q.Fingerprint)
+		}
+		recent = recent[len(recent)-size:]
+	}
+	s.entries = make([]SlowQuery, size)
+	copy(s.entries, recent)
+	s.next = len(recent) % size
+	s.full = len(recent) == size
+}
+
+// snapshot 按时间顺序返回所有记录，调用方需持有 mu
+func (s *slowLog) snapshot() []SlowQuery {
+	if !s.full {
+		return append([]SlowQuery(nil), s.entries[:s.next]...)
+	}
+	result := make([]SlowQuery, 0, len(s.entries))
+	result = append(result, s.entries[s.next:]...)
+	return append(result, s.entries[:s.next]...)
+}
+
+func (s *slowLog) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for fingerprint := range s.counts {
+		metrics.DBQueryDuration.DeletePartialMatch(prometheus.Labels{"fingerprint": fingerprint})
+	}
+	clear(s.counts)
+	s.tracked.Store(0)
+	clear(s.entries)
+	s.next = 0
+	s.full = false
+}
+
+// SlowQueries 返回最近的慢查询，按耗时从高到低排序，limit <= 0 时返回全部
+func SlowQueries(limit int) []SlowQuery {
+	slowQueries.mu.Lock()
+	result := slowQueries.snapshot()
+	slowQueries.mu.Unlock()
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Duration > result[j].Duration })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// ResetSlowQueries 清空慢查询记录
+func ResetSlowQueries() {
+	slowQueries.reset()
+}
+
+// graphqlOrigin 返回发起查询的 GraphQL 操作与字段，查询需通过 GetDB(ctx) 或 WithContext 传入请求的 ctx
+func graphqlOrigin(ctx context.Context) (operation, field string) {
+	if ctx == nil {
+		return "", ""
+	}
+	if graphql.HasOperationContext(ctx) {
+		if oc := graphql.GetOperationContext(ctx); oc != nil {
+			operation = oc.OperationName
+			if operation == "" && oc.Operation != nil {
+				operation = string(oc.Operation.Operation)
+			}
+		}
+	}
+	if fc := graphql.GetFieldContext(ctx); fc != nil && fc.Field.Field != nil {
+		field = fc.Object + "." + fc.Field.Name
+	}
+	return operation, field
+}
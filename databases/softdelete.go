@@ -0,0 +1,160 @@
+package databases
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/light-speak/lighthouse/lighterr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// TrashedMode 软删除记录的查询方式
+type TrashedMode int
+
+const (
+	WithoutTrashed TrashedMode = iota // 排除已删除的记录，gorm 的默认行为
+	WithTrashed                       // 包含已删除的记录
+	OnlyTrashed                       // 只查询已删除的记录
+)
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// Trashed 按 mode 处理软删除记录的 gorm scope，可以放到 Query.Scopes 中
+func Trashed(mode TrashedMode) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch mode {
+		case WithTrashed:
+			return db.Unscoped()
+		case OnlyTrashed:
+			stmt := db.Statement
+			if stmt.Schema == nil && stmt.Model != nil {
+				if err := stmt.Parse(stmt.Model); err != nil {
+					_ = db.AddError(err)
+					return db
+				}
+			}
+			field := softDeleteField(stmt.Schema)
+			if field == nil {
+				_ = db.AddError(fmt.Errorf("model %s does not support soft deletes", modelName(stmt.Schema)))
+				return db
+			}
+			return db.Unscoped().Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: nil})
+		default:
+			return db
+		}
+	}
+}
+
+// TrashedFromContext 读取当前字段的 withTrashed / onlyTrashed 参数（由 @softDeletes 生成），onlyTrashed 优先
+// 不在 GraphQL 请求中或没有这两个参数时返回 WithoutTrashed
+func TrashedFromContext(ctx context.Context) TrashedMode {
+	if !graphql.HasOperationContext(ctx) {
+		return WithoutTrashed
+	}
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil {
+		return WithoutTrashed
+	}
+	if boolValue(fc.Args["onlyTrashed"]) {
+		return OnlyTrashed
+	}
+	if boolValue(fc.Args["withTrashed"]) {
+		return WithTrashed
+	}
+	return WithoutTrashed
+}
+
+// Restore 恢复软删除的记录并返回恢复后的记录，记录不存在或未被删除时返回 NotFound 错误
+func Restore[T any](db *gorm.DB, id any) (*T, error) {
+	s, field, err := softDeleteSchema[T](db)
+	if err != nil {
+		return nil, err
+	}
+	pk := s.PrioritizedPrimaryField
+	result := db.Unscoped().Model(new(T)).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: id}).
+		Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: nil}).
+		Update(field.DBName, nil)
+	if result.Error != nil {
+		return nil, lighterr.NewDatabaseError("恢复失败", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, lighterr.NewNotFoundError("记录不存在或未被删除")
+	}
+
+	item := new(T)
+	if err := db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: id}).Take(item).Error; err != nil {
+		return nil, lighterr.NewDatabaseError("查询失败", err)
+	}
+	return item, nil
+}
+
+// ForceDelete 永久删除记录（包括已软删除的记录），记录不存在时返回 NotFound 错误
+func ForceDelete[T any](db *gorm.DB, id any) error {
+	s, _, err := softDeleteSchema[T](db)
+	if err != nil {
+		return err
+	}
+	result := db.Unscoped().
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: s.PrioritizedPrimaryField.DBName}, Value: id}).
+		Delete(new(T))
+	if result.Error != nil {
+		return lighterr.NewDatabaseError("删除失败", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return lighterr.NewNotFoundError("记录不存在")
+	}
+	return nil
+}
+
+// softDeleteSchema 解析模型，模型需要有单一主键和 gorm.DeletedAt 字段
+func softDeleteSchema[T any](db *gorm.DB) (*schema.Schema, *schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, nil, lighterr.NewInternalError("模型解析失败", err)
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, nil, lighterr.NewInternalError(fmt.Sprintf("模型 %s 没有主键", stmt.Schema.Name))
+	}
+	field := softDeleteField(stmt.Schema)
+	if field == nil {
+		return nil, nil, lighterr.NewInternalError(fmt.Sprintf("模型 %s 不支持软删除", stmt.Schema.Name))
+	}
+	return stmt.Schema, field, nil
+}
+
+// softDeleteField 查找模型的 gorm.DeletedAt 字段
+func softDeleteField(s *schema.Schema) *schema.Field {
+	if s == nil {
+		return nil
+	}
+	for _, f := range s.Fields {
+		if f.DBName != "" && f.IndirectFieldType == deletedAtType {
+			return f
+		}
+	}
+	return nil
+}
+
+func modelName(s *schema.Schema) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return s.Name
+}
+
+// boolValue 读取 GraphQL 参数中的布尔值，兼容指针
+func boolValue(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case *bool:
+		return b != nil && *b
+	default:
+		return false
+	}
+}
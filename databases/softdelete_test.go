@@ -0,0 +1,75 @@
+package databases
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type trashedPost struct {
+	ID        uint `gorm:"primaryKey"`
+	Title     string
+	DeletedAt *gorm.DeletedAt `gorm:"index"`
+}
+
+func TestSoftDeletes(t *testing.T) {
+	setupRepository(t)
+	db, _ := LightDatabaseClient.GetDB(context.Background())
+	if err := db.AutoMigrate(&trashedPost{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	posts := []*trashedPost{{Title: "a"}, {Title: "b"}, {Title: "c"}}
+	db.Create(posts)
+	db.Delete(posts[1])
+
+	repo := NewRepository[trashedPost]()
+	tests := []struct {
+		name     string
+		mode     TrashedMode
+		expected int
+	}{
+		{"Test Without Trashed", WithoutTrashed, 2},
+		{"Test With Trashed", WithTrashed, 3},
+		{"Test Only Trashed", OnlyTrashed, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, err := repo.Find(context.Background(), &Query{Scopes: []func(*gorm.DB) *gorm.DB{Trashed(test.mode)}})
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if len(items) != test.expected {
+				t.Errorf("len = %d; expected %d", len(items), test.expected)
+			}
+		})
+	}
+
+	t.Run("Test Restore", func(t *testing.T) {
+		item, err := Restore[trashedPost](db, posts[1].ID)
+		if err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		if item.Title != "b" || (item.DeletedAt != nil && item.DeletedAt.Valid) {
+			t.Errorf("restored = %+v", item)
+		}
+		if _, err := Restore[trashedPost](db, posts[1].ID); err == nil {
+			t.Errorf("Restore() on a live record should fail")
+		}
+	})
+
+	t.Run("Test Force Delete", func(t *testing.T) {
+		db.Delete(posts[0])
+		if err := ForceDelete[trashedPost](db, posts[0].ID); err != nil {
+			t.Fatalf("ForceDelete() error = %v", err)
+		}
+		var count int64
+		db.Unscoped().Model(&trashedPost{}).Count(&count)
+		if count != 2 {
+			t.Errorf("count = %d; expected 2", count)
+		}
+		if err := ForceDelete[trashedPost](db, posts[0].ID); err == nil {
+			t.Errorf("ForceDelete() on a missing record should fail")
+		}
+	})
+}
@@ -0,0 +1,317 @@
+package databases
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/light-speak/lighthouse/lighterr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// 多租户
+// 带有 tenant gorm tag 的字段（由 @tenant 指令生成，如 `gorm:"tenant;index"`）是模型的租户列，
+// 对这类模型的查询、更新、删除会自动追加 WHERE tenant_id = 当前租户，创建时自动填充租户列；
+// ctx 中没有租户时拒绝执行，跨租户的管理任务使用 WithoutTenant
+//
+// 隔离基于模型的 schema，Raw / Exec 执行的原生 SQL 没有模型，不会追加租户条件，也不会因缺少租户被拒绝，
+// 原生 SQL 访问租户表时需要自行通过 TenantFromContext 加上租户条件
+
+// TenantTag 租户字段的 gorm tag
+const TenantTag = "TENANT"
+
+// tenantConnectionPrefix 库隔离时租户对应的命名连接前缀，如 DB_CONN_TENANT_ACME_*
+const tenantConnectionPrefix = "tenant_"
+
+type tenantContextKey struct{}
+type withoutTenantContextKey struct{}
+
+// WithTenant 把租户 ID 保存到 ctx 中，只作用于通过模型执行的语句，Raw / Exec 不受影响
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext 获取 ctx 中的租户 ID
+func TenantFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// WithoutTenant 关闭 ctx 下的租户隔离，用于跨租户的管理任务、定时任务
+func WithoutTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutTenantContextKey{}, true)
+}
+
+// IsWithoutTenant 判断 ctx 是否关闭了租户隔离
+func IsWithoutTenant(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	without, _ := ctx.Value(withoutTenantContextKey{}).(bool)
+	return without
+}
+
+var (
+	tenantConnMu       sync.RWMutex
+	tenantConnResolver = func(tenant string) string { return tenantConnectionPrefix + tenant }
+)
+
+// SetTenantConnectionResolver 设置租户到命名连接的映射，默认为 tenant_<租户 ID>
+func SetTenantConnectionResolver(fn func(tenant string) string) {
+	tenantConnMu.Lock()
+	defer tenantConnMu.Unlock()
+	tenantConnResolver = fn
+}
+
+// TenantConnection 返回 ctx 中租户对应的命名连接，用于每个租户独立数据库的部署
+// 没有租户或租户没有配置独立连接时返回默认连接
+func TenantConnection(ctx context.Context) (*LightDatabase, error) {
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		return Connection(DefaultConnection)
+	}
+	tenantConnMu.RLock()
+	name := strings.ToLower(tenantConnResolver(tenant))
+	tenantConnMu.RUnlock()
+
+	for _, configured := range ConnectionNames() {
+		if configured == name {
+			return Connection(name)
+		}
+	}
+	return Connection(DefaultConnection)
+}
+
+// tenantPlugin 按租户自动隔离的 gorm 插件，注册到每个连接
+type tenantPlugin struct{}
+
+func (tenantPlugin) Name() string {
+	return "lighthouse:tenant"
+}
+
+func (tenantPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("lighthouse:tenant_query", scopeTenant(false)); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("lighthouse:tenant_row", scopeTenant(false)); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("lighthouse:tenant_update", scopeTenantUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("lighthouse:tenant_delete", scopeTenant(true)); err != nil {
+		return err
+	}
+	return cb.Create().Before("gorm:create").Register("lighthouse:tenant_create", fillTenant)
+}
+
+// tenantField 返回当前语句模型的租户字段，模型不需要隔离或 ctx 关闭了隔离时返回 nil
+// 需要隔离但 ctx 中没有租户时返回错误
+func tenantField(db *gorm.DB) (*schema.Field, string, error) {
+	stmt := db.Statement
+	if stmt.Schema == nil {
+		return nil, "", nil
+	}
+	field := lookupTenantField(stmt.Schema)
+	if field == nil || IsWithoutTenant(stmt.Context) {
+		return nil, "", nil
+	}
+	tenant, ok := TenantFromContext(stmt.Context)
+	if !ok {
+		return nil, "", lighterr.NewForbiddenError(fmt.Sprintf("缺少租户信息，无法访问 %s", stmt.Schema.Name))
+	}
+	return field, tenant, nil
+}
+
+func lookupTenantField(s *schema.Schema) *schema.Field {
+	for _, f := range s.Fields {
+		if _, ok := f.TagSettings[TenantTag]; ok && f.DBName != "" {
+			return f
+		}
+	}
+	return nil
+}
+
+// scopeTenant 为查询、更新、删除追加租户条件
+func scopeTenant(write bool) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil {
+			return
+		}
+		field, tenant, err := tenantField(db)
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		if field == nil {
+			return
+		}
+		// 没有任何条件的更新、删除交给 gorm 报 ErrMissingWhereClause，不因为租户条件变成全租户更新
+		if write && !db.Statement.AllowGlobalUpdate && !hasPrimaryValue(db.Statement) {
+			if _, ok := db.Statement.Clauses["WHERE"]; !ok {
+				return
+			}
+		}
+		value, err := tenantValue(field, tenant)
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value},
+		}})
+	}
+}
+
+// scopeTenantUpdate 更新时追加租户条件，并把租户列固定为当前租户
+func scopeTenantUpdate(db *gorm.DB) {
+	scopeTenant(true)(db)
+	pinTenant(db)
+}
+
+// pinTenant Save 会更新包括租户列在内的所有字段，租户列为零值或其他租户时会清空租户或把数据移到其他租户，
+// 因此更新前把结构体中的租户列设置为当前租户；Updates、UpdateColumn 中的租户列必须是当前租户
+func pinTenant(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	field, tenant, err := tenantField(db)
+	if err != nil || field == nil {
+		return
+	}
+	value, err := tenantValue(field, tenant)
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]any:
+		for _, key := range []string{field.DBName, field.Name} {
+			if v, ok := dest[key]; ok && fmt.Sprint(v) != fmt.Sprint(value) {
+				_ = db.AddError(lighterr.NewForbiddenError("不能把数据移到其他租户"))
+				return
+			}
+		}
+	default:
+		rv := reflect.Indirect(reflect.ValueOf(dest))
+		if rv.Kind() == reflect.Struct && rv.Type() == db.Statement.Schema.ModelType {
+			assignTenant(db, field, value, rv, "不能把数据移到其他租户")
+		}
+	}
+}
+
+// fillTenant 创建时填充租户列，已有的值与当前租户不一致时拒绝创建
+func fillTenant(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	field, tenant, err := tenantField(db)
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	if field == nil {
+		return
+	}
+	value, err := tenantValue(field, tenant)
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	if !scopeUpsert(db, field, value) {
+		return
+	}
+
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			assignTenant(db, field, value, reflect.Indirect(rv.Index(i)), "不能为其他租户创建数据")
+		}
+	case reflect.Struct:
+		assignTenant(db, field, value, rv, "不能为其他租户创建数据")
+	}
+}
+
+// scopeUpsert 带更新的 upsert（包括 Save 更新不到记录时的回退）冲突的可能是其他租户的记录：
+// 支持 ON CONFLICT ... DO UPDATE ... WHERE 的驱动追加租户条件，MySQL 的 ON DUPLICATE KEY UPDATE 无法附加条件，直接拒绝
+func scopeUpsert(db *gorm.DB, field *schema.Field, value any) bool {
+	c, ok := db.Statement.Clauses["ON CONFLICT"]
+	if !ok {
+		return true
+	}
+	onConflict, ok := c.Expression.(clause.OnConflict)
+	if !ok || onConflict.DoNothing || (!onConflict.UpdateAll && len(onConflict.DoUpdates) == 0) {
+		return true
+	}
+	if db.Dialector.Name() == string(DriverMySQL) {
+		_ = db.AddError(lighterr.NewForbiddenError(fmt.Sprintf("%s 按租户隔离，不支持 upsert，请先查询再更新", db.Statement.Schema.Name)))
+		return false
+	}
+	onConflict.Where.Exprs = append(onConflict.Where.Exprs, clause.Eq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+		Value:  value,
+	})
+	c.Expression = onConflict
+	db.Statement.Clauses["ON CONFLICT"] = c
+	return true
+}
+
+// assignTenant 把记录的租户列设置为当前租户，已有其他租户的值时拒绝
+func assignTenant(db *gorm.DB, field *schema.Field, value any, rv reflect.Value, msg string) {
+	ctx := db.Statement.Context
+	current, zero := field.ValueOf(ctx, rv)
+	if !zero && fmt.Sprint(current) != fmt.Sprint(value) {
+		_ = db.AddError(lighterr.NewForbiddenError(msg))
+		return
+	}
+	if !rv.CanAddr() {
+		return
+	}
+	if err := field.Set(ctx, rv, value); err != nil {
+		_ = db.AddError(err)
+	}
+}
+
+// tenantValue 按租户列的类型转换租户 ID
+func tenantValue(field *schema.Field, tenant string) (any, error) {
+	var (
+		value any = tenant
+		err   error
+	)
+	switch field.DataType {
+	case schema.Int:
+		value, err = strconv.ParseInt(tenant, 10, 64)
+	case schema.Uint:
+		value, err = strconv.ParseUint(tenant, 10, 64)
+	}
+	if err != nil {
+		return nil, lighterr.NewInvalidInputError("租户 ID 无效", err)
+	}
+	return value, nil
+}
+
+func hasPrimaryValue(stmt *gorm.Statement) bool {
+	rv := stmt.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return rv.Len() > 0
+	case reflect.Struct:
+		for _, f := range stmt.Schema.PrimaryFields {
+			if _, zero := f.ValueOf(stmt.Context, rv); !zero {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package databases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/light-speak/lighthouse/lighterr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tenantOrder struct {
+	ID       uint `gorm:"primaryKey"`
+	TenantID uint `gorm:"tenant;index"`
+	Amount   int
+}
+
+func TestTenantScope(t *testing.T) {
+	setupRepository(t)
+	db, _ := LightDatabaseClient.GetDB(context.Background())
+	if err := db.AutoMigrate(&tenantOrder{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	acme := WithTenant(context.Background(), "1")
+	globex := WithTenant(context.Background(), "2")
+	admin := WithoutTenant(context.Background())
+
+	orders := []*tenantOrder{{Amount: 10}, {Amount: 20}}
+	if err := db.WithContext(acme).Create(orders).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if orders[0].TenantID != 1 {
+		t.Errorf("tenant id = %d; expected 1", orders[0].TenantID)
+	}
+	db.WithContext(globex).Create(&tenantOrder{Amount: 30})
+
+	count := func(ctx context.Context) (int64, error) {
+		var n int64
+		err := db.WithContext(ctx).Model(&tenantOrder{}).Count(&n).Error
+		return n, err
+	}
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected int64
+	}{
+		{"Test Tenant Acme", acme, 2},
+		{"Test Tenant Globex", globex, 1},
+		{"Test Without Tenant", admin, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := count(test.ctx)
+			if err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+			if n != test.expected {
+				t.Errorf("count = %d; expected %d", n, test.expected)
+			}
+		})
+	}
+
+	t.Run("Test Tenant Required", func(t *testing.T) {
+		_, err := count(context.Background())
+		var gqlErr *lighterr.GraphQLError
+		if !errors.As(err, &gqlErr) || gqlErr.Code != lighterr.ErrorCodeForbidden {
+			t.Errorf("Count() error = %v; expected forbidden", err)
+		}
+	})
+
+	t.Run("Test Tenant Writes", func(t *testing.T) {
+		result := db.WithContext(globex).Model(orders[0]).Update("amount", 99)
+		if result.Error != nil || result.RowsAffected != 0 {
+			t.Errorf("cross tenant update affected %d rows, error = %v", result.RowsAffected, result.Error)
+		}
+		err := db.WithContext(acme).Model(&tenantOrder{}).Update("amount", 0).Error
+		if !errors.Is(err, gorm.ErrMissingWhereClause) {
+			t.Errorf("global update error = %v; expected ErrMissingWhereClause", err)
+		}
+		if err := db.WithContext(acme).Create(&tenantOrder{TenantID: 2}).Error; err == nil {
+			t.Errorf("creating for another tenant should fail")
+		}
+		result = db.WithContext(acme).Where("amount > ?", 0).Delete(&tenantOrder{})
+		if result.Error != nil || result.RowsAffected != 2 {
+			t.Errorf("delete affected %d rows, error = %v", result.RowsAffected, result.Error)
+		}
+	})
+
+	t.Run("Test Tenant Save", func(t *testing.T) {
+		var other tenantOrder
+		if err := db.WithContext(globex).First(&other).Error; err != nil {
+			t.Fatalf("First() error = %v", err)
+		}
+		// 其他租户的记录既不能被更新，也不能被移到当前租户
+		if err := db.WithContext(acme).Save(&tenantOrder{ID: other.ID, Amount: 1}).Error; err != nil {
+			t.Errorf("Save() error = %v", err)
+		}
+		var reloaded tenantOrder
+		db.WithContext(admin).First(&reloaded, other.ID)
+		if reloaded.TenantID != 2 || reloaded.Amount != other.Amount {
+			t.Errorf("other tenant's order = %+v; expected %+v", reloaded, other)
+		}
+
+		own := &tenantOrder{Amount: 5}
+		db.WithContext(acme).Create(own)
+		own.TenantID, own.Amount = 0, 6
+		if err := db.WithContext(acme).Save(own).Error; err != nil {
+			t.Errorf("Save() error = %v", err)
+		}
+		var saved tenantOrder
+		db.WithContext(admin).First(&saved, own.ID)
+		if saved.TenantID != 1 || saved.Amount != 6 {
+			t.Errorf("saved order = %+v; expected tenant 1 and amount 6", saved)
+		}
+
+		own.TenantID = 2
+		if err := db.WithContext(acme).Save(own).Error; err == nil {
+			t.Errorf("saving into another tenant should fail")
+		}
+		own.TenantID = 1
+		if err := db.WithContext(acme).Model(own).Update("tenant_id", 2).Error; err == nil {
+			t.Errorf("updating the tenant column should fail")
+		}
+	})
+
+	t.Run("Test Tenant Upsert On MySQL", func(t *testing.T) {
+		mysqlDB := openFake(t, &fakePool{})
+		if err := mysqlDB.Use(tenantPlugin{}); err != nil {
+			t.Fatalf("Use() error = %v", err)
+		}
+		err := mysqlDB.WithContext(acme).Clauses(clause.OnConflict{UpdateAll: true}).Create(&tenantOrder{ID: 1}).Error
+		var gqlErr *lighterr.GraphQLError
+		if !errors.As(err, &gqlErr) || gqlErr.Code != lighterr.ErrorCodeForbidden {
+			t.Errorf("upsert error = %v; expected forbidden", err)
+		}
+		err = mysqlDB.WithContext(acme).Clauses(clause.OnConflict{DoNothing: true}).Create(&tenantOrder{ID: 1}).Error
+		if err != nil {
+			t.Errorf("insert ignore error = %v", err)
+		}
+	})
+}
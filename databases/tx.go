@@ -0,0 +1,143 @@
+package databases
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/light-speak/lighthouse/logs"
+	"gorm.io/gorm"
+)
+
+// txContextKey 按连接区分事务，不同命名连接的事务可以同时存在于 ctx 中
+type txContextKey struct {
+	owner *LightDatabase
+}
+
+// currentTxContextKey 最内层的事务，AfterCommit 注册到这里
+type currentTxContextKey struct{}
+
+// txState 保存在 ctx 中的事务状态，嵌套调用通过 parent 串起来
+type txState struct {
+	db     *gorm.DB
+	parent *txState
+
+	mu          sync.Mutex
+	afterCommit []func(ctx context.Context) error
+}
+
+func (s *txState) addAfterCommit(fns ...func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.afterCommit = append(s.afterCommit, fns...)
+}
+
+func (s *txState) hooks() []func(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]func(ctx context.Context) error(nil), s.afterCommit...)
+}
+
+// WithTx 使用默认数据库在事务中执行 fn
+func WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return LightDatabaseClient.WithTx(ctx, fn)
+}
+
+// WithTx 在事务中执行 fn，事务会保存在传给 fn 的 ctx 中，
+// fn 内通过 GetDB(ctx) / DB(ctx) 拿到的都是当前事务。
+// 嵌套调用会创建保存点，内层返回错误只回滚到保存点；
+// fn 返回错误或 panic 时回滚，否则提交并执行 AfterCommit 注册的钩子；
+// 嵌套在其他连接的事务中时，钩子在最外层事务提交后执行
+func (l *LightDatabase) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	parent := l.txFromContext(ctx)
+
+	var base *gorm.DB
+	if parent != nil {
+		base = parent.db
+	} else {
+		db, err := l.GetDB(ctx)
+		if err != nil {
+			return err
+		}
+		base = db
+	}
+
+	state := &txState{parent: parent}
+	err := base.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.db = tx
+		txCtx := context.WithValue(ctx, txContextKey{owner: l}, state)
+		return fn(context.WithValue(txCtx, currentTxContextKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+
+	// 保存点释放成功，或外层还有其他连接的事务，钩子要等外层事务提交后才能执行，外层回滚时一起丢弃
+	if outer := currentTx(ctx); outer != nil {
+		outer.addAfterCommit(state.hooks()...)
+		return nil
+	}
+
+	runAfterCommit(ctx, state.hooks())
+	return nil
+}
+
+// AfterCommit 注册事务提交后执行的钩子，例如发布消息、投递队列任务
+// ctx 不在事务中时立即执行；所在事务（或保存点）回滚时钩子会被丢弃
+func AfterCommit(ctx context.Context, fn func(ctx context.Context) error) {
+	if state := currentTx(ctx); state != nil {
+		state.addAfterCommit(fn)
+		return
+	}
+	runAfterCommit(ctx, []func(ctx context.Context) error{fn})
+}
+
+// InTx 判断 ctx 是否处于事务中（任意连接）
+func InTx(ctx context.Context) bool {
+	return currentTx(ctx) != nil
+}
+
+// TxFromContext 获取 ctx 中默认连接的事务
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	return LightDatabaseClient.TxFromContext(ctx)
+}
+
+// TxFromContext 获取 ctx 中当前连接的事务
+func (l *LightDatabase) TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	state := l.txFromContext(ctx)
+	if state == nil {
+		return nil, false
+	}
+	return state.db.WithContext(ctx), true
+}
+
+func (l *LightDatabase) txFromContext(ctx context.Context) *txState {
+	if ctx == nil {
+		return nil
+	}
+	state, _ := ctx.Value(txContextKey{owner: l}).(*txState)
+	return state
+}
+
+func currentTx(ctx context.Context) *txState {
+	if ctx == nil {
+		return nil
+	}
+	state, _ := ctx.Value(currentTxContextKey{}).(*txState)
+	return state
+}
+
+func runAfterCommit(ctx context.Context, hooks []func(ctx context.Context) error) {
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logs.Error().Err(fmt.Errorf("%v", r)).Msg("panic in after commit hook")
+				}
+			}()
+			if err := hook(ctx); err != nil {
+				logs.Error().Err(err).Msg("after commit hook failed")
+			}
+		}()
+	}
+}
@@ -0,0 +1,181 @@
+package databases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithTx(t *testing.T) {
+	errFail := errors.New("fail")
+
+	newDB := func(t *testing.T) (*LightDatabase, *fakePool) {
+		pool := &fakePool{}
+		return &LightDatabase{MainDB: openFake(t, pool), Completed: true}, pool
+	}
+
+	t.Run("Test Commit Runs Hooks", func(t *testing.T) {
+		l, pool := newDB(t)
+		var fired []string
+		err := l.WithTx(context.Background(), func(ctx context.Context) error {
+			if !InTx(ctx) {
+				t.Error("ctx should be in a transaction")
+			}
+			if db, _ := l.GetDB(ctx); db.Statement.ConnPool == l.MainDB.Statement.ConnPool {
+				t.Error("GetDB should return the transaction")
+			}
+			AfterCommit(ctx, func(ctx context.Context) error {
+				fired = append(fired, "outer")
+				return nil
+			})
+			return l.WithTx(ctx, func(ctx context.Context) error {
+				AfterCommit(ctx, func(ctx context.Context) error {
+					fired = append(fired, "inner")
+					return nil
+				})
+				return nil
+			})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pool.commits != 1 || pool.rollbacks != 0 {
+			t.Errorf("commits = %d, rollbacks = %d", pool.commits, pool.rollbacks)
+		}
+		if strings.Join(fired, ",") != "outer,inner" {
+			t.Errorf("fired = %v", fired)
+		}
+	})
+
+	t.Run("Test Rollback Drops Hooks", func(t *testing.T) {
+		l, pool := newDB(t)
+		fired := false
+		err := l.WithTx(context.Background(), func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) error {
+				fired = true
+				return nil
+			})
+			return errFail
+		})
+		if !errors.Is(err, errFail) {
+			t.Errorf("err = %v", err)
+		}
+		if pool.rollbacks != 1 || fired {
+			t.Errorf("rollbacks = %d, fired = %v", pool.rollbacks, fired)
+		}
+	})
+
+	t.Run("Test Nested Error Rolls Back To Savepoint", func(t *testing.T) {
+		l, pool := newDB(t)
+		var fired []string
+		err := l.WithTx(context.Background(), func(ctx context.Context) error {
+			inner := l.WithTx(ctx, func(ctx context.Context) error {
+				AfterCommit(ctx, func(ctx context.Context) error {
+					fired = append(fired, "inner")
+					return nil
+				})
+				return errFail
+			})
+			if !errors.Is(inner, errFail) {
+				t.Errorf("inner err = %v", inner)
+			}
+			AfterCommit(ctx, func(ctx context.Context) error {
+				fired = append(fired, "outer")
+				return nil
+			})
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		statements := strings.Join(pool.statements, ";")
+		if !strings.Contains(statements, "SAVEPOINT") || !strings.Contains(statements, "ROLLBACK TO SAVEPOINT") {
+			t.Errorf("statements = %s", statements)
+		}
+		if pool.commits != 1 || strings.Join(fired, ",") != "outer" {
+			t.Errorf("commits = %d, fired = %v", pool.commits, fired)
+		}
+	})
+
+	t.Run("Test Panic Rolls Back", func(t *testing.T) {
+		l, pool := newDB(t)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("panic should be propagated")
+				}
+			}()
+			_ = l.WithTx(context.Background(), func(ctx context.Context) error {
+				panic("boom")
+			})
+		}()
+		if pool.rollbacks != 1 || pool.commits != 0 {
+			t.Errorf("commits = %d, rollbacks = %d", pool.commits, pool.rollbacks)
+		}
+	})
+
+	t.Run("Test Hook Panic Is Recovered", func(t *testing.T) {
+		l, _ := newDB(t)
+		fired := false
+		err := l.WithTx(context.Background(), func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) error { panic("boom") })
+			AfterCommit(ctx, func(ctx context.Context) error {
+				fired = true
+				return nil
+			})
+			return nil
+		})
+		if err != nil || !fired {
+			t.Errorf("err = %v, fired = %v", err, fired)
+		}
+	})
+
+	t.Run("Test Other Connection Waits For Outer Tx", func(t *testing.T) {
+		outer, outerPool := newDB(t)
+		other, otherPool := newDB(t)
+		var fired []string
+		run := func(fail error) error {
+			return outer.WithTx(context.Background(), func(ctx context.Context) error {
+				err := other.WithTx(ctx, func(ctx context.Context) error {
+					AfterCommit(ctx, func(ctx context.Context) error {
+						fired = append(fired, "other")
+						return nil
+					})
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				if len(fired) != 0 {
+					t.Error("hook should wait for the outer transaction")
+				}
+				return fail
+			})
+		}
+
+		if err := run(errFail); !errors.Is(err, errFail) {
+			t.Errorf("err = %v", err)
+		}
+		if otherPool.commits != 1 || outerPool.rollbacks != 1 || len(fired) != 0 {
+			t.Errorf("other commits = %d, outer rollbacks = %d, fired = %v", otherPool.commits, outerPool.rollbacks, fired)
+		}
+		if err := run(nil); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(fired, ",") != "other" {
+			t.Errorf("fired = %v", fired)
+		}
+	})
+
+	t.Run("Test AfterCommit Outside Tx Runs Immediately", func(t *testing.T) {
+		fired := false
+		AfterCommit(context.Background(), func(ctx context.Context) error {
+			fired = true
+			return nil
+		})
+		if !fired {
+			t.Error("hook should run immediately")
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package databases
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/light-speak/lighthouse/lighterr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// VersionTag 乐观锁版本字段的 gorm tag，由 @version 指令生成，如 `gorm:"version"`
+// 模型没有该 tag 时使用名为 version 的列
+const VersionTag = "VERSION"
+
+// UpdateWithVersion 使用乐观锁更新 model
+// 更新条件追加 WHERE version = 当前版本，同时把版本号加 1；没有更新到记录时说明记录已被其他请求修改（或已删除），返回 Conflict 错误。
+// values 为空时更新 model 的全部字段（主键、创建时间除外），更新成功后 model 中的版本号会同步为新版本。
+// db 可以是事务，例如 WithTx 中 GetDB(ctx) 返回的连接
+func UpdateWithVersion(db *gorm.DB, model any, values map[string]any) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return lighterr.NewInternalError("模型解析失败", err)
+	}
+	field := versionField(stmt.Schema)
+	if field == nil {
+		return lighterr.NewInternalError(fmt.Sprintf("模型 %s 没有版本字段", stmt.Schema.Name))
+	}
+
+	ctx := db.Statement.Context
+	rv := reflect.ValueOf(model)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return lighterr.NewInternalError("model 必须是非空指针")
+	}
+	rv = rv.Elem()
+	for _, pk := range stmt.Schema.PrimaryFields {
+		if _, zero := pk.ValueOf(ctx, rv); zero {
+			return lighterr.NewInvalidInputError("缺少主键，无法更新")
+		}
+	}
+	current, _ := field.ValueOf(ctx, rv)
+	version, ok := intValue(current)
+	if !ok {
+		return lighterr.NewInternalError(fmt.Sprintf("版本字段 %s 必须是整数", field.Name))
+	}
+
+	updates := make(map[string]any, len(values)+1)
+	if len(values) == 0 {
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName == "" || !f.Updatable || f.PrimaryKey || f.AutoCreateTime > 0 || f == field {
+				continue
+			}
+			updates[f.DBName], _ = f.ValueOf(ctx, rv)
+		}
+	}
+	for k, v := range values {
+		updates[k] = v
+	}
+	updates[field.DBName] = version + 1
+
+	result := db.Model(model).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: version}).
+		Updates(updates)
+	if result.Error != nil {
+		return lighterr.NewDatabaseError("更新失败", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// gorm 会把 updates 回写到 model，冲突时恢复原版本号
+		_ = field.Set(ctx, rv, current)
+		return lighterr.NewConflictError("记录已被修改，请刷新后重试", errors.New("version conflict"))
+	}
+	if err := field.Set(ctx, rv, version+1); err != nil {
+		return lighterr.NewInternalError("更新版本号失败", err)
+	}
+	return nil
+}
+
+// versionField 查找模型的版本字段
+func versionField(s *schema.Schema) *schema.Field {
+	for _, f := range s.Fields {
+		if _, ok := f.TagSettings[VersionTag]; ok {
+			return f
+		}
+	}
+	return s.LookUpField("version")
+}
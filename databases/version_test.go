@@ -0,0 +1,57 @@
+package databases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/light-speak/lighthouse/lighterr"
+)
+
+type versionedPost struct {
+	ID      uint `gorm:"primaryKey"`
+	Title   string
+	Version int64 `gorm:"version;not null;default:0"`
+}
+
+func TestUpdateWithVersion(t *testing.T) {
+	setupRepository(t)
+	db, _ := LightDatabaseClient.GetDB(context.Background())
+	if err := db.AutoMigrate(&versionedPost{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	post := &versionedPost{Title: "draft"}
+	if err := db.Create(post).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	stale := *post
+
+	t.Run("Test Update With Version", func(t *testing.T) {
+		if err := UpdateWithVersion(db, post, map[string]any{"title": "published"}); err != nil {
+			t.Fatalf("UpdateWithVersion() error = %v", err)
+		}
+		if post.Version != 1 {
+			t.Errorf("version = %d; expected 1", post.Version)
+		}
+		post.Title = "edited"
+		if err := UpdateWithVersion(db, post, nil); err != nil {
+			t.Fatalf("UpdateWithVersion() error = %v", err)
+		}
+		var saved versionedPost
+		db.First(&saved, post.ID)
+		if saved.Title != "edited" || saved.Version != 2 {
+			t.Errorf("saved = %+v; expected title edited, version 2", saved)
+		}
+	})
+
+	t.Run("Test Update With Stale Version", func(t *testing.T) {
+		err := UpdateWithVersion(db, &stale, map[string]any{"title": "lost"})
+		var gqlErr *lighterr.GraphQLError
+		if !errors.As(err, &gqlErr) || gqlErr.Code != lighterr.ErrorCodeConflict {
+			t.Fatalf("UpdateWithVersion() error = %v; expected conflict", err)
+		}
+		if stale.Version != 0 {
+			t.Errorf("stale version changed to %d", stale.Version)
+		}
+	})
+}
@@ -4,14 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	_ "time/tzdata"
 
 	"github.com/light-speak/lighthouse/logs"
-	"gorm.io/driver/mysql"
+	"github.com/light-speak/lighthouse/metrics"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -21,98 +21,177 @@ type LightDatabase struct {
 	SlaveDBs  []*gorm.DB
 	Completed bool
 	Error     error
+
+	mu         sync.RWMutex
+	config     *DatabaseConfig // 连接使用的配置，从库负载均衡与健康检查读取其中的设置
+	host       string          // 主库地址，用于连接池指标
+	replicas   []*replica
+	stopHealth chan struct{}
+
+	// 延迟连接状态，只用于默认连接
+	ready       atomic.Bool
+	lastAttempt time.Time
 }
 
-var LightDatabaseClient *LightDatabase
+// LightDatabaseClient 默认连接，首次使用时按 .env 配置自动连接，也可以通过 Init 显式初始化
+var LightDatabaseClient = &LightDatabase{}
 
-func init() {
-	// 从配置加载时区，默认 Asia/Shanghai
-	loc, err := time.LoadLocation(databaseConfig.Timezone)
-	if err != nil {
-		panic(err)
-	}
-	time.Local = loc
-	databaseConfig.Main.LogLevel = databaseConfig.LogLevel
-	databaseConfig.Slave.LogLevel = databaseConfig.LogLevel
+// 连接失败后，延迟连接的最小重试间隔
+const lazyRetryInterval = 5 * time.Second
 
-	// 初始化数据库连接，添加重试机制
-	initDatabaseWithRetry(loc, databaseConfig.Timezone)
-}
+// ErrClosed 调用 CloseConnections（lighthouse.Shutdown）之后获取连接时返回，再次调用 Init 后恢复
+var ErrClosed = errors.New("database is closed")
 
-// initDatabaseWithRetry 初始化数据库连接，添加重试机制
-func initDatabaseWithRetry(loc *time.Location, timezone string) {
-	maxRetries := 5
-	retryInterval := 5 * time.Second
+var (
+	initMu sync.Mutex
+	// closed 默认连接已关闭，不再自动重连，由 initMu 保护
+	closed bool
+)
 
-	for i := 0; i < maxRetries; i++ {
-		// 尝试初始化主库
-		mainDB, err := initDB(databaseConfig.Main, loc, timezone)
-		if err != nil {
-			logs.Error().Err(err).Int("retry", i+1).Msg("main database init error, retrying...")
-
-			// 如果已经是最后一次尝试，则设置错误状态并返回
-			if i == maxRetries-1 {
-				logs.Error().Err(err).Msg("main database init failed after maximum retries")
-				LightDatabaseClient = &LightDatabase{
-					Completed: false,
-					MainDB:    nil,
-					Error:     err,
-				}
-				return
-			}
+// Init 使用给定配置连接默认数据库（含从库），并注册命名连接，cfg 为 nil 时通过 LoadConfig 加载
+// 已经初始化过时会先关闭原有连接
+func Init(cfg *DatabaseConfig) error {
+	initMu.Lock()
+	defer initMu.Unlock()
 
-			// 等待一段时间后重试
-			time.Sleep(retryInterval)
-			continue
+	if cfg == nil {
+		loaded, err := LoadConfig()
+		if err != nil {
+			return err
 		}
+		cfg = loaded
+	}
+	cfg.normalize()
 
-		// 初始化从库
-		var slaveDBs []*gorm.DB
-		if databaseConfig.EnableSlave && len(databaseConfig.Slave.Hosts) > 0 {
-			for _, host := range databaseConfig.Slave.Hosts {
-				slaveConfig := *databaseConfig.Slave
-				slaveConfig.Hosts = []string{host}
-				slaveDB, err := initDB(&slaveConfig, loc, timezone)
-				if err != nil {
-					logs.Error().Err(err).Str("host", host).Msg("slave database init error")
-					continue
-				}
-				slaveDBs = append(slaveDBs, slaveDB)
-			}
-		}
+	LightDatabaseClient.closeAll()
+	closed = false
+	return connect(cfg)
+}
 
-		// 如果没有从库，使用主库作为从库
-		if len(slaveDBs) == 0 {
-			slaveDBs = []*gorm.DB{mainDB}
+// ensure 默认连接在首次使用时自动连接，失败后按 lazyRetryInterval 限制重试频率
+func (l *LightDatabase) ensure() error {
+	if l.ready.Load() {
+		return nil
+	}
+	if l != LightDatabaseClient {
+		if !l.completed() {
+			return fmt.Errorf("database is not completed, error: %v", l.Error)
 		}
+		return nil
+	}
 
-		LightDatabaseClient = &LightDatabase{
-			MainDB:    mainDB,
-			SlaveDBs:  slaveDBs,
-			Completed: true,
-		}
+	initMu.Lock()
+	defer initMu.Unlock()
+	if l.ready.Load() {
+		return nil
+	}
+	if closed {
+		return ErrClosed
+	}
+	if l.Error != nil && time.Since(l.lastAttempt) < lazyRetryInterval {
+		return fmt.Errorf("database is not completed, error: %v", l.Error)
+	}
 
-		logs.Info().Msg("database connection initialized successfully")
-		return
+	cfg, err := loadConfigLocked()
+	if err != nil {
+		l.Error = err
+		l.lastAttempt = time.Now()
+		return err
+	}
+	if err := connect(cfg); err != nil {
+		return fmt.Errorf("database is not completed, error: %v", err)
+	}
+	return nil
+}
+
+// loadConfigLocked 首次使用时通过 LoadConfig 加载配置并注册命名连接，调用方需持有 initMu
+func loadConfigLocked() (*DatabaseConfig, error) {
+	if databaseConfig != nil {
+		return databaseConfig, nil
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	databaseConfig = cfg
+	initConnections(cfg)
+	return cfg, nil
+}
+
+// getConfig 返回当前配置，尚未加载时通过 LoadConfig 加载，加载失败则使用默认配置
+func getConfig() *DatabaseConfig {
+	initMu.Lock()
+	defer initMu.Unlock()
+	cfg, err := loadConfigLocked()
+	if err != nil {
+		return DefaultConfig()
+	}
+	return cfg
+}
+
+// connect 连接默认数据库，调用方需持有 initMu
+func connect(cfg *DatabaseConfig) error {
+	l := LightDatabaseClient
+	if databaseConfig != cfg {
+		databaseConfig = cfg
+		initConnections(cfg)
+	}
+	l.lastAttempt = time.Now()
+
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		l.Error = err
+		return err
+	}
+	time.Local = loc
+	slowQueries.resize(cfg.SlowLogSize)
+
+	mainDB, err := initDB(DefaultConnection, cfg.Main, loc, cfg.Timezone)
+	if err != nil {
+		logs.Error().Err(err).Msg("main database init error")
+		l.mu.Lock()
+		l.MainDB = nil
+		l.Completed = false
+		l.Error = err
+		l.mu.Unlock()
+		return err
+	}
+
+	// 初始化从库，连接失败的从库由健康检查负责重连，SQLite 没有从库
+	var replicas []*replica
+	if cfg.EnableSlave && cfg.Driver != DriverSQLite && len(cfg.Slave.Hosts) > 0 {
+		replicas = newReplicas(cfg, loc)
+	}
+
+	l.mu.Lock()
+	l.MainDB = mainDB
+	l.config = cfg
+	l.host = poolHost(cfg.Main)
+	l.Completed = true
+	l.Error = nil
+	l.replicas = replicas
+	// 如果没有从库，使用主库作为从库
+	l.refreshSlaveDBs()
+	l.mu.Unlock()
+	l.startHealthCheck(loc)
+
+	// 注册读写分离 callback
+	if err := l.registerResolver(); err != nil {
+		logs.Error().Err(err).Msg("failed to register database resolver")
 	}
+
+	l.ready.Store(true)
+	logs.Info().Msg("database connection initialized successfully")
+	return nil
 }
 
-func initDB(config *DatabaseConfig, loc *time.Location, timezone string) (*gorm.DB, error) {
-	// URL encode timezone (e.g., Asia/Shanghai -> Asia%2FShanghai)
-	encodedTZ := strings.ReplaceAll(timezone, "/", "%2F")
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4",
-		config.User,
-		config.Password,
-		config.Hosts[0], // 使用第一个host
-		config.Port,
-		config.Name,
-	)
-	dsn += "&parseTime=True&loc=" + encodedTZ + "&timeout=10s&readTimeout=30s&writeTimeout=30s"
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+// initDB 打开连接，name 为连接名，用于查询耗时指标
+func initDB(name string, config *DatabaseConfig, loc *time.Location, timezone string) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector(config, timezone), &gorm.Config{
 		DisableForeignKeyConstraintWhenMigrating: true,
 		IgnoreRelationshipsWhenMigrating:         true,
-		Logger:                                   &DBLogger{LogLevel: config.LogLevel},
-		PrepareStmt:                              databaseConfig.PrepareStmt,
+		Logger:                                   &DBLogger{LogLevel: config.LogLevel, SlowThreshold: config.SlowThreshold, Connection: name},
+		PrepareStmt:                              config.PrepareStmt,
 		SkipDefaultTransaction:                   true,
 		NowFunc: func() time.Time {
 			return time.Now().In(loc)
@@ -121,46 +200,57 @@ func initDB(config *DatabaseConfig, loc *time.Location, timezone string) (*gorm.
 	if err != nil {
 		return nil, err
 	}
+	if err := usePlugins(db); err != nil {
+		return nil, err
+	}
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
 
-	sqlDB.SetMaxIdleConns(databaseConfig.MaxIdleConns)
-	sqlDB.SetMaxOpenConns(databaseConfig.MaxOpenConns)
-	sqlDB.SetConnMaxLifetime(time.Duration(databaseConfig.ConnMaxLifetime) * time.Minute)
-	sqlDB.SetConnMaxIdleTime(time.Duration(databaseConfig.ConnMaxIdleTime) * time.Minute)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(config.ConnMaxLifetime) * time.Minute)
+	sqlDB.SetConnMaxIdleTime(time.Duration(config.ConnMaxIdleTime) * time.Minute)
 	return db, nil
 }
 
-// GetDB 获取主库连接
+// GetDB 获取主库连接，ctx 处于 WithTx 事务中时返回当前事务
 func (l *LightDatabase) GetDB(ctx context.Context) (*gorm.DB, error) {
 	if l == nil {
 		return nil, fmt.Errorf("database is not initialized")
 	}
-	if !l.Completed {
-		return nil, fmt.Errorf("database is not completed, error: %v", l.Error)
+	if err := l.ensure(); err != nil {
+		return nil, err
+	}
+	if tx, ok := l.TxFromContext(ctx); ok {
+		return tx, nil
 	}
 
-	return l.MainDB, nil
+	return l.MainDB.WithContext(ctx), nil
 }
 
-// GetSlaveDB 获取从库连接，实现负载均衡
+// GetSlaveDB 获取从库连接，实现负载均衡，ctx 处于事务中时返回当前事务
 func (l *LightDatabase) GetSlaveDB(ctx context.Context) (*gorm.DB, error) {
 	if l == nil {
 		return nil, fmt.Errorf("database is not initialized")
 	}
-	if !l.Completed {
-		return nil, fmt.Errorf("database is not completed, error: %v", l.Error)
+	if err := l.ensure(); err != nil {
+		return nil, err
+	}
+	if tx, ok := l.TxFromContext(ctx); ok {
+		return tx, nil
 	}
 
-	slaveDB := l.SlaveDBs[rand.Intn(len(l.SlaveDBs))]
-
-	return slaveDB, nil
+	return l.pickSlave().WithContext(ctx), nil
 }
 
 type DBLogger struct {
 	LogLevel logger.LogLevel
+	// SlowThreshold 慢查询阈值，为 0 时使用 200ms
+	SlowThreshold time.Duration
+	// Connection 连接名，作为查询耗时指标的标签
+	Connection string
 }
 
 func (l *DBLogger) LogMode(level logger.LogLevel) logger.Interface {
@@ -181,28 +271,69 @@ func (l *DBLogger) Error(ctx context.Context, msg string, data ...interface{}) {
 }
 
 func (l *DBLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	operation := sqlOperation(sql)
+
+	threshold := l.SlowThreshold
+	if threshold <= 0 {
+		threshold = defaultSlowThreshold
+	}
+	slow := err == nil && elapsed > threshold
+	// 指纹在慢查询时计算，其他查询只在已有慢查询记录时计算，用于耗时指标的 fingerprint 标签
+	var fingerprint, gqlOperation, gqlField, label string
+	if slow {
+		fingerprint = Fingerprint(sql)
+		gqlOperation, gqlField = graphqlOrigin(ctx)
+		slowQueries.add(SlowQuery{
+			Fingerprint:      fingerprint,
+			Operation:        operation,
+			GraphQLOperation: gqlOperation,
+			GraphQLField:     gqlField,
+			Duration:         elapsed,
+			Rows:             rows,
+			Time:             begin,
+		})
+		label = fingerprint
+	} else {
+		label = slowQueries.fingerprintLabel(sql)
+	}
+	metrics.DBQueryDuration.WithLabelValues(operation, l.Connection, label).Observe(elapsed.Seconds())
+
 	if l.LogLevel <= logger.Silent {
 		return
 	}
-
-	elapsed := time.Since(begin)
-	sql, rows := fc()
 	if err != nil && l.LogLevel >= logger.Error {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return
 		}
 		logs.Error().Err(err).Str("sql", sql).Int64("rows", rows).Msg("database error")
-	} else if elapsed > 200*time.Millisecond && l.LogLevel >= logger.Warn {
-		logs.Warn().Str("sql", sql).Int64("rows", rows).Msg("database slow query")
+	} else if slow && l.LogLevel >= logger.Warn {
+		logs.Warn().Str("sql", sql).Str("fingerprint", fingerprint).
+			Str("graphql_operation", gqlOperation).Str("graphql_field", gqlField).
+			Dur("elapsed", elapsed).Int64("rows", rows).Msg("database slow query")
 	} else if l.LogLevel >= logger.Info {
 		logs.Info().Str("sql", sql).Int64("rows", rows).Msg("database query")
 	}
 }
 
 // Stats 返回数据库连接池统计信息
+// 默认连接会同时汇总所有命名连接，key 为 <name>_main
 func (l *LightDatabase) Stats() map[string]interface{} {
+	stats := l.poolStats()
+	if l != nil && l == LightDatabaseClient {
+		for name, conn := range namedConnections() {
+			for k, v := range conn.poolStats() {
+				stats[name+"_"+k] = v
+			}
+		}
+	}
+	return stats
+}
+
+func (l *LightDatabase) poolStats() map[string]interface{} {
 	stats := make(map[string]interface{})
-	if l == nil || !l.Completed {
+	if !l.completed() {
 		return stats
 	}
 
@@ -220,7 +351,7 @@ func (l *LightDatabase) Stats() map[string]interface{} {
 		}
 	}
 
-	for i, slaveDB := range l.SlaveDBs {
+	for i, slaveDB := range l.slaveDBs() {
 		if slaveDB != nil {
 			if sqlDB, err := slaveDB.DB(); err == nil {
 				s := sqlDB.Stats()
@@ -239,9 +370,18 @@ func (l *LightDatabase) Stats() map[string]interface{} {
 	return stats
 }
 
-// LogStats 记录数据库连接池统计信息到日志
+// LogStats 记录数据库连接池统计信息到日志，默认连接会同时记录所有命名连接
 func (l *LightDatabase) LogStats() {
-	if l == nil || !l.Completed {
+	l.logStats(DefaultConnection)
+	if l != nil && l == LightDatabaseClient {
+		for name, conn := range namedConnections() {
+			conn.logStats(name)
+		}
+	}
+}
+
+func (l *LightDatabase) logStats(name string) {
+	if !l.completed() {
 		return
 	}
 
@@ -249,6 +389,7 @@ func (l *LightDatabase) LogStats() {
 		if sqlDB, err := l.MainDB.DB(); err == nil {
 			s := sqlDB.Stats()
 			logs.Info().
+				Str("connection", name).
 				Int("in_use", s.InUse).
 				Int("idle", s.Idle).
 				Int("open", s.OpenConnections).
@@ -261,24 +402,67 @@ func (l *LightDatabase) LogStats() {
 }
 
 // CloseConnections 提供一个方法用于安全地关闭数据库连接
-// 应仅在确认不再需要使用数据库时调用，例如应用程序关闭时
+// 应仅在确认不再需要使用数据库时调用，例如应用程序关闭时，默认连接会同时关闭所有命名连接，
+// 之后获取连接返回 ErrClosed，不会自动重连，需要重新调用 Init
 func (l *LightDatabase) CloseConnections() {
-	if l == nil || !l.Completed {
+	if l == nil || l != LightDatabaseClient {
+		l.close(DefaultConnection)
 		return
 	}
+	initMu.Lock()
+	defer initMu.Unlock()
+	closed = true
+	l.closeAll()
+}
 
-	if l.MainDB != nil {
-		sqlDB, err := l.MainDB.DB()
+// closeAll 关闭默认连接和所有命名连接，调用方需持有 initMu
+func (l *LightDatabase) closeAll() {
+	l.close(DefaultConnection)
+	for name, conn := range namedConnections() {
+		conn.close(name)
+	}
+}
+
+// completed 是否已连接
+func (l *LightDatabase) completed() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.Completed
+}
+
+func (l *LightDatabase) close(name string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	if !l.Completed {
+		l.mu.Unlock()
+		return
+	}
+	l.Completed = false
+	l.ready.Store(false)
+	if l.stopHealth != nil {
+		close(l.stopHealth)
+		l.stopHealth = nil
+	}
+	mainDB := l.MainDB
+	l.mu.Unlock()
+
+	if mainDB != nil {
+		sqlDB, err := mainDB.DB()
 		if err != nil {
-			logs.Error().Err(err).Msg("error getting main DB connection while closing")
+			logs.Error().Err(err).Str("connection", name).Msg("error getting main DB connection while closing")
 		} else {
 			sqlDB.Close()
-			logs.Info().Msg("main database connection closed")
+			logs.Info().Str("connection", name).Msg("main database connection closed")
 		}
 	}
 
-	for i, slaveDB := range l.SlaveDBs {
-		if slaveDB != nil {
+	for i, slaveDB := range l.slaveDBs() {
+		if slaveDB != nil && slaveDB != mainDB {
 			sqlDB, err := slaveDB.DB()
 			if err != nil {
 				logs.Error().Err(err).Int("slave_index", i).Msg("error getting slave DB connection while closing")
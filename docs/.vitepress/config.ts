@@ -29,6 +29,7 @@ export default defineConfig({
           items: [
             { text: '快速开始', link: '/guide/getting-started' },
             { text: 'CLI 命令', link: '/guide/cli' },
+            { text: '配置', link: '/guide/configuration' },
             { text: '项目结构', link: '/guide/project-structure' },
           ]
         }
@@ -50,6 +51,8 @@ export default defineConfig({
           items: [
             { text: '数据库', link: '/features/database' },
             { text: '数据库迁移', link: '/features/migration' },
+            { text: '数据填充', link: '/features/seeding' },
+            { text: '查询缓存', link: '/features/cache' },
             { text: '中间件与认证', link: '/features/auth' },
             { text: '健康检查', link: '/features/health' },
           ]
@@ -59,6 +62,7 @@ export default defineConfig({
           items: [
             { text: '异步任务队列', link: '/features/queue' },
             { text: '消息系统', link: '/features/messaging' },
+            { text: '事务性 Outbox', link: '/features/outbox' },
             { text: '文件存储', link: '/features/storage' },
             { text: '实时推送', link: '/features/subscription' },
             { text: '监控与指标', link: '/features/metrics' },
@@ -0,0 +1,373 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/light-speak/lighthouse/databases"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 模型工厂
+// 为模型的零值字段填充假数据并写入数据库，用于开发环境填充数据和测试：
+//
+//	users, err := factory.New[models.User]().With(func(u *models.User) { u.Name = "admin" }).Create(ctx, 10)
+//
+// 每条记录的假数据由种子和记录序号决定，同样的调用顺序每次生成同样的数据；
+// 通过 Register 登记的外键为零值时，会先用工厂创建关联的模型
+
+// DefaultSeed 默认的假数据种子
+const DefaultSeed uint64 = 1
+
+// maxDepth 创建关联模型的最大层数，防止循环关联
+const maxDepth = 8
+
+type relation struct {
+	field  string
+	parent reflect.Type
+}
+
+// Relation 模型关联
+type Relation func(r *relation)
+
+// BelongsTo field 外键（字段名或列名）指向 parent 的主键
+func BelongsTo(field string, parent any) Relation {
+	return func(r *relation) {
+		r.field = field
+		r.parent = modelType(parent)
+	}
+}
+
+var (
+	mu        sync.RWMutex
+	relations = make(map[reflect.Type][]*relation)
+	sequences = make(map[reflect.Type]uint64)
+	schemas   = &sync.Map{}
+)
+
+// Register 登记模型的关联，generate:schema 生成的 models/factory_gen.go 会按 @loader 的外键登记
+func Register(model any, rels ...Relation) {
+	mu.Lock()
+	defer mu.Unlock()
+	typ := modelType(model)
+	for _, rel := range rels {
+		r := &relation{}
+		rel(r)
+		relations[typ] = append(relations[typ], r)
+	}
+}
+
+// Reset 重置记录序号，测试中每次使用新的数据库时调用，使生成的数据与执行顺序无关
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	sequences = make(map[reflect.Type]uint64)
+}
+
+// Factory 模型工厂
+type Factory[T any] struct {
+	db     *gorm.DB
+	seed   uint64
+	states []func(m *T)
+}
+
+// New 创建模型工厂
+func New[T any]() *Factory[T] {
+	return &Factory[T]{seed: DefaultSeed}
+}
+
+// With 在填充假数据之后修改模型，用于指定字段的值
+func (f *Factory[T]) With(states ...func(m *T)) *Factory[T] {
+	c := f.clone()
+	c.states = append(c.states, states...)
+	return c
+}
+
+// Seed 指定假数据种子
+func (f *Factory[T]) Seed(seed uint64) *Factory[T] {
+	c := f.clone()
+	c.seed = seed
+	return c
+}
+
+// Using 指定数据库，默认使用 databases.LightDatabaseClient
+func (f *Factory[T]) Using(db *gorm.DB) *Factory[T] {
+	c := f.clone()
+	c.db = db
+	return c
+}
+
+// Make 生成 n 个模型，不写入数据库，也不创建关联的模型
+func (f *Factory[T]) Make(n int) ([]*T, error) {
+	values, err := f.builder(nil).make(reflect.TypeFor[T](), n, f.apply)
+	if err != nil {
+		return nil, err
+	}
+	return f.typed(values), nil
+}
+
+// Create 生成 n 个模型并写入数据库，外键为零值时先创建关联的模型
+func (f *Factory[T]) Create(ctx context.Context, n int) ([]*T, error) {
+	db := f.db
+	if db == nil {
+		var err error
+		if db, err = databases.LightDatabaseClient.GetDB(ctx); err != nil {
+			return nil, err
+		}
+	}
+	values, err := f.builder(db.WithContext(ctx)).create(reflect.TypeFor[T](), n, f.apply, 0)
+	if err != nil {
+		return nil, err
+	}
+	return f.typed(values), nil
+}
+
+func (f *Factory[T]) clone() *Factory[T] {
+	return &Factory[T]{db: f.db, seed: f.seed, states: append([]func(m *T){}, f.states...)}
+}
+
+func (f *Factory[T]) apply(v reflect.Value) {
+	m := v.Interface().(*T)
+	for _, state := range f.states {
+		state(m)
+	}
+}
+
+func (f *Factory[T]) builder(db *gorm.DB) *builder {
+	return &builder{db: db, seed: f.seed}
+}
+
+func (f *Factory[T]) typed(values []reflect.Value) []*T {
+	result := make([]*T, len(values))
+	for i, v := range values {
+		result[i] = v.Interface().(*T)
+	}
+	return result
+}
+
+// builder 按反射类型生成模型，关联的模型类型在编译期未知
+type builder struct {
+	db   *gorm.DB
+	seed uint64
+}
+
+func (b *builder) parse(typ reflect.Type) (*schema.Schema, error) {
+	var namer schema.Namer = schema.NamingStrategy{}
+	if b.db != nil {
+		namer = b.db.NamingStrategy
+	}
+	return schema.Parse(reflect.New(typ).Interface(), schemas, namer)
+}
+
+// make 生成 n 个模型（指针），填充假数据后执行 state
+func (b *builder) make(typ reflect.Type, n int, state func(v reflect.Value)) ([]reflect.Value, error) {
+	s, err := b.parse(typ)
+	if err != nil {
+		return nil, fmt.Errorf("parse model %s: %w", typ, err)
+	}
+	skip := make(map[string]bool)
+	for _, rel := range lookupRelations(typ) {
+		if field := s.LookUpField(rel.field); field != nil {
+			skip[field.Name] = true
+		}
+	}
+
+	values := make([]reflect.Value, n)
+	for i := range values {
+		seq := nextSequence(typ)
+		v := reflect.New(typ)
+		fill(v, s, NewFaker(b.seed*1000003+seq), seq, skip)
+		if state != nil {
+			state(v)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// create 生成并写入 n 个模型，外键为零值时先创建关联的模型
+func (b *builder) create(typ reflect.Type, n int, state func(v reflect.Value), depth int) ([]reflect.Value, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("factory relations of %s are nested too deep, check for circular relations", typ)
+	}
+	values, err := b.make(typ, n, state)
+	if err != nil || len(values) == 0 {
+		return values, err
+	}
+	s, err := b.parse(typ)
+	if err != nil {
+		return nil, err
+	}
+	ctx := b.db.Statement.Context
+
+	for _, rel := range lookupRelations(typ) {
+		field := s.LookUpField(rel.field)
+		if field == nil {
+			return nil, fmt.Errorf("factory relation field %s not found in %s", rel.field, typ)
+		}
+		for _, v := range values {
+			if _, zero := field.ValueOf(ctx, v.Elem()); !zero {
+				continue
+			}
+			parents, err := b.create(rel.parent, 1, nil, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			parentSchema, err := b.parse(rel.parent)
+			if err != nil {
+				return nil, err
+			}
+			if parentSchema.PrioritizedPrimaryField == nil {
+				return nil, fmt.Errorf("factory relation %s has no primary key", rel.parent)
+			}
+			id, _ := parentSchema.PrioritizedPrimaryField.ValueOf(ctx, parents[0].Elem())
+			if err := field.Set(ctx, v.Elem(), id); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	slice := reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(typ)), 0, n)
+	for _, v := range values {
+		slice = reflect.Append(slice, v)
+	}
+	ptr := reflect.New(slice.Type())
+	ptr.Elem().Set(slice)
+	if err := b.db.Create(ptr.Interface()).Error; err != nil {
+		return nil, fmt.Errorf("create %s: %w", typ, err)
+	}
+	return values, nil
+}
+
+func lookupRelations(typ reflect.Type) []*relation {
+	mu.RLock()
+	defer mu.RUnlock()
+	return relations[typ]
+}
+
+func nextSequence(typ reflect.Type) uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	sequences[typ]++
+	return sequences[typ]
+}
+
+func modelType(model any) reflect.Type {
+	typ := reflect.TypeOf(model)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	deletedAtType = reflect.TypeFor[gorm.DeletedAt]()
+)
+
+// fill 为零值字段填充假数据
+// 跳过主键、自动时间、软删除、租户、乐观锁版本、有默认值的列以及外键，这些列由数据库、插件或关联填充
+func fill(v reflect.Value, s *schema.Schema, fake *Faker, seq uint64, skip map[string]bool) {
+	ctx := context.Background()
+	unique := make(map[string]bool)
+	for _, index := range s.ParseIndexes() {
+		if index.Class == "UNIQUE" && len(index.Fields) == 1 {
+			unique[index.Fields[0].Name] = true
+		}
+	}
+	for _, field := range s.Fields {
+		if field.DBName == "" || !field.Creatable || field.PrimaryKey || skip[field.Name] ||
+			field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 || field.FieldType == deletedAtType {
+			continue
+		}
+		if _, ok := field.TagSettings[databases.TenantTag]; ok {
+			continue
+		}
+		if _, ok := field.TagSettings["VERSION"]; ok {
+			continue
+		}
+		if field.HasDefaultValue && field.DefaultValue != "" {
+			continue
+		}
+		if _, zero := field.ValueOf(ctx, v.Elem()); !zero {
+			continue
+		}
+		value := fakeValue(field, fake, seq, field.Unique || unique[field.Name])
+		if value == nil {
+			continue
+		}
+		_ = field.Set(ctx, v.Elem(), value)
+	}
+}
+
+func fakeValue(field *schema.Field, fake *Faker, seq uint64, unique bool) any {
+	typ := field.IndirectFieldType
+	switch typ.Kind() {
+	case reflect.String:
+		s := fakeString(strings.ToLower(field.DBName), fake, seq, unique)
+		if field.Size > 0 && utf8.RuneCountInString(s) > field.Size {
+			s = string([]rune(s)[:field.Size])
+		}
+		return s
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if unique {
+			return reflect.ValueOf(seq).Convert(typ).Interface()
+		}
+		return reflect.ValueOf(fake.Int(1, 100)).Convert(typ).Interface()
+	case reflect.Float32, reflect.Float64:
+		return reflect.ValueOf(fake.Float(1, 1000)).Convert(typ).Interface()
+	case reflect.Bool:
+		return fake.Bool()
+	}
+	if typ == timeType {
+		return fake.Time()
+	}
+	return nil
+}
+
+// fakeString 按列名生成字符串，唯一列追加记录序号
+func fakeString(name string, fake *Faker, seq uint64, unique bool) string {
+	switch {
+	case strings.Contains(name, "email"):
+		return strings.Replace(fake.Email(), "@", fmt.Sprintf("%d@", seq), 1)
+	case strings.Contains(name, "phone") || strings.Contains(name, "mobile"):
+		if unique {
+			return fmt.Sprintf("1%010d", seq)
+		}
+		return fake.Phone()
+	case strings.Contains(name, "url") || strings.Contains(name, "link") ||
+		strings.Contains(name, "avatar") || strings.Contains(name, "image"):
+		return fmt.Sprintf("%s/%d", fake.URL(), seq)
+	case name == "ip" || strings.HasSuffix(name, "_ip"):
+		return fake.IPv4()
+	case strings.Contains(name, "password"):
+		return "password"
+	case name == "username" || name == "user_name" || name == "account":
+		return fmt.Sprintf("%s%d", strings.ToLower(fake.FirstName()), seq)
+	}
+
+	var s string
+	switch {
+	case strings.Contains(name, "name"):
+		s = fake.Name()
+	case strings.Contains(name, "title") || strings.Contains(name, "subject"):
+		s = strings.TrimSuffix(fake.Sentence(), ".")
+	case strings.Contains(name, "description") || strings.Contains(name, "content") ||
+		strings.Contains(name, "body") || strings.Contains(name, "remark") ||
+		strings.Contains(name, "bio") || strings.Contains(name, "summary"):
+		s = fake.Paragraph()
+	default:
+		s = fake.Word()
+	}
+	if unique {
+		s = fmt.Sprintf("%s %d", s, seq)
+	}
+	return s
+}
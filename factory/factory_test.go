@@ -0,0 +1,164 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/light-speak/lighthouse/databases"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type factoryUser struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"type:varchar(20);not null"`
+	Email     string `gorm:"type:varchar(191);uniqueIndex"`
+	Nickname  *string
+	Age       int
+	Active    bool
+	Status    string `gorm:"default:'active'"`
+	CreatedAt time.Time
+}
+
+type factoryPost struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"index;not null"`
+	Title  string
+}
+
+func init() {
+	Register(&factoryPost{}, BelongsTo("UserID", &factoryUser{}))
+}
+
+type errSeeder struct{}
+
+var errSeedFailed = errors.New("failed")
+
+func (errSeeder) Name() string { return "ErrSeeder" }
+func (errSeeder) Run(ctx context.Context, db *gorm.DB) error {
+	if !databases.InTx(ctx) {
+		return errors.New("ctx should carry the seeder transaction")
+	}
+	if _, err := New[factoryUser]().Using(db).Create(ctx, 1); err != nil {
+		return err
+	}
+	// 不调用 Using 时通过 ctx 中的事务写入
+	if _, err := New[factoryUser]().Create(ctx, 1); err != nil {
+		return err
+	}
+	return errSeedFailed
+}
+
+func TestFactory(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&factoryUser{}, &factoryPost{}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	t.Run("Test Make Deterministic", func(t *testing.T) {
+		Reset()
+		first, err := New[factoryUser]().Make(3)
+		if err != nil {
+			t.Fatalf("Make() error = %v", err)
+		}
+		Reset()
+		second, _ := New[factoryUser]().Make(3)
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("Make() is not deterministic: %+v != %+v", first[0], second[0])
+		}
+		u := first[0]
+		if u.Name == "" || !strings.Contains(u.Email, "@example.com") || u.Nickname == nil || u.Age == 0 {
+			t.Errorf("Make() = %+v; expected fake values", u)
+		}
+		if len(u.Name) > 20 || u.Status != "" || !u.CreatedAt.IsZero() {
+			t.Errorf("Make() = %+v; expected size limit, default and auto time columns skipped", u)
+		}
+		if first[0].Email == first[1].Email {
+			t.Errorf("unique email %s repeated", first[0].Email)
+		}
+		Reset()
+		other, _ := New[factoryUser]().Seed(42).Make(1)
+		if other[0].Name == first[0].Name && other[0].Age == first[0].Age {
+			t.Error("Seed() should change fake values")
+		}
+	})
+
+	t.Run("Test Create With State", func(t *testing.T) {
+		users, err := New[factoryUser]().Using(db).With(func(u *factoryUser) { u.Name = "admin" }).Create(ctx, 2)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		var count int64
+		db.Model(&factoryUser{}).Where("name = ?", "admin").Count(&count)
+		if count != 2 || users[0].ID == 0 || users[0].Status != "active" {
+			t.Errorf("count = %d, user = %+v", count, users[0])
+		}
+	})
+
+	t.Run("Test Create Relations", func(t *testing.T) {
+		var before int64
+		db.Model(&factoryUser{}).Count(&before)
+		posts, err := New[factoryPost]().Using(db).Create(ctx, 2)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		var after int64
+		db.Model(&factoryUser{}).Count(&after)
+		if after-before != 2 || posts[0].UserID == 0 || posts[0].UserID == posts[1].UserID {
+			t.Errorf("users created = %d, posts = %+v %+v", after-before, posts[0], posts[1])
+		}
+
+		owner := posts[0].UserID
+		posts, err = New[factoryPost]().Using(db).With(func(p *factoryPost) { p.UserID = owner }).Create(ctx, 1)
+		if err != nil || posts[0].UserID != owner {
+			t.Errorf("Create() = %+v, %v; expected owner %d", posts, err, owner)
+		}
+	})
+}
+
+func TestRunSeeders(t *testing.T) {
+	cfg := &databases.DatabaseConfig{Driver: databases.DriverSQLite, Name: filepath.Join(t.TempDir(), "seed.db"), LogLevel: logger.Silent}
+	if err := databases.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer databases.LightDatabaseClient.CloseConnections()
+	ctx := context.Background()
+	db, err := databases.LightDatabaseClient.GetDB(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&factoryUser{}); err != nil {
+		t.Fatal(err)
+	}
+	AddSeeder(errSeeder{})
+
+	t.Run("Test Failed Seeder Rolls Back", func(t *testing.T) {
+		if err := RunSeeders(ctx, "ErrSeeder"); !errors.Is(err, errSeedFailed) {
+			t.Errorf("RunSeeders() error = %v; expected %v", err, errSeedFailed)
+		}
+		var count int64
+		db.Model(&factoryUser{}).Count(&count)
+		if count != 0 {
+			t.Errorf("failed seeder should be rolled back, %d users left", count)
+		}
+	})
+
+	t.Run("Test Missing Seeder", func(t *testing.T) {
+		if err := RunSeeders(ctx, "Missing"); err == nil {
+			t.Error("RunSeeders() expected error for missing seeder")
+		}
+	})
+
+}
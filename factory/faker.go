@@ -0,0 +1,132 @@
+package factory
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+var (
+	firstNames = []string{
+		"James", "Mary", "John", "Linda", "Robert", "Emma", "Michael", "Olivia", "William", "Sophia",
+		"David", "Ava", "Daniel", "Mia", "Joseph", "Grace", "Thomas", "Chloe", "Henry", "Lucy",
+	}
+	lastNames = []string{
+		"Smith", "Johnson", "Brown", "Taylor", "Miller", "Wilson", "Moore", "Clark", "Lewis", "Walker",
+		"Hall", "Young", "King", "Wright", "Scott", "Green", "Baker", "Adams", "Nelson", "Carter",
+	}
+	words = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+		"minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip", "commodo",
+	}
+	// baseTime Time 生成的时间在 baseTime 之前一年内，不使用当前时间以保证结果可复现
+	baseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Faker 假数据生成器，相同的种子生成相同的数据
+type Faker struct {
+	r *rand.Rand
+}
+
+// NewFaker 创建假数据生成器
+func NewFaker(seed uint64) *Faker {
+	return &Faker{r: rand.New(rand.NewPCG(seed, 0x9e3779b97f4a7c15))}
+}
+
+// Int 返回 [min, max] 之间的整数
+func (f *Faker) Int(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + f.r.IntN(max-min+1)
+}
+
+// Float 返回 [min, max) 之间保留两位小数的浮点数
+func (f *Faker) Float(min, max float64) float64 {
+	v := min + f.r.Float64()*(max-min)
+	return float64(int64(v*100)) / 100
+}
+
+// Bool 返回随机布尔值
+func (f *Faker) Bool() bool {
+	return f.r.IntN(2) == 1
+}
+
+// Pick 从 values 中随机选择一个
+func (f *Faker) Pick(values ...string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[f.r.IntN(len(values))]
+}
+
+// FirstName 名
+func (f *Faker) FirstName() string {
+	return f.Pick(firstNames...)
+}
+
+// LastName 姓
+func (f *Faker) LastName() string {
+	return f.Pick(lastNames...)
+}
+
+// Name 姓名，如 Mary Smith
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Email 邮箱，如 mary.smith@example.com
+func (f *Faker) Email() string {
+	return strings.ToLower(f.FirstName()+"."+f.LastName()) + "@example.com"
+}
+
+// Phone 11 位手机号
+func (f *Faker) Phone() string {
+	return fmt.Sprintf("1%s%09d", f.Pick("3", "5", "7", "8", "9"), f.r.IntN(1000000000))
+}
+
+// URL 链接
+func (f *Faker) URL() string {
+	return "https://example.com/" + f.Word()
+}
+
+// IPv4 IP 地址
+func (f *Faker) IPv4() string {
+	return fmt.Sprintf("%d.%d.%d.%d", f.Int(1, 223), f.Int(0, 255), f.Int(0, 255), f.Int(1, 254))
+}
+
+// Word 单词
+func (f *Faker) Word() string {
+	return f.Pick(words...)
+}
+
+// Words n 个单词，以空格分隔
+func (f *Faker) Words(n int) string {
+	result := make([]string, n)
+	for i := range result {
+		result[i] = f.Word()
+	}
+	return strings.Join(result, " ")
+}
+
+// Sentence 首字母大写、以句号结尾的句子
+func (f *Faker) Sentence() string {
+	s := f.Words(f.Int(4, 10))
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
+
+// Paragraph 段落
+func (f *Faker) Paragraph() string {
+	sentences := make([]string, f.Int(2, 4))
+	for i := range sentences {
+		sentences[i] = f.Sentence()
+	}
+	return strings.Join(sentences, " ")
+}
+
+// Time 2023 年内的时间
+func (f *Faker) Time() time.Time {
+	return baseTime.Add(-time.Duration(f.r.Int64N(int64(365 * 24 * time.Hour)))).Truncate(time.Second)
+}
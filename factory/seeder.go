@@ -0,0 +1,73 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/light-speak/lighthouse/databases"
+	"github.com/light-speak/lighthouse/logs"
+	"gorm.io/gorm"
+)
+
+// Seeder 数据填充，由 generate:seeder 在项目的 seeders 目录生成，db:seed 命令执行
+type Seeder interface {
+	Name() string
+	Run(ctx context.Context, db *gorm.DB) error
+}
+
+var (
+	seedersMu sync.RWMutex
+	seeders   = make([]Seeder, 0)
+)
+
+// AddSeeder 登记 Seeder，按登记顺序执行
+func AddSeeder(seeder Seeder) {
+	seedersMu.Lock()
+	defer seedersMu.Unlock()
+	seeders = append(seeders, seeder)
+}
+
+// Seeders 返回登记的 Seeder
+func Seeders() []Seeder {
+	seedersMu.RLock()
+	defer seedersMu.RUnlock()
+	return append([]Seeder(nil), seeders...)
+}
+
+// RunSeeders 按登记顺序执行 Seeder，names 不为空时只执行指定的 Seeder
+// 每个 Seeder 通过 databases.WithTx 在默认连接的事务中执行，传给 Run 的 ctx 携带该事务，
+// Seeder 内通过 GetDB(ctx) 或不调用 Using 的 factory 写入的数据也在事务中，失败时回滚该 Seeder 写入的数据并停止
+func RunSeeders(ctx context.Context, names ...string) error {
+	all := Seeders()
+	selected := all
+	if len(names) > 0 {
+		byName := make(map[string]Seeder, len(all))
+		for _, seeder := range all {
+			byName[seeder.Name()] = seeder
+		}
+		selected = make([]Seeder, 0, len(names))
+		for _, name := range names {
+			seeder, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("seeder %s not found", name)
+			}
+			selected = append(selected, seeder)
+		}
+	}
+
+	for _, seeder := range selected {
+		logs.Info().Str("seeder", seeder.Name()).Msg("seeding")
+		err := databases.WithTx(ctx, func(ctx context.Context) error {
+			tx, err := databases.LightDatabaseClient.GetDB(ctx)
+			if err != nil {
+				return err
+			}
+			return seeder.Run(ctx, tx)
+		})
+		if err != nil {
+			return fmt.Errorf("seeder %s: %w", seeder.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Code generated by github.com/light-speak/lighthouse, YOU CAN FUCKING EDIT BY YOURSELF.
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/light-speak/lighthouse/config"
+
+	// 注册各组件的配置
+	_ "github.com/light-speak/lighthouse/lighthouse"
+	_ "github.com/light-speak/lighthouse/routers"
+)
+
+type ConfigShow struct{}
+
+func (c *ConfigShow) Name() string {
+	// Func:Name user code start. Do not remove this comment.
+	return "config:show"
+	// Func:Name user code end. Do not remove this comment.
+}
+
+func (c *ConfigShow) Usage() string {
+	// Func:Usage user code start. Do not remove this comment.
+	return "print the effective configuration, secrets are redacted"
+	// Func:Usage user code end. Do not remove this comment.
+}
+
+func (c *ConfigShow) Args() []*CommandArg {
+	return []*CommandArg{
+		// Func:Args user code start. Do not remove this comment.
+		{
+			Name:  "section",
+			Type:  String,
+			Usage: "only print the given sections, split by [,], like: database,redis",
+		},
+		// Func:Args user code end. Do not remove this comment.
+	}
+}
+
+func (c *ConfigShow) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		// Func:Action user code start. Do not remove this comment.
+		args, err := GetArgs(c.Args(), flagValues)
+		if err != nil {
+			return err
+		}
+
+		var sections []string
+		if section, err := GetStringArg(args, "section"); err == nil && *section != "" {
+			sections = strings.Split(*section, ",")
+		}
+		return config.Print(os.Stdout, sections...)
+		// Func:Action user code end. Do not remove this comment.
+	}
+}
+
+func (c *ConfigShow) OnExit() func() {
+	return func() {
+		// Func:OnExit user code start. Do not remove this comment.
+		// Func:OnExit user code end. Do not remove this comment.
+	}
+}
+
+func init() {
+	AddCommand(&ConfigShow{})
+}
+
+// Section: user code section start. Do not remove this comment.
+// Section: user code section end. Do not remove this comment.
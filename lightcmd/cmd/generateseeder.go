@@ -0,0 +1,73 @@
+// Code generated by github.com/light-speak/lighthouse, YOU CAN FUCKING EDIT BY YOURSELF.
+package cmd
+
+import "github.com/light-speak/lighthouse/lightcmd/seeder"
+
+type GenerateSeeder struct{}
+
+func (c *GenerateSeeder) Name() string {
+	// Func:Name user code start. Do not remove this comment.
+	return "generate:seeder"
+	// Func:Name user code end. Do not remove this comment.
+}
+
+func (c *GenerateSeeder) Usage() string {
+	// Func:Usage user code start. Do not remove this comment.
+	return "generate seeder and db:seed command in project"
+	// Func:Usage user code end. Do not remove this comment.
+}
+
+func (c *GenerateSeeder) Args() []*CommandArg {
+	return []*CommandArg{
+		// Func:Args user code start. Do not remove this comment.
+		{
+			Name:     "name",
+			Type:     String,
+			Required: true,
+			Usage:    "seeder name, like: User",
+		},
+		{
+			Name:    "model",
+			Type:    String,
+			Default: "",
+			Usage:   "model created by the seeder with factory, like: User",
+		},
+		// Func:Args user code end. Do not remove this comment.
+	}
+}
+
+func (c *GenerateSeeder) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		// Func:Action user code start. Do not remove this comment.
+		args, err := GetArgs(c.Args(), flagValues)
+		if err != nil {
+			return err
+		}
+
+		name, err := GetStringArg(args, "name")
+		if err != nil {
+			return err
+		}
+		model := ""
+		if m, err := GetStringArg(args, "model"); err == nil {
+			model = *m
+		}
+
+		return seeder.GenSeeder(*name, model)
+		// Func:Action user code end. Do not remove this comment.
+	}
+}
+
+func (c *GenerateSeeder) OnExit() func() {
+	return func() {
+		// Func:OnExit user code start. Do not remove this comment.
+		// Func:OnExit user code end. Do not remove this comment.
+	}
+}
+
+func init() {
+	AddCommand(&GenerateSeeder{})
+}
+
+// Section: user code section start. Do not remove this comment.
+// Section: user code section end. Do not remove this comment.
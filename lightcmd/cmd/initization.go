@@ -34,6 +34,12 @@ func (c *Init) Args() []*CommandArg {
 			Required: true,
 			Usage:    "project models split by [,], like: user,post,comment",
 		},
+		{
+			Name:    "driver",
+			Type:    String,
+			Default: "mysql",
+			Usage:   "database driver: mysql | postgres | sqlite",
+		},
 		// Func:Args user code end. Do not remove this comment.
 	}
 }
@@ -56,7 +62,12 @@ func (c *Init) Action() func(flagValues map[string]interface{}) error {
 			return err
 		}
 
-		err = initization.Run(*module, *models)
+		driver, err := GetStringArg(args, "driver")
+		if err != nil {
+			return err
+		}
+
+		err = initization.Run(*module, *models, *driver)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,47 @@
+// Code generated by github.com/light-speak/lighthouse, YOU CAN FUCKING EDIT BY YOURSELF.
+package cmd
+
+import "github.com/light-speak/lighthouse/lightcmd/migrate"
+
+type InitMigrate struct{}
+
+func (c *InitMigrate) Name() string {
+	// Func:Name user code start. Do not remove this comment.
+	return "migrate:init"
+	// Func:Name user code end. Do not remove this comment.
+}
+
+func (c *InitMigrate) Usage() string {
+	// Func:Usage user code start. Do not remove this comment.
+	return "install migrate commands in project"
+	// Func:Usage user code end. Do not remove this comment.
+}
+
+func (c *InitMigrate) Args() []*CommandArg {
+	return []*CommandArg{
+		// Func:Args user code start. Do not remove this comment.
+		// Func:Args user code end. Do not remove this comment.
+	}
+}
+
+func (c *InitMigrate) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		// Func:Action user code start. Do not remove this comment.
+		return migrate.InitMigrate()
+		// Func:Action user code end. Do not remove this comment.
+	}
+}
+
+func (c *InitMigrate) OnExit() func() {
+	return func() {
+		// Func:OnExit user code start. Do not remove this comment.
+		// Func:OnExit user code end. Do not remove this comment.
+	}
+}
+
+func init() {
+	AddCommand(&InitMigrate{})
+}
+
+// Section: user code section start. Do not remove this comment.
+// Section: user code section end. Do not remove this comment.
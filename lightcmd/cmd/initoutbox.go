@@ -0,0 +1,47 @@
+// Code generated by github.com/light-speak/lighthouse, YOU CAN FUCKING EDIT BY YOURSELF.
+package cmd
+
+import "github.com/light-speak/lighthouse/lightcmd/outbox"
+
+type InitOutbox struct{}
+
+func (c *InitOutbox) Name() string {
+	// Func:Name user code start. Do not remove this comment.
+	return "outbox:init"
+	// Func:Name user code end. Do not remove this comment.
+}
+
+func (c *InitOutbox) Usage() string {
+	// Func:Usage user code start. Do not remove this comment.
+	return "install outbox relay command in project"
+	// Func:Usage user code end. Do not remove this comment.
+}
+
+func (c *InitOutbox) Args() []*CommandArg {
+	return []*CommandArg{
+		// Func:Args user code start. Do not remove this comment.
+		// Func:Args user code end. Do not remove this comment.
+	}
+}
+
+func (c *InitOutbox) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		// Func:Action user code start. Do not remove this comment.
+		return outbox.InitOutbox()
+		// Func:Action user code end. Do not remove this comment.
+	}
+}
+
+func (c *InitOutbox) OnExit() func() {
+	return func() {
+		// Func:OnExit user code start. Do not remove this comment.
+		// Func:OnExit user code end. Do not remove this comment.
+	}
+}
+
+func init() {
+	AddCommand(&InitOutbox{})
+}
+
+// Section: user code section start. Do not remove this comment.
+// Section: user code section end. Do not remove this comment.
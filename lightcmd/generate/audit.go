@@ -0,0 +1,25 @@
+package generate
+
+import "github.com/vektah/gqlparser/v2/ast"
+
+const auditedDirective = "audited"
+
+// auditedTypes 使用 @audited 的类型及其不记录的字段，用于生成 audit.Register
+var auditedTypes = make(map[string][]string)
+
+// collectAudited 记录 @audited 类型，exclude 为不写入审计日志的字段，如密码
+func collectAudited(td *ast.Definition) {
+	directive := td.Directives.ForName(auditedDirective)
+	if directive == nil {
+		return
+	}
+	if _, exists := auditedTypes[td.Name]; exists {
+		return
+	}
+	auditedTypes[td.Name] = make([]string, 0)
+	if exclude := directive.Arguments.ForName("exclude"); exclude != nil && exclude.Value != nil {
+		for _, field := range exclude.Value.Children {
+			auditedTypes[td.Name] = append(auditedTypes[td.Name], field.Value.Raw)
+		}
+	}
+}
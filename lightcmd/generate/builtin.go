@@ -0,0 +1,81 @@
+package generate
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const builtinSource = "lighthouse_directives.graphqls"
+
+// builtinDirectives 只在生成时使用的指令，schema 中没有定义时自动补充，旧项目升级后无需手动修改 schema
+var builtinDirectives = map[string]string{
+	"audited": "directive @audited(exclude: [String!]) on OBJECT",
+	"version": "directive @version on FIELD_DEFINITION",
+	"tenant":  `directive @tenant(field: String = "tenantId") on OBJECT`,
+}
+
+// builtinPlugin 补充缺失的 lighthouse 指令定义，并把这些指令设置为 skip_runtime
+type builtinPlugin struct {
+	cfg *config.Config
+}
+
+func newBuiltinPlugin(cfg *config.Config) *builtinPlugin {
+	return &builtinPlugin{cfg: cfg}
+}
+
+func (p *builtinPlugin) Name() string {
+	return "lighthouse-directives"
+}
+
+func (p *builtinPlugin) InjectSourcesEarly() ([]*ast.Source, error) {
+	defined := make(map[string]bool)
+	dir := ""
+	for _, src := range p.cfg.Sources {
+		if src.BuiltIn {
+			continue
+		}
+		doc, err := parser.ParseSchema(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, def := range doc.Directives {
+			defined[def.Name] = true
+		}
+		if dir == "" {
+			dir = filepath.Dir(src.Name)
+		}
+	}
+
+	if p.cfg.Directives == nil {
+		p.cfg.Directives = map[string]config.DirectiveConfig{}
+	}
+	missing := make([]string, 0)
+	for name, definition := range builtinDirectives {
+		p.cfg.Directives[name] = config.DirectiveConfig{SkipRuntime: true}
+		if !defined[name] {
+			missing = append(missing, definition)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	sort.Strings(missing)
+
+	src := &ast.Source{
+		Name:  filepath.ToSlash(filepath.Join(dir, builtinSource)),
+		Input: "# Code generated by lighthouse, DO NOT EDIT.\n\n" + strings.Join(missing, "\n") + "\n",
+	}
+	markRewritten(src)
+	return []*ast.Source{src}, nil
+}
+
+func (p *builtinPlugin) GenerateCode(data *codegen.Data) error {
+	inlineRewritten(data)
+	return nil
+}
@@ -0,0 +1,86 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect 生成 gorm tag 时的目标数据库，由项目 .env 中的 DB_DRIVER 决定
+type Dialect string
+
+const (
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// ColumnKind 与数据库无关的列类型
+type ColumnKind int
+
+const (
+	ColumnID ColumnKind = iota
+	ColumnDatetime
+	ColumnVarchar
+	ColumnText
+	ColumnLongText
+)
+
+var dialect = DialectMySQL
+
+// SetDialect 设置目标数据库，支持 mysql | postgres | sqlite
+func SetDialect(name string) error {
+	switch strings.ToLower(name) {
+	case "", "mysql":
+		dialect = DialectMySQL
+	case "postgres", "postgresql", "pgsql":
+		dialect = DialectPostgres
+	case "sqlite", "sqlite3":
+		dialect = DialectSQLite
+	default:
+		return fmt.Errorf("unsupported database driver: %s", name)
+	}
+	return nil
+}
+
+// CurrentDialect 返回当前的目标数据库
+func CurrentDialect() Dialect {
+	return dialect
+}
+
+// TypeTag 返回列类型在当前数据库下的 gorm tag，如 type:datetime
+// 返回空字符串表示交给 gorm 按 Go 类型推断
+// ColumnVarchar 可以传入长度，默认 255
+func TypeTag(kind ColumnKind, args ...string) string {
+	columnType := ""
+	switch kind {
+	case ColumnID:
+		// PostgreSQL / SQLite 由 gorm 根据 auto_increment 推断为 bigserial / integer
+		if dialect == DialectMySQL {
+			columnType = "int unsigned"
+		}
+	case ColumnDatetime:
+		if dialect == DialectPostgres {
+			columnType = "timestamptz"
+		} else {
+			columnType = "datetime"
+		}
+	case ColumnVarchar:
+		length := "255"
+		if len(args) > 0 && args[0] != "" {
+			length = args[0]
+		}
+		columnType = "varchar(" + length + ")"
+	case ColumnText:
+		columnType = "text"
+	case ColumnLongText:
+		if dialect == DialectMySQL {
+			columnType = "longtext"
+		} else {
+			columnType = "text"
+		}
+	}
+	if columnType == "" {
+		return ""
+	}
+	return "type:" + columnType
+}
@@ -0,0 +1,44 @@
+package generate
+
+import "testing"
+
+func TestTypeTag(t *testing.T) {
+	defer SetDialect("mysql")
+
+	tests := []struct {
+		dialect  string
+		kind     ColumnKind
+		args     []string
+		expected string
+	}{
+		{"mysql", ColumnID, nil, "type:int unsigned"},
+		{"postgres", ColumnID, nil, ""},
+		{"sqlite", ColumnID, nil, ""},
+		{"mysql", ColumnDatetime, nil, "type:datetime"},
+		{"postgres", ColumnDatetime, nil, "type:timestamptz"},
+		{"sqlite", ColumnDatetime, nil, "type:datetime"},
+		{"mysql", ColumnLongText, nil, "type:longtext"},
+		{"postgres", ColumnLongText, nil, "type:text"},
+		{"sqlite", ColumnText, nil, "type:text"},
+		{"mysql", ColumnVarchar, nil, "type:varchar(255)"},
+		{"postgres", ColumnVarchar, []string{"64"}, "type:varchar(64)"},
+	}
+
+	for _, test := range tests {
+		t.Run("Test TypeTag "+test.dialect+" "+test.expected, func(t *testing.T) {
+			if err := SetDialect(test.dialect); err != nil {
+				t.Fatalf("SetDialect(%q) error: %v", test.dialect, err)
+			}
+			result := TypeTag(test.kind, test.args...)
+			if result != test.expected {
+				t.Errorf("TypeTag(%d, %v) on %s = %q; expected %q", test.kind, test.args, test.dialect, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestSetDialectUnsupported(t *testing.T) {
+	if err := SetDialect("oracle"); err == nil {
+		t.Errorf("SetDialect(%q) expected error", "oracle")
+	}
+}
@@ -10,6 +10,6 @@ func init() {
 }
 
 func longtext(directive *ast.Directive, logic *generate.DirectiveLogic) (*generate.DirectiveLogic, error) {
-	logic.TagKvs["gorm"] = append(logic.TagKvs["gorm"], `type:longtext`)
+	logic.TagKvs["gorm"] = append(logic.TagKvs["gorm"], generate.TypeTag(generate.ColumnLongText))
 	return logic, nil
 }
@@ -10,6 +10,6 @@ func init() {
 }
 
 func text(directive *ast.Directive, logic *generate.DirectiveLogic) (*generate.DirectiveLogic, error) {
-	logic.TagKvs["gorm"] = append(logic.TagKvs["gorm"], `type:text`)
+	logic.TagKvs["gorm"] = append(logic.TagKvs["gorm"], generate.TypeTag(generate.ColumnText))
 	return logic, nil
 }
@@ -1,8 +1,6 @@
 package directives
 
 import (
-	"fmt"
-
 	"github.com/light-speak/lighthouse/lightcmd/generate"
 	"github.com/vektah/gqlparser/v2/ast"
 )
@@ -13,9 +11,9 @@ func init() {
 
 func varchar(directive *ast.Directive, logic *generate.DirectiveLogic) (*generate.DirectiveLogic, error) {
 	if nameArg := directive.Arguments.ForName("length"); nameArg != nil && nameArg.Value != nil {
-		logic.TagKvs["gorm"] = append(logic.TagKvs["gorm"], fmt.Sprintf(`type:varchar(%s)`, nameArg.Value.String()))
+		logic.TagKvs["gorm"] = append(logic.TagKvs["gorm"], generate.TypeTag(generate.ColumnVarchar, nameArg.Value.String()))
 	} else {
-		logic.TagKvs["gorm"] = append(logic.TagKvs["gorm"], `type:varchar(255)`)
+		logic.TagKvs["gorm"] = append(logic.TagKvs["gorm"], generate.TypeTag(generate.ColumnVarchar))
 	}
 	return logic, nil
 }
@@ -0,0 +1,16 @@
+package directives
+
+import (
+	"github.com/light-speak/lighthouse/lightcmd/generate"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func init() {
+	generate.AddDirective("version", version)
+}
+
+// version 乐观锁版本列，databases.UpdateWithVersion 通过 version tag 找到该列
+func version(directive *ast.Directive, logic *generate.DirectiveLogic) (*generate.DirectiveLogic, error) {
+	logic.TagKvs["gorm"] = append(logic.TagKvs["gorm"], `version`, `default:0`)
+	return logic, nil
+}
@@ -0,0 +1,47 @@
+package generate
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/light-speak/lighthouse/utils"
+)
+
+// factoryRelation @loader 外键对应的关联，如 Post 的 userId 指向 User
+type factoryRelation struct {
+	Model  string
+	Field  string
+	Parent string
+}
+
+// factoryRelations 从 @loader 的 keys 和 extraKeys 中找出 xxxId 外键，xxx 是带 id 字段的类型时生成关联，
+// factory 创建模型时外键为零值会先创建关联的模型
+func factoryRelations() []*factoryRelation {
+	result := make([]*factoryRelation, 0)
+	seen := make(map[string]bool)
+	for _, source := range []map[string][]string{loaderTypeToFieldsMap, loaderTypeExtraKeysMap} {
+		for model, keys := range source {
+			if !migrateTypes[model] {
+				continue
+			}
+			for _, key := range keys {
+				if key == "id" || !strings.HasSuffix(key, "Id") {
+					continue
+				}
+				parent := utils.UcFirst(strings.TrimSuffix(key, "Id"))
+				if !migrateTypes[parent] || seen[model+"."+key] {
+					continue
+				}
+				seen[model+"."+key] = true
+				result = append(result, &factoryRelation{Model: model, Field: utils.SnakeCase(key), Parent: parent})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Model != result[j].Model {
+			return result[i].Model < result[j].Model
+		}
+		return result[i].Field < result[j].Field
+	})
+	return result
+}
@@ -0,0 +1,32 @@
+package generate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFactoryRelations(t *testing.T) {
+	migrateTypes = map[string]bool{"User": true, "Chat": true, "Post": true, "ChatUser": true}
+	loaderTypeToFieldsMap = map[string][]string{
+		"User":     {"id"},
+		"ChatUser": {"chatId", "userId"},
+	}
+	loaderTypeExtraKeysMap = map[string][]string{
+		"Post":     {"userId", "categoryId"},
+		"ChatUser": {"userId"},
+	}
+	t.Cleanup(func() {
+		migrateTypes = make(map[string]bool)
+		loaderTypeToFieldsMap = make(map[string][]string)
+		loaderTypeExtraKeysMap = make(map[string][]string)
+	})
+
+	expected := []*factoryRelation{
+		{Model: "ChatUser", Field: "chat_id", Parent: "Chat"},
+		{Model: "ChatUser", Field: "user_id", Parent: "User"},
+		{Model: "Post", Field: "user_id", Parent: "User"},
+	}
+	if actual := factoryRelations(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("factoryRelations() = %+v; expected %+v", actual, expected)
+	}
+}
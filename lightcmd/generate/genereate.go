@@ -6,11 +6,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/99designs/gqlgen/api"
 	"github.com/99designs/gqlgen/codegen/config"
 	"github.com/99designs/gqlgen/plugin/modelgen"
+	lightconfig "github.com/light-speak/lighthouse/config"
 	"github.com/light-speak/lighthouse/logs"
 	"github.com/light-speak/lighthouse/templates"
 	"github.com/vektah/gqlparser/v2/ast"
@@ -40,13 +42,27 @@ func GenerateSchema() error {
 	}
 	logs.Info().Msgf("Config loaded from: %s", cfg.SchemaFilename)
 
+	// gorm tag 按项目使用的数据库生成
+	driver, ok := lightconfig.Lookup("DB_DRIVER")
+	if !ok {
+		driver = string(DialectMySQL)
+	}
+	if err := SetDialect(driver); err != nil {
+		return err
+	}
+	logs.Info().Msgf("Database dialect: %s", CurrentDialect())
+
 	// Generate schema
 	logs.Info().Msg("Generating GraphQL schema and models...")
 	p := &modelgen.Plugin{
 		FieldHook: fieldHook,
 	}
 
-	err = api.Generate(cfg, api.ReplacePlugin(p))
+	err = api.Generate(cfg, api.ReplacePlugin(p),
+		api.AddPlugin(newBuiltinPlugin(cfg)),
+		api.AddPlugin(newSoftDeletesPlugin(cfg)),
+		api.AddPlugin(newPaginatePlugin(cfg)),
+	)
 	if err != nil {
 		logs.Error().Msgf("Failed to generate schema: %+v", err)
 		return fmt.Errorf("failed to generate schema: %w", err)
@@ -73,6 +89,33 @@ func GenerateSchema() error {
 	}
 	logs.Info().Msg("DataLoader generated successfully")
 
+	// Generate audit registrations
+	if len(auditedTypes) > 0 {
+		logs.Info().Msgf("Generating audit registrations for %d models...", len(auditedTypes))
+		if err := generateAudit(); err != nil {
+			logs.Error().Msgf("Failed to generate audit registrations: %v", err)
+			return fmt.Errorf("failed to generate audit registrations: %w", err)
+		}
+	}
+
+	// Generate migrate registrations
+	if len(migrateTypes) > 0 {
+		logs.Info().Msgf("Generating migrate registrations for %d models...", len(migrateTypes))
+		if err := generateMigrate(); err != nil {
+			logs.Error().Msgf("Failed to generate migrate registrations: %v", err)
+			return fmt.Errorf("failed to generate migrate registrations: %w", err)
+		}
+	}
+
+	// Generate factory relations
+	if relations := factoryRelations(); len(relations) > 0 {
+		logs.Info().Msgf("Generating factory relations for %d foreign keys...", len(relations))
+		if err := generateFactory(relations); err != nil {
+			logs.Error().Msgf("Failed to generate factory relations: %v", err)
+			return fmt.Errorf("failed to generate factory relations: %w", err)
+		}
+	}
+
 	// Run go mod tidy
 	logs.Info().Msg("Running go mod tidy...")
 	cmd := exec.Command("go", "mod", "tidy")
@@ -160,6 +203,177 @@ func generateLoader() error {
 	return nil
 }
 
+func generatePaginate() error {
+	paginateTpl, err := tpl.ReadFile("tpl/paginate.tpl")
+	if err != nil {
+		return fmt.Errorf("failed to read paginate template: %w", err)
+	}
+
+	curPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	nodes := make([]string, 0, len(paginateTypes))
+	for name := range paginateTypes {
+		nodes = append(nodes, name)
+	}
+	sort.Strings(nodes)
+
+	options := &templates.Options{
+		Path:         filepath.Join(curPath, "models"),
+		Template:     string(paginateTpl),
+		FileName:     "paginate_gen",
+		Package:      "models",
+		FileExt:      "go",
+		Editable:     false,
+		SkipIfExists: false,
+		Data: map[string]any{
+			"Nodes": nodes,
+		},
+	}
+	templates.AddImportRegex("databases", "github.com/light-speak/lighthouse/databases", "")
+
+	return templates.Render(options)
+}
+
+func generateSoftDeletes() error {
+	softDeletesTpl, err := tpl.ReadFile("tpl/softdeletes.tpl")
+	if err != nil {
+		return fmt.Errorf("failed to read soft deletes template: %w", err)
+	}
+
+	curPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	nodes := make([]string, 0, len(softDeleteTypes))
+	for name := range softDeleteTypes {
+		nodes = append(nodes, name)
+	}
+	sort.Strings(nodes)
+
+	options := &templates.Options{
+		Path:         filepath.Join(curPath, "models"),
+		Template:     string(softDeletesTpl),
+		FileName:     "softdeletes_gen",
+		Package:      "models",
+		FileExt:      "go",
+		Editable:     false,
+		SkipIfExists: false,
+		Data: map[string]any{
+			"Nodes": nodes,
+		},
+	}
+	templates.AddImportRegex("context", "context", "")
+	templates.AddImportRegex("databases", "github.com/light-speak/lighthouse/databases", "")
+
+	return templates.Render(options)
+}
+
+type auditedNode struct {
+	Name    string
+	Exclude []string
+}
+
+func generateAudit() error {
+	auditTpl, err := tpl.ReadFile("tpl/audit.tpl")
+	if err != nil {
+		return fmt.Errorf("failed to read audit template: %w", err)
+	}
+
+	curPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	nodes := make([]*auditedNode, 0, len(auditedTypes))
+	for name, exclude := range auditedTypes {
+		nodes = append(nodes, &auditedNode{Name: name, Exclude: exclude})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	options := &templates.Options{
+		Path:         filepath.Join(curPath, "models"),
+		Template:     string(auditTpl),
+		FileName:     "audit_gen",
+		Package:      "models",
+		FileExt:      "go",
+		Editable:     false,
+		SkipIfExists: false,
+		Data: map[string]any{
+			"Nodes": nodes,
+		},
+	}
+	templates.AddImportRegex("audit", "github.com/light-speak/lighthouse/audit", "")
+	templates.AddImportRegex(`(^|[^A-Za-z])migrate\.`, "github.com/light-speak/lighthouse/migrate", "")
+
+	return templates.Render(options)
+}
+
+func generateMigrate() error {
+	migrateTpl, err := tpl.ReadFile("tpl/migrate.tpl")
+	if err != nil {
+		return fmt.Errorf("failed to read migrate template: %w", err)
+	}
+
+	curPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	names := make([]string, 0, len(migrateTypes))
+	for name := range migrateTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	options := &templates.Options{
+		Path:         filepath.Join(curPath, "models"),
+		Template:     string(migrateTpl),
+		FileName:     "migrate_gen",
+		Package:      "models",
+		FileExt:      "go",
+		Editable:     false,
+		SkipIfExists: false,
+		Data: map[string]any{
+			"Names": names,
+		},
+	}
+	templates.AddImportRegex(`(^|[^A-Za-z])migrate\.`, "github.com/light-speak/lighthouse/migrate", "")
+
+	return templates.Render(options)
+}
+
+func generateFactory(relations []*factoryRelation) error {
+	factoryTpl, err := tpl.ReadFile("tpl/factory.tpl")
+	if err != nil {
+		return fmt.Errorf("failed to read factory template: %w", err)
+	}
+
+	curPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	options := &templates.Options{
+		Path:         filepath.Join(curPath, "models"),
+		Template:     string(factoryTpl),
+		FileName:     "factory_gen",
+		Package:      "models",
+		FileExt:      "go",
+		Editable:     false,
+		SkipIfExists: false,
+		Data: map[string]any{
+			"Relations": relations,
+		},
+	}
+	templates.AddImportRegex(`(^|[^A-Za-z])factory\.`, "github.com/light-speak/lighthouse/factory", "")
+
+	return templates.Render(options)
+}
+
 type LoaderField struct {
 	Field string
 	Union []string
@@ -170,6 +384,11 @@ var loaderTypeToFieldsMap = make(map[string][]string)
 var loaderTypeExtraKeysMap = make(map[string][]string)
 
 func fieldHook(td *ast.Definition, fd *ast.FieldDefinition, f *modelgen.Field) (*modelgen.Field, error) {
+	if err := validateOwn(td, fd); err != nil {
+		return nil, err
+	}
+	collectAudited(td)
+	collectMigrate(td, fd)
 	if loaderDirective := td.Directives.ForName("loader"); loaderDirective != nil {
 		if _, exists := loaderTypeToFieldsMap[td.Name]; !exists {
 			loaderTypeToFieldsMap[td.Name] = make([]string, 0)
@@ -251,17 +470,22 @@ func fieldLogic(td *ast.Definition, fd *ast.FieldDefinition, f *modelgen.Field)
 
 	switch fd.Name {
 	case "id":
-		tagKvs["gorm"] = append(tagKvs["gorm"], `type:int unsigned`)
+		if typeTag := TypeTag(ColumnID); typeTag != "" {
+			tagKvs["gorm"] = append(tagKvs["gorm"], typeTag)
+		}
 		tagKvs["gorm"] = append(tagKvs["gorm"], `primary_key`)
 		tagKvs["gorm"] = append(tagKvs["gorm"], `auto_increment`)
 	case "createdAt":
-		tagKvs["gorm"] = append(tagKvs["gorm"], `type:datetime`)
+		tagKvs["gorm"] = append(tagKvs["gorm"], TypeTag(ColumnDatetime))
 	case "updatedAt":
-		tagKvs["gorm"] = append(tagKvs["gorm"], `type:datetime`)
+		tagKvs["gorm"] = append(tagKvs["gorm"], TypeTag(ColumnDatetime))
 	case "deletedAt":
-		tagKvs["gorm"] = append(tagKvs["gorm"], `type:datetime`)
+		tagKvs["gorm"] = append(tagKvs["gorm"], TypeTag(ColumnDatetime))
 		tagKvs["gorm"] = append(tagKvs["gorm"], `index`)
 	}
+	if tenantColumn(td, fd) {
+		tagKvs["gorm"] = append(tagKvs["gorm"], `tenant`, `index`)
+	}
 	for dName, fn := range directives {
 		directives := fd.Directives.ForNames(dName)
 		for _, directive := range directives {
@@ -307,7 +531,7 @@ func fieldLogic(td *ast.Definition, fd *ast.FieldDefinition, f *modelgen.Field)
 		}
 	}
 	if isStringType(f) && !hasTypeTag {
-		tagKvs["gorm"] = append(tagKvs["gorm"], "type:varchar(255)")
+		tagKvs["gorm"] = append(tagKvs["gorm"], TypeTag(ColumnVarchar))
 	}
 	if fd.Type.NonNull {
 		tagKvs["gorm"] = append(tagKvs["gorm"], "not null")
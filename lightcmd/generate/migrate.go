@@ -0,0 +1,18 @@
+package generate
+
+import "github.com/vektah/gqlparser/v2/ast"
+
+// migrateTypes 带 id 字段的对象类型，对应数据表，用于生成 migrate.RegisterModels
+var migrateTypes = make(map[string]bool)
+
+// collectMigrate 记录需要 migrate:diff 对比的类型，根类型和分页类型没有 id 字段，不会被记录
+func collectMigrate(td *ast.Definition, fd *ast.FieldDefinition) {
+	if td.Kind != ast.Object || fd.Name != "id" {
+		return
+	}
+	switch td.Name {
+	case "Query", "Mutation", "Subscription":
+		return
+	}
+	migrateTypes[td.Name] = true
+}
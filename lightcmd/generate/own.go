@@ -0,0 +1,31 @@
+package generate
+
+import (
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const ownDirective = "own"
+
+// ownMaskable @own(deny: MASK) 在非空字段上可以返回零值的类型
+var ownMaskable = map[string]bool{"String": true, "ID": true, "Int": true, "Float": true, "Boolean": true}
+
+// validateOwn 检查非空字段上的 @own：deny 为 NULL 时无法返回 null，deny 为 MASK 时字段需要是内置标量
+func validateOwn(td *ast.Definition, fd *ast.FieldDefinition) error {
+	directive := fd.Directives.ForName(ownDirective)
+	if directive == nil || !fd.Type.NonNull {
+		return nil
+	}
+	deny := "MASK"
+	if arg := directive.Arguments.ForName("deny"); arg != nil && arg.Value != nil {
+		deny = arg.Value.Raw
+	}
+	switch {
+	case deny == "NULL":
+		return fmt.Errorf("%s.%s: @own(deny: NULL) cannot be used on non-null field", td.Name, fd.Name)
+	case deny == "MASK" && (fd.Type.Elem != nil || !ownMaskable[fd.Type.NamedType]):
+		return fmt.Errorf("%s.%s: @own(deny: MASK) has no zero value for non-null type %s, use deny: ERROR or make the field nullable", td.Name, fd.Name, fd.Type)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/light-speak/lighthouse/lightcmd/scalars"
+	"github.com/light-speak/lighthouse/routers/auth"
+	"github.com/light-speak/lighthouse/routers/own"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"gopkg.in/yaml.v3"
+)
+
+const ownSchema = `type Order {
+  phone: String! @own
+  amount: Int! @own(deny: ERROR)
+  note: String @own(deny: NULL)
+  buyer: User! @own
+  tags: [String!]! @own(deny: MASK)
+  email: String! @own(fields: ["buyerId"], deny: NULL)
+}
+`
+
+func TestValidateOwn(t *testing.T) {
+	doc, err := parser.ParseSchema(&ast.Source{Name: "schema.graphqls", Input: ownSchema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	td := doc.Definitions.ForName("Order")
+
+	tests := []struct {
+		field string
+		err   bool
+	}{
+		{"phone", false},
+		{"amount", false},
+		{"note", false},
+		{"buyer", true},
+		{"tags", true},
+		{"email", true},
+	}
+	for _, test := range tests {
+		t.Run("Test Own "+test.field, func(t *testing.T) {
+			err := validateOwn(&ast.Definition{Name: td.Name}, td.Fields.ForName(test.field))
+			if (err != nil) != test.err {
+				t.Errorf("validateOwn(%s) error = %v, expected error: %v", test.field, err, test.err)
+			}
+		})
+	}
+}
+
+// TestOwnMaskTypes 非空字段 MASK 返回的零值需要能被生成代码断言为 gqlgen.yml 中映射的 Go 类型
+func TestOwnMaskTypes(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "initization", "tpl", "gqlgen.tpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Models map[string]struct {
+			Model any `yaml:"model"`
+		} `yaml:"models"`
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		t.Fatal(err)
+	}
+	marshalers := map[string]any{
+		"github.com/99designs/gqlgen/graphql.String":              graphql.MarshalString,
+		"github.com/99designs/gqlgen/graphql.Float":               graphql.MarshalFloatContext,
+		"github.com/99designs/gqlgen/graphql.Boolean":             graphql.MarshalBoolean,
+		"github.com/99designs/gqlgen/graphql.Int32":               graphql.MarshalInt32,
+		"github.com/light-speak/lighthouse/lightcmd/scalars.Uint": scalars.MarshalUint,
+	}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserId: 2})
+	obj := map[string]any{"userId": uint(1)}
+
+	for name := range ownMaskable {
+		t.Run("Test Mask "+name, func(t *testing.T) {
+			model := "github.com/99designs/gqlgen/graphql." + name
+			if m, ok := config.Models[name]; ok {
+				switch v := m.Model.(type) {
+				case string:
+					model = v
+				case []any:
+					model = v[0].(string)
+				}
+			}
+			marshaler, ok := marshalers[model]
+			if !ok {
+				t.Fatalf("unknown model %s for %s", model, name)
+			}
+			fc := &graphql.FieldContext{Field: graphql.CollectedField{Field: &ast.Field{
+				Definition: &ast.FieldDefinition{Name: "field", Type: ast.NonNullNamedType(name, nil)},
+			}}}
+			res, err := own.OwnDirective(graphql.WithFieldContext(ctx, fc), obj, nil, []string{"userId"}, nil, own.DenyMask)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := reflect.TypeOf(marshaler).In(0); reflect.TypeOf(res) != expected {
+				t.Errorf("MASK %s = %T, expected %s", name, res, expected)
+			}
+		})
+	}
+}
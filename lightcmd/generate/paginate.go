@@ -0,0 +1,222 @@
+package generate
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/light-speak/lighthouse/logs"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const (
+	paginateDirective = "paginate"
+	paginateSource    = "lighthouse_paginate.graphqls"
+	paginateTypeEnum  = "PaginateType"
+	pageInfoType      = "PageInfo"
+	pageInfoModel     = "github.com/light-speak/lighthouse/databases.PageInfo"
+)
+
+// paginateTypes 使用 @paginate 的节点类型，用于生成 NewXxxConnection
+var paginateTypes = make(map[string]bool)
+
+// paginatePlugin 展开 @paginate 字段：把列表类型改写为 XxxConnection!，追加分页参数，并生成 Connection/Edge/PageInfo 类型
+// 改写发生在 gqlgen 加载 schema 之前，改写后的 schema 在生成代码时内联，保证运行时与生成的代码一致
+type paginatePlugin struct {
+	cfg *config.Config
+}
+
+func newPaginatePlugin(cfg *config.Config) *paginatePlugin {
+	return &paginatePlugin{cfg: cfg}
+}
+
+func (p *paginatePlugin) Name() string {
+	return "lighthouse-paginate"
+}
+
+// paginateField 一个 @paginate 字段
+type paginateField struct {
+	field *ast.FieldDefinition
+	node  string
+	mode  string
+}
+
+func (p *paginatePlugin) InjectSourcesEarly() ([]*ast.Source, error) {
+	nodes := make(map[string]bool)
+	defined := make(map[string]bool)
+	directiveDefined := false
+	dir := ""
+
+	for _, src := range p.cfg.Sources {
+		if src.BuiltIn {
+			continue
+		}
+		doc, err := parser.ParseSchema(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, def := range doc.Definitions {
+			defined[def.Name] = true
+		}
+		for _, def := range doc.Directives {
+			if def.Name == paginateDirective {
+				directiveDefined = true
+			}
+		}
+
+		fields, err := findPaginateFields(doc)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if err := p.rewrite(src, fields); err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			nodes[f.node] = true
+		}
+		if dir == "" {
+			dir = filepath.Dir(src.Name)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	// @paginate 只在生成时展开，运行时由 databases.PaginationFromContext 读取参数
+	if p.cfg.Directives == nil {
+		p.cfg.Directives = map[string]config.DirectiveConfig{}
+	}
+	p.cfg.Directives[paginateDirective] = config.DirectiveConfig{SkipRuntime: true}
+	if !defined[pageInfoType] && !p.cfg.Models.UserDefined(pageInfoType) {
+		p.cfg.Models.Add(pageInfoType, pageInfoModel)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Code generated by lighthouse from @paginate, DO NOT EDIT.\n")
+	if !defined[paginateTypeEnum] {
+		sb.WriteString(paginateTypeDefinition)
+	}
+	if !directiveDefined {
+		sb.WriteString(paginateDirectiveDefinition)
+	}
+	if !defined[pageInfoType] {
+		sb.WriteString(pageInfoDefinition)
+	}
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		paginateTypes[name] = true
+		if !defined[name+"Connection"] {
+			fmt.Fprintf(&sb, "\ntype %sConnection {\n  edges: [%sEdge!]!\n  pageInfo: %s!\n}\n", name, name, pageInfoType)
+		}
+		if !defined[name+"Edge"] {
+			fmt.Fprintf(&sb, "\ntype %sEdge {\n  node: %s!\n  cursor: String!\n}\n", name, name)
+		}
+	}
+
+	src := &ast.Source{Name: filepath.ToSlash(filepath.Join(dir, paginateSource)), Input: sb.String()}
+	markRewritten(src)
+	return []*ast.Source{src}, nil
+}
+
+// GenerateCode 在 gqlgen 校验生成的代码之前写入 NewXxxConnection，resolver 中可以直接引用
+func (p *paginatePlugin) GenerateCode(data *codegen.Data) error {
+	inlineRewritten(data)
+	if len(paginateTypes) == 0 {
+		return nil
+	}
+	logs.Info().Msg("Generating connection helpers...")
+	if err := generatePaginate(); err != nil {
+		return fmt.Errorf("failed to generate connection helpers: %w", err)
+	}
+	return nil
+}
+
+// findPaginateFields 找出对象类型中带 @paginate 的字段，字段类型必须是列表
+func findPaginateFields(doc *ast.SchemaDocument) ([]*paginateField, error) {
+	defs := append(append(ast.DefinitionList{}, doc.Definitions...), doc.Extensions...)
+	fields := make([]*paginateField, 0)
+	for _, def := range defs {
+		if def.Kind != ast.Object {
+			continue
+		}
+		for _, field := range def.Fields {
+			directive := field.Directives.ForName(paginateDirective)
+			if directive == nil {
+				continue
+			}
+			if field.Type.Elem == nil || field.Type.Elem.NamedType == "" {
+				return nil, fmt.Errorf("@paginate on %s.%s requires a list type, got %s", def.Name, field.Name, field.Type.String())
+			}
+
+			mode := "CURSOR"
+			if arg := directive.Arguments.ForName("type"); arg != nil && arg.Value != nil {
+				mode = arg.Value.Raw
+			}
+			if mode != "CURSOR" && mode != "OFFSET" {
+				return nil, fmt.Errorf("@paginate on %s.%s: unsupported type %s", def.Name, field.Name, mode)
+			}
+
+			fields = append(fields, &paginateField{
+				field: field,
+				node:  field.Type.Elem.NamedType,
+				mode:  mode,
+			})
+		}
+	}
+	return fields, nil
+}
+
+// rewrite 把 @paginate 字段改写为 XxxConnection!，并按分页方式追加 first/after 或 first/page 参数
+func (p *paginatePlugin) rewrite(src *ast.Source, fields []*paginateField) error {
+	rewrites := make([]*fieldRewrite, 0, len(fields))
+	for _, f := range fields {
+		args := []string{"first: Int", "after: String"}
+		if f.mode == "OFFSET" {
+			args = []string{"first: Int", "page: Int"}
+		}
+		rewrites = append(rewrites, &fieldRewrite{field: f.field, args: args, typ: f.node + "Connection!"})
+	}
+	if err := rewriteFields(src, rewrites); err != nil {
+		return fmt.Errorf("@paginate: %w", err)
+	}
+	markRewritten(src)
+	return nil
+}
+
+const paginateTypeDefinition = `
+enum PaginateType {
+  CURSOR
+  OFFSET
+}
+`
+
+const paginateDirectiveDefinition = `
+directive @paginate(type: PaginateType = CURSOR, defaultSize: Int = 15, maxSize: Int = 100) on FIELD_DEFINITION
+`
+
+const pageInfoDefinition = `
+type PageInfo {
+  hasNextPage: Boolean!
+  hasPreviousPage: Boolean!
+  startCursor: String
+  endCursor: String
+  "总条数，仅 OFFSET 分页返回"
+  total: Int
+  "当前页码，仅 OFFSET 分页返回"
+  currentPage: Int
+  "最后一页页码，仅 OFFSET 分页返回"
+  lastPage: Int
+}
+`
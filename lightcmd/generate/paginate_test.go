@@ -0,0 +1,85 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const paginateSchema = `scalar Time
+
+type User {
+  id: ID!
+  name: String!
+}
+
+type Post {
+  id: ID!
+}
+
+type Query {
+  "用户列表"
+  users(name: String @deprecated(reason: "x")): [User!]! @paginate
+  posts: [Post] @paginate(type: OFFSET, maxSize: 50)
+  me: User
+}
+`
+
+func TestPaginateRewrite(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Sources = []*ast.Source{{Name: "graph/schema.graphqls", Input: paginateSchema}}
+
+	p := newPaginatePlugin(cfg)
+	injected, err := p.InjectSourcesEarly()
+	if err != nil {
+		t.Fatalf("InjectSourcesEarly() error: %v", err)
+	}
+	if len(injected) != 1 || injected[0].Name != "graph/"+paginateSource {
+		t.Fatalf("InjectSourcesEarly() = %v; expected one generated source", injected)
+	}
+	if !cfg.Directives[paginateDirective].SkipRuntime {
+		t.Errorf("@paginate should be skip_runtime")
+	}
+
+	schema, gqlErr := gqlparser.LoadSchema(append(cfg.Sources, injected...)...)
+	if gqlErr != nil {
+		t.Fatalf("LoadSchema() error: %v", gqlErr)
+	}
+
+	tests := []struct {
+		field string
+		typ   string
+		args  []string
+	}{
+		{"users", "UserConnection!", []string{"name", "first", "after"}},
+		{"posts", "PostConnection!", []string{"first", "page"}},
+		{"me", "User", nil},
+	}
+	for _, test := range tests {
+		t.Run("Test Paginate Rewrite "+test.field, func(t *testing.T) {
+			field := schema.Query.Fields.ForName(test.field)
+			if field.Type.String() != test.typ {
+				t.Errorf("type = %s; expected %s", field.Type.String(), test.typ)
+			}
+			if len(field.Arguments) != len(test.args) {
+				t.Fatalf("args = %v; expected %v", field.Arguments, test.args)
+			}
+			for i, arg := range field.Arguments {
+				if arg.Name != test.args[i] {
+					t.Errorf("arg %d = %s; expected %s", i, arg.Name, test.args[i])
+				}
+			}
+		})
+	}
+
+	if schema.Query.Fields.ForName("users").Description != "用户列表" {
+		t.Errorf("description should be kept")
+	}
+	for _, name := range []string{"UserEdge", "PostConnection", "PageInfo", "PaginateType"} {
+		if schema.Types[name] == nil {
+			t.Errorf("type %s is not generated", name)
+		}
+	}
+}
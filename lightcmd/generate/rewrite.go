@@ -0,0 +1,154 @@
+package generate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/lexer"
+)
+
+// rewrittenSources 改写过或生成的 schema 内容
+var rewrittenSources = make(map[string]bool)
+
+// markRewritten 标记 schema 已改写，生成代码时需要内联
+func markRewritten(src *ast.Source) {
+	rewrittenSources[src.Input] = true
+}
+
+// inlineRewritten 改写过的 schema 与磁盘上的文件不同，不能通过 go:embed 读取，改为内联到生成的代码中
+func inlineRewritten(data *codegen.Data) {
+	for i := range data.AugmentedSources {
+		if rewrittenSources[data.AugmentedSources[i].Source] {
+			data.AugmentedSources[i].Embeddable = false
+		}
+	}
+}
+
+// fieldRewrite 对 schema 中一个字段定义的改写
+type fieldRewrite struct {
+	field *ast.FieldDefinition
+	args  []string // 追加的参数，如 "first: Int"，字段已有的同名参数会跳过
+	typ   string   // 新的字段类型，为空时保持原类型
+}
+
+// rewriteFields 按 token 位置改写字段的参数列表和类型，其余内容（注释、格式、行号）保持不变
+// 指令在 gqlgen 加载 schema 之前展开，改写后的 schema 需要在生成代码时内联，见 inlineRewritten
+func rewriteFields(src *ast.Source, rewrites []*fieldRewrite) error {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return err
+	}
+	input := []rune(src.Input)
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	edits := make([]edit, 0, len(rewrites))
+	for _, rw := range rewrites {
+		f := rw.field
+		nameIdx := -1
+		for i, tok := range tokens {
+			if tok.Pos.Start >= f.Position.Start && tok.Kind == lexer.Name && tok.Value == f.Name {
+				nameIdx = i
+				break
+			}
+		}
+		if nameIdx < 0 {
+			return fmt.Errorf("field %s not found in %s", f.Name, src.Name)
+		}
+
+		i := nameIdx + 1
+		args := ""
+		if tokens[i].Kind == lexer.ParenL {
+			open, depth := i, 0
+			for ; i < len(tokens); i++ {
+				if tokens[i].Kind == lexer.ParenL {
+					depth++
+				} else if tokens[i].Kind == lexer.ParenR {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			if i >= len(tokens) {
+				return fmt.Errorf("unbalanced arguments of field %s in %s", f.Name, src.Name)
+			}
+			args = strings.TrimSpace(string(input[tokens[open].Pos.End:tokens[i].Pos.Start]))
+			i++
+		}
+		if i >= len(tokens) || tokens[i].Kind != lexer.Colon {
+			return fmt.Errorf("missing type of field %s in %s", f.Name, src.Name)
+		}
+		i++
+
+		// 类型：[X!]! 的各个 token 直到指令开始
+		end := i
+		for end < len(tokens) && tokens[end].Kind != lexer.At && tokens[end].Kind != lexer.Name {
+			end++
+		}
+		if end < len(tokens) && tokens[end].Kind == lexer.Name {
+			end++
+			for end < len(tokens) && (tokens[end].Kind == lexer.Bang || tokens[end].Kind == lexer.BracketR) {
+				end++
+			}
+		}
+		if end == i {
+			return fmt.Errorf("cannot find type of field %s in %s", f.Name, src.Name)
+		}
+		typ := rw.typ
+		if typ == "" {
+			typ = string(input[tokens[i].Pos.Start:tokens[end-1].Pos.End])
+		}
+
+		extra := make([]string, 0, len(rw.args))
+		for _, arg := range rw.args {
+			name := strings.TrimSpace(strings.SplitN(arg, ":", 2)[0])
+			if f.Arguments.ForName(name) == nil {
+				extra = append(extra, arg)
+			}
+		}
+		if args != "" && len(extra) > 0 {
+			args += ", "
+		}
+		args += strings.Join(extra, ", ")
+
+		head := fmt.Sprintf("(%s): %s", args, typ)
+		if args == "" {
+			head = ": " + typ
+		}
+		edits = append(edits, edit{
+			start: tokens[nameIdx].Pos.End,
+			end:   tokens[end-1].Pos.End,
+			text:  head,
+		})
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		input = append(input[:e.start], append([]rune(e.text), input[e.end:]...)...)
+	}
+	src.Input = string(input)
+	return nil
+}
+
+func tokenize(src *ast.Source) ([]lexer.Token, error) {
+	lex := lexer.New(src)
+	tokens := make([]lexer.Token, 0)
+	for {
+		tok, err := lex.ReadToken()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Kind == lexer.EOF {
+			return tokens, nil
+		}
+		if tok.Kind == lexer.Comment {
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+}
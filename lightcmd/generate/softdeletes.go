@@ -0,0 +1,188 @@
+package generate
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/light-speak/lighthouse/logs"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const (
+	softDeletesDirective = "softDeletes"
+	softDeletesSource    = "lighthouse_soft_deletes.graphqls"
+	deletedAtScalar      = "DeletedAt"
+	deletedAtModel       = "github.com/light-speak/lighthouse/lightcmd/scalars.DeletedAt"
+)
+
+// softDeleteTypes 使用 @softDeletes 的类型，用于生成 RestoreXxx / ForceDeleteXxx
+var softDeleteTypes = make(map[string]bool)
+
+// softDeletesPlugin 展开 @softDeletes 类型：
+// 返回该类型的 Query 字段追加 withTrashed / onlyTrashed 参数，并生成 restoreXxx / forceDeleteXxx 变更
+// 需要在 paginatePlugin 之前执行，@paginate 字段改写为 Connection 后无法再判断节点类型
+type softDeletesPlugin struct {
+	cfg *config.Config
+}
+
+func newSoftDeletesPlugin(cfg *config.Config) *softDeletesPlugin {
+	return &softDeletesPlugin{cfg: cfg}
+}
+
+func (p *softDeletesPlugin) Name() string {
+	return "lighthouse-soft-deletes"
+}
+
+func (p *softDeletesPlugin) InjectSourcesEarly() ([]*ast.Source, error) {
+	docs := make(map[*ast.Source]*ast.SchemaDocument)
+	types := make(map[string]bool)
+	defined := make(map[string]bool)
+	fields := make(map[string]map[string]bool)
+	directiveDefined := false
+	dir := ""
+
+	for _, src := range p.cfg.Sources {
+		if src.BuiltIn {
+			continue
+		}
+		doc, err := parser.ParseSchema(src)
+		if err != nil {
+			return nil, err
+		}
+		docs[src] = doc
+		for _, def := range doc.Definitions {
+			defined[def.Name] = true
+		}
+		for _, def := range doc.Directives {
+			if def.Name == softDeletesDirective {
+				directiveDefined = true
+			}
+		}
+		for _, def := range append(append(ast.DefinitionList{}, doc.Definitions...), doc.Extensions...) {
+			if fields[def.Name] == nil {
+				fields[def.Name] = make(map[string]bool)
+			}
+			for _, field := range def.Fields {
+				fields[def.Name][field.Name] = true
+			}
+			if def.Directives.ForName(softDeletesDirective) == nil {
+				continue
+			}
+			if def.Kind != ast.Object {
+				return nil, fmt.Errorf("@softDeletes on %s requires an object type", def.Name)
+			}
+			types[def.Name] = true
+			if dir == "" {
+				dir = filepath.Dir(src.Name)
+			}
+		}
+	}
+
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	for _, src := range p.cfg.Sources {
+		doc := docs[src]
+		if doc == nil {
+			continue
+		}
+		rewrites := trashedQueryFields(doc, types)
+		if len(rewrites) == 0 {
+			continue
+		}
+		if err := rewriteFields(src, rewrites); err != nil {
+			return nil, fmt.Errorf("@softDeletes: %w", err)
+		}
+		markRewritten(src)
+	}
+
+	if p.cfg.Directives == nil {
+		p.cfg.Directives = map[string]config.DirectiveConfig{}
+	}
+	p.cfg.Directives[softDeletesDirective] = config.DirectiveConfig{SkipRuntime: true}
+	if !defined[deletedAtScalar] && !p.cfg.Models.UserDefined(deletedAtScalar) {
+		p.cfg.Models.Add(deletedAtScalar, deletedAtModel)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Code generated by lighthouse from @softDeletes, DO NOT EDIT.\n")
+	if !directiveDefined {
+		sb.WriteString("\ndirective @softDeletes on OBJECT\n")
+	}
+	if !defined[deletedAtScalar] {
+		sb.WriteString("\nscalar DeletedAt\n")
+	}
+
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	mutations := make([]string, 0, len(names)*2)
+	for _, name := range names {
+		softDeleteTypes[name] = true
+		if !fields[name]["deletedAt"] {
+			fmt.Fprintf(&sb, "\nextend type %s {\n  deletedAt: DeletedAt\n}\n", name)
+		}
+		if !fields["Mutation"]["restore"+name] {
+			mutations = append(mutations, fmt.Sprintf("  \"恢复软删除的 %s\"\n  restore%s(id: ID!): %s!", name, name, name))
+		}
+		if !fields["Mutation"]["forceDelete"+name] {
+			mutations = append(mutations, fmt.Sprintf("  \"永久删除 %s\"\n  forceDelete%s(id: ID!): Boolean!", name, name))
+		}
+	}
+	if len(mutations) > 0 {
+		keyword := "extend type"
+		if !defined["Mutation"] {
+			keyword = "type"
+		}
+		fmt.Fprintf(&sb, "\n%s Mutation {\n%s\n}\n", keyword, strings.Join(mutations, "\n"))
+	}
+
+	src := &ast.Source{Name: filepath.ToSlash(filepath.Join(dir, softDeletesSource)), Input: sb.String()}
+	markRewritten(src)
+	return []*ast.Source{src}, nil
+}
+
+// GenerateCode 在 gqlgen 校验生成的代码之前写入 RestoreXxx / ForceDeleteXxx，resolver 中可以直接引用
+func (p *softDeletesPlugin) GenerateCode(data *codegen.Data) error {
+	inlineRewritten(data)
+	if len(softDeleteTypes) == 0 {
+		return nil
+	}
+	logs.Info().Msg("Generating soft delete helpers...")
+	if err := generateSoftDeletes(); err != nil {
+		return fmt.Errorf("failed to generate soft delete helpers: %w", err)
+	}
+	return nil
+}
+
+// trashedQueryFields 找出 Query 中返回软删除类型（或其列表）的字段，追加 withTrashed / onlyTrashed 参数
+func trashedQueryFields(doc *ast.SchemaDocument, types map[string]bool) []*fieldRewrite {
+	rewrites := make([]*fieldRewrite, 0)
+	for _, def := range append(append(ast.DefinitionList{}, doc.Definitions...), doc.Extensions...) {
+		if def.Kind != ast.Object || def.Name != "Query" {
+			continue
+		}
+		for _, field := range def.Fields {
+			typ := field.Type
+			for typ.Elem != nil {
+				typ = typ.Elem
+			}
+			if !types[typ.NamedType] {
+				continue
+			}
+			rewrites = append(rewrites, &fieldRewrite{
+				field: field,
+				args:  []string{"withTrashed: Boolean", "onlyTrashed: Boolean"},
+			})
+		}
+	}
+	return rewrites
+}
@@ -0,0 +1,85 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const softDeletesSchema = `scalar Time
+
+type User @softDeletes {
+  id: ID!
+  name: String!
+}
+
+type Post {
+  id: ID!
+}
+
+type Query {
+  users: [User!]! @paginate
+  user(id: ID!): User
+  posts: [Post!]!
+}
+`
+
+func TestSoftDeletesRewrite(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Sources = []*ast.Source{{Name: "graph/schema.graphqls", Input: softDeletesSchema}}
+
+	// 与 GenerateSchema 中的插件顺序一致
+	sources := cfg.Sources
+	for _, p := range []interface {
+		InjectSourcesEarly() ([]*ast.Source, error)
+	}{newSoftDeletesPlugin(cfg), newPaginatePlugin(cfg)} {
+		injected, err := p.InjectSourcesEarly()
+		if err != nil {
+			t.Fatalf("InjectSourcesEarly() error: %v", err)
+		}
+		sources = append(sources, injected...)
+		cfg.Sources = sources
+	}
+	if !cfg.Directives[softDeletesDirective].SkipRuntime {
+		t.Errorf("@softDeletes should be skip_runtime")
+	}
+
+	schema, gqlErr := gqlparser.LoadSchema(sources...)
+	if gqlErr != nil {
+		t.Fatalf("LoadSchema() error: %v", gqlErr)
+	}
+
+	tests := []struct {
+		object string
+		field  string
+		args   []string
+	}{
+		{"Query", "users", []string{"withTrashed", "onlyTrashed", "first", "after"}},
+		{"Query", "user", []string{"id", "withTrashed", "onlyTrashed"}},
+		{"Query", "posts", nil},
+		{"Mutation", "restoreUser", []string{"id"}},
+		{"Mutation", "forceDeleteUser", []string{"id"}},
+	}
+	for _, test := range tests {
+		t.Run("Test Soft Deletes Rewrite "+test.field, func(t *testing.T) {
+			field := schema.Types[test.object].Fields.ForName(test.field)
+			if field == nil {
+				t.Fatalf("%s.%s is not generated", test.object, test.field)
+			}
+			if len(field.Arguments) != len(test.args) {
+				t.Fatalf("args = %v; expected %v", field.Arguments, test.args)
+			}
+			for i, arg := range field.Arguments {
+				if arg.Name != test.args[i] {
+					t.Errorf("arg %d = %s; expected %s", i, arg.Name, test.args[i])
+				}
+			}
+		})
+	}
+
+	if schema.Types["User"].Fields.ForName("deletedAt") == nil {
+		t.Errorf("User.deletedAt is not generated")
+	}
+}
@@ -0,0 +1,22 @@
+package generate
+
+import "github.com/vektah/gqlparser/v2/ast"
+
+const (
+	tenantDirective = "tenant"
+	tenantField     = "tenantId"
+)
+
+// tenantColumn 判断字段是否为 @tenant 类型的租户列，租户列由 @tenant(field:) 指定，默认 tenantId
+// 租户列带有 tenant gorm tag，databases 据此自动按租户隔离
+func tenantColumn(td *ast.Definition, fd *ast.FieldDefinition) bool {
+	directive := td.Directives.ForName(tenantDirective)
+	if directive == nil {
+		return false
+	}
+	name := tenantField
+	if arg := directive.Arguments.ForName("field"); arg != nil && arg.Value != nil {
+		name = arg.Value.Raw
+	}
+	return fd.Name == name
+}
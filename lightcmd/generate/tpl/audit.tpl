@@ -0,0 +1,8 @@
+
+// 登记 @audited 模型，创建、更新、删除时写入审计日志，audit_logs 表随其他模型一起迁移
+func init() {
+	migrate.RegisterModels(&audit.Log{})
+{{- range $node := .Nodes }}
+	audit.Register(&{{ $node.Name | ucFirst }}{}{{ range $field := $node.Exclude }}, audit.Exclude("{{ $field }}"){{ end }})
+{{- end }}
+}
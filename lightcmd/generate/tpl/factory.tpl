@@ -0,0 +1,7 @@
+
+// 登记 @loader 外键对应的关联，factory 创建模型时外键为零值会先创建关联的模型
+func init() {
+{{- range $rel := .Relations }}
+	factory.Register(&{{ $rel.Model | ucFirst }}{}, factory.BelongsTo("{{ $rel.Field }}", &{{ $rel.Parent | ucFirst }}{}))
+{{- end }}
+}
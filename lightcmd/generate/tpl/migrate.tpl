@@ -0,0 +1,9 @@
+
+// 登记数据表模型，migrate:diff 对比这些模型与数据库生成迁移
+func init() {
+	migrate.RegisterModels(
+{{- range $name := .Names }}
+		&{{ $name | ucFirst }}{},
+{{- end }}
+	)
+}
@@ -0,0 +1,12 @@
+{{- range $node := .Nodes }}
+{{- $name := ($node | ucFirst) }}
+
+// New{{ $name }}Connection 将 Repository.Paginate 的结果转换为 {{ $name }}Connection
+func New{{ $name }}Connection(page *databases.Page[{{ $name }}]) *{{ $name }}Connection {
+	edges := make([]*{{ $name }}Edge, 0, len(page.Items))
+	for i, item := range page.Items {
+		edges = append(edges, &{{ $name }}Edge{Node: item, Cursor: page.Cursors[i]})
+	}
+	return &{{ $name }}Connection{Edges: edges, PageInfo: page.PageInfo()}
+}
+{{- end }}
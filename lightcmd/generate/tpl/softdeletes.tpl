@@ -0,0 +1,24 @@
+{{- range $node := .Nodes }}
+{{- $name := ($node | ucFirst) }}
+
+// Restore{{ $name }} 恢复软删除的 {{ $name }}，用于 restore{{ $name }} 变更
+func Restore{{ $name }}(ctx context.Context, id any) (*{{ $name }}, error) {
+	db, err := databases.LightDatabaseClient.GetDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return databases.Restore[{{ $name }}](db, id)
+}
+
+// ForceDelete{{ $name }} 永久删除 {{ $name }}，用于 forceDelete{{ $name }} 变更
+func ForceDelete{{ $name }}(ctx context.Context, id any) (bool, error) {
+	db, err := databases.LightDatabaseClient.GetDB(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := databases.ForceDelete[{{ $name }}](db, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+{{- end }}
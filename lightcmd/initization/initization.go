@@ -1,7 +1,9 @@
 package initization
 
 import (
+	"crypto/rand"
 	"embed"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/light-speak/lighthouse/lightcmd/migrate"
 	"github.com/light-speak/lighthouse/logs"
 	"github.com/light-speak/lighthouse/templates"
 	"github.com/light-speak/lighthouse/utils"
@@ -21,6 +24,7 @@ var projectName string
 var projectModule string
 var currentDir string
 var models []string
+var driver string
 
 var dirs = []string{
 	"commands",
@@ -37,11 +41,19 @@ type initStep struct {
 	fn   func() error
 }
 
-func Run(module string, ms string) error {
+func Run(module string, ms string, db string) error {
+	switch db {
+	case "mysql", "postgres", "sqlite":
+		driver = db
+	default:
+		return fmt.Errorf("unsupported database driver: %s, expected mysql | postgres | sqlite", db)
+	}
+
 	fmt.Println()
 	logs.Info().Msg("🚀 Initializing new Lighthouse project...")
 	logs.Info().Msgf("   Module: %s", module)
 	logs.Info().Msgf("   Project: %s", filepath.Base(module))
+	logs.Info().Msgf("   Database: %s", driver)
 	fmt.Println()
 
 	projectModule = module
@@ -86,6 +98,7 @@ func Run(module string, ms string) error {
 	templates.AddImportRegex("http", "net/http", "")
 	templates.AddImportRegex("utils", "github.com/light-speak/lighthouse/utils", "")
 	templates.AddImportRegex("godotenv", "github.com/joho/godotenv", "")
+	templates.AddImportRegex(`(^|[^A-Za-z])config\.`, "github.com/light-speak/lighthouse/config", "")
 	templates.AddImportRegex("filepath", "path/filepath", "")
 	templates.AddImportRegex("gorm", "gorm.io/gorm", "")
 	templates.AddImportRegex("databases", "github.com/light-speak/lighthouse/databases", "")
@@ -99,6 +112,10 @@ func Run(module string, ms string) error {
 	templates.AddImportRegex("messaging", "github.com/light-speak/lighthouse/messaging", "")
 	templates.AddImportRegex("queue", "github.com/light-speak/lighthouse/queue", "")
 	templates.AddImportRegex("redis", "github.com/light-speak/lighthouse/redis", "")
+	templates.AddImportRegex("querycache", "github.com/light-speak/lighthouse/querycache", "")
+	templates.AddImportRegex(`policy\.`, "github.com/light-speak/lighthouse/routers/policy", "")
+	templates.AddImportRegex(`(^|[^A-Za-z])own\.`, "github.com/light-speak/lighthouse/routers/own", "")
+	templates.AddImportRegex(`(^|[^A-Za-z/])lighthouse\.`, "github.com/light-speak/lighthouse/lighthouse", "")
 	templates.AddImportRegex("bytes", "bytes", "")
 
 	// Execute init steps with progress
@@ -176,12 +193,20 @@ func initEnv() error {
 	if err != nil {
 		return err
 	}
+	data := databaseData()
+	// 每个项目生成独立的 JWT 密钥，框架不再提供默认密钥
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	data["JWTSecret"] = hex.EncodeToString(secret)
 	options := &templates.Options{
 		Path:         filepath.Join(projectName),
 		Template:     string(envTpl),
 		FileName:     ".env",
 		Editable:     true,
 		SkipIfExists: true,
+		Data:         data,
 	}
 	return templates.Render(options)
 }
@@ -443,24 +468,7 @@ func initGitignore() error {
 }
 
 func initMigration() error {
-	migrationTpl, err := tpl.ReadFile("tpl/migration.tpl")
-	if err != nil {
-		return err
-	}
-	options := &templates.Options{
-		Path:         filepath.Join(projectName, "commands"),
-		Template:     string(migrationTpl),
-		FileName:     "migration",
-		Package:      "commands",
-		FileExt:      "go",
-		Editable:     true,
-		SkipIfExists: true,
-	}
-	templates.AddImportRegex("atlasexec", "ariga.io/atlas-go-sdk/atlasexec", "")
-	templates.AddImportRegex("context", "context", "")
-	templates.AddImportRegex("formatter", "github.com/vektah/gqlparser/v2/formatter", "")
-
-	return templates.Render(options)
+	return migrate.Render(filepath.Join(projectName, "commands"))
 }
 
 func initSchemaCmd() error {
@@ -477,6 +485,7 @@ func initSchemaCmd() error {
 		Editable:     true,
 		SkipIfExists: true,
 	}
+	templates.AddImportRegex("formatter", "github.com/vektah/gqlparser/v2/formatter", "")
 	return templates.Render(options)
 }
 
@@ -493,6 +502,7 @@ func initLoader() error {
 		Editable:     true,
 		SkipIfExists: true,
 		SkipImport:   false,
+		Data:         databaseData(),
 	}
 	templates.AddImportRegex("gormschema", "ariga.io/atlas-provider-gorm/gormschema", "")
 	return templates.Render(options)
@@ -510,9 +520,36 @@ func initAtlas() error {
 		FileExt:      "hcl",
 		Editable:     true,
 		SkipIfExists: true,
-		Data: map[string]string{
-			"ProjectName": projectName,
-		},
+		Data:         databaseData(),
 	}
 	return templates.Render(options)
 }
+
+// databaseData 按数据库驱动生成 .env、atlas.hcl 和 loader 的模板数据
+func databaseData() map[string]string {
+	data := map[string]string{
+		"ProjectName": projectName,
+		"Driver":      driver,
+	}
+	switch driver {
+	case "postgres":
+		data["Port"] = "5432"
+		data["User"] = "postgres"
+		data["Name"] = projectName
+		data["DevURL"] = "postgres://postgres:@127.0.0.1:5432/test?sslmode=disable&search_path=public"
+		data["URL"] = fmt.Sprintf("postgres://postgres:@127.0.0.1:5432/%s?sslmode=disable&search_path=public", projectName)
+	case "sqlite":
+		data["Port"] = ""
+		data["User"] = ""
+		data["Name"] = projectName + ".db"
+		data["DevURL"] = "sqlite://dev?mode=memory"
+		data["URL"] = fmt.Sprintf("sqlite://%s.db", projectName)
+	default:
+		data["Port"] = "3306"
+		data["User"] = "root"
+		data["Name"] = projectName
+		data["DevURL"] = "mysql://root:@127.0.0.1:3306/test"
+		data["URL"] = fmt.Sprintf("mysql://root:@127.0.0.1:3306/%s", projectName)
+	}
+	return data
+}
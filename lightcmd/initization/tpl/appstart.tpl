@@ -14,6 +14,10 @@ func (c *Start) Args() []*cmd.CommandArg {
 
 func (c *Start) Action() func(flagValues map[string]interface{}) error {
 	return func(flagValues map[string]interface{}) error {
+		// 启动时连接各组件，失败的组件会在首次使用时自动重连
+		if err := lighthouse.Boot(context.Background()); err != nil {
+			logs.Error().Err(err).Msg("some components failed to initialize")
+		}
 		server.StartService()
 		return nil
 	}
@@ -22,34 +26,7 @@ func (c *Start) Action() func(flagValues map[string]interface{}) error {
 func (c *Start) OnExit() func() {
 	return func() {
 		logs.Info().Msg("shutting down gracefully...")
-
-		// 关闭数据库连接
-		if databases.LightDatabaseClient != nil {
-			databases.LightDatabaseClient.CloseConnections()
-		}
-
-		// 关闭 Redis 连接
-		if lr, err := redis.GetLightRedis(); err == nil && lr != nil {
-			if err := lr.Close(); err != nil {
-				logs.Error().Err(err).Msg("failed to close redis")
-			} else {
-				logs.Info().Msg("redis connection closed")
-			}
-		}
-
-		// 关闭队列客户端
-		if err := queue.CloseClient(); err != nil {
-			logs.Error().Err(err).Msg("failed to close queue client")
-		} else {
-			logs.Info().Msg("queue client closed")
-		}
-
-		// 关闭 NATS 连接
-		if broker := messaging.GetBroker(); broker != nil {
-				broker.Close()
-				logs.Info().Msg("messaging broker closed")
-		}
-
+		lighthouse.Shutdown()
 		logs.Info().Msg("shutdown complete")
 	}
 }
@@ -9,8 +9,8 @@ data "external_schema" "gorm" {
 
 env "dev" {
   src = data.external_schema.gorm.url
-  dev = "mysql://root:@127.0.0.1:3306/test"
-  url = "mysql://root:@127.0.0.1:3306/{{.ProjectName}}"
+  dev = "{{.DevURL}}"
+  url = "{{.URL}}"
   migration {
     dir = "file://migrations"
   }
@@ -1,8 +1,8 @@
 type AppConfig struct {
-	Name       string
-	Port       string
-	RPCPort    string
-	Env        Env
+	Name       string `env:"APP_NAME"`
+	Port       string `env:"APP_PORT"`
+	RPCPort    string `env:"APP_RPC_PORT"`
+	Env        Env    `env:"APP_ENV"`
 	QueueRedis *QueueRedisConfig
 }
 
@@ -15,22 +15,22 @@ const (
 )
 
 type QueueRedisConfig struct {
-	Enabled  bool
-	Host     string
-	Port     string
-	Password string
-	DB       int
+	Enabled  bool   `env:"QUEUE_REDIS_ENABLED"`
+	Host     string `env:"QUEUE_REDIS_HOST"`
+	Port     string `env:"QUEUE_REDIS_PORT"`
+	Password string `env:"QUEUE_REDIS_PASSWORD" secret:"true"`
+	DB       int    `env:"QUEUE_REDIS_DB"`
 }
 
 
 var Config *AppConfig
 
-func init() {
-	Config = &AppConfig{
-		Name: 	  "DefaultApp",
-		Port:     "8080",
-		RPCPort:  "8081",
-		Env:  EnvDevelopment,
+func loadConfig() (*AppConfig, error) {
+	cfg := &AppConfig{
+		Name:    "DefaultApp",
+		Port:    "8080",
+		RPCPort: "8081",
+		Env:     EnvDevelopment,
 		QueueRedis: &QueueRedisConfig{
 			Enabled:  false,
 			Host:     "localhost",
@@ -40,19 +40,21 @@ func init() {
 		},
 	}
 
-	if cp, err := os.Getwd(); err == nil {
-		_ = godotenv.Load(filepath.Join(cp, ".env"))
+	// 配置按 默认值 < config.yaml < .env < .env.<APP_ENV> < 环境变量 叠加
+	if err := config.Bind(cfg); err != nil {
+		return cfg, err
+	}
+	if err := config.Bind(cfg.QueueRedis); err != nil {
+		return cfg, err
 	}
+	return cfg, nil
+}
 
-	Config.Name = utils.GetEnv("APP_NAME", Config.Name)
-	Config.Port = utils.GetEnv("APP_PORT", Config.Port)
-	Config.RPCPort = utils.GetEnv("APP_RPC_PORT", Config.RPCPort)
-	Config.Env = Env(utils.GetEnv("APP_ENV", string(Config.Env)))
-	Config.QueueRedis.Enabled = utils.GetEnvBool("QUEUE_REDIS_ENABLED", Config.QueueRedis.Enabled)
-	if Config.QueueRedis.Enabled {
-		Config.QueueRedis.Host = utils.GetEnv("QUEUE_REDIS_HOST", Config.QueueRedis.Host)
-		Config.QueueRedis.Port = utils.GetEnv("QUEUE_REDIS_PORT", Config.QueueRedis.Port)
-		Config.QueueRedis.Password = utils.GetEnv("QUEUE_REDIS_PASSWORD", Config.QueueRedis.Password)
-		Config.QueueRedis.DB = utils.GetEnvInt("QUEUE_REDIS_DB", Config.QueueRedis.DB)
+func init() {
+	cfg, err := loadConfig()
+	if err != nil {
+		logs.Error().Err(err).Msg("invalid app config")
 	}
+	Config = cfg
+	config.Register("app", func() (any, error) { return loadConfig() })
 }
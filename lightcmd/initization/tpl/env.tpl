@@ -3,7 +3,8 @@
 # ===========================================
 APP_NAME=DefaultApp
 APP_PORT=8080
-APP_ENV=development                    # development | staging | production
+APP_ENV=development                    # development | staging | production，同时加载 .env.<APP_ENV>
+# CONFIG_FILE=config.yaml              # 可选的 YAML 配置文件
 
 # ===========================================
 # Log Settings
@@ -19,6 +20,9 @@ LOG_PRETTY=false                       # 是否美化输出
 # ===========================================
 # Database Settings
 # ===========================================
+# 数据库驱动 mysql | postgres | sqlite
+DB_DRIVER={{.Driver}}
+# DB_SSL_MODE=disable                  # PostgreSQL sslmode
 DB_TIMEZONE=Asia/Shanghai              # 数据库时区
 DB_LOG_LEVEL=info                      # debug | info | warn | error
 DB_MAX_IDLE_CONNS=10                   # 最大空闲连接数
@@ -27,13 +31,13 @@ DB_CONN_MAX_LIFETIME=30                # 连接最大生命周期(分钟)
 DB_CONN_MAX_IDLE_TIME=3                # 空闲连接最大生命周期(分钟)
 DB_PREPARE_STMT=false                  # prepared statement 缓存（启用会导致连接累积）
 
-# 单数据库模式 (DB_ENABLE_SLAVE=false 时使用)
+# 单数据库模式 (DB_ENABLE_SLAVE=false 时使用)，SQLite 的 DB_NAME 为数据库文件路径
 DB_ENABLE_SLAVE=false
 DB_HOST=localhost
-DB_PORT=3306
-DB_USER=root
+DB_PORT={{.Port}}
+DB_USER={{.User}}
 DB_PASSWORD=
-DB_NAME=example
+DB_NAME={{.Name}}
 
 # 主从分离模式 (DB_ENABLE_SLAVE=true 时使用)
 # DB_ENABLE_SLAVE=true
@@ -46,6 +50,22 @@ DB_NAME=example
 # DB_SLAVE_PORT=3306
 # DB_SLAVE_USER=root
 # DB_SLAVE_PASSWORD=
+# DB_SLAVE_WEIGHTS=3,1                  # 从库权重，与 DB_SLAVE_HOST 一一对应
+# DB_SLAVE_POLICY=random                # random | round_robin | least_conn
+# DB_HEALTH_CHECK_INTERVAL=10           # 从库健康检查间隔(秒)，0 关闭
+# DB_SLAVE_MAX_LAG=30                   # 最大复制延迟(秒)，超过则剔除，0 不检查
+# DB_STICKY_WINDOW=5                    # 写操作后同一会话读主库的时间(秒)
+# DB_SLOW_THRESHOLD=200ms               # 慢查询阈值，需要带单位，纯数字按秒处理
+# DB_SLOW_LOG_SIZE=100                  # 内存中保留的慢查询条数
+
+# 命名连接 (DB_CONN_<NAME>_*)，通过 databases.Connection("analytics") 获取
+# DB_CONN_ANALYTICS_DRIVER=mysql         # 默认与 DB_DRIVER 相同
+# DB_CONN_ANALYTICS_HOST=localhost
+# DB_CONN_ANALYTICS_PORT=3306
+# DB_CONN_ANALYTICS_USER=root
+# DB_CONN_ANALYTICS_PASSWORD=
+# DB_CONN_ANALYTICS_NAME=analytics
+# DB_CONN_ANALYTICS_MAX_OPEN_CONNS=20    # 未设置的连接池配置沿用全局配置
 
 # ===========================================
 # Redis Settings
@@ -70,6 +90,7 @@ QUEUE_REDIS_DB=0
 # ===========================================
 # Messaging Settings (NATS/Redis)
 # ===========================================
+MESSAGING_ENABLE=true
 MESSAGING_DRIVER=nats                  # nats | redis
 MESSAGING_URL=localhost:4222
 HOSTNAME=default-instance              # 实例标识，用于消息订阅
@@ -77,9 +98,22 @@ HOSTNAME=default-instance              # 实例标识，用于消息订阅
 # ===========================================
 # JWT & Middleware Settings
 # ===========================================
-JWT_SECRET=IWY@*3JUI#d309HhefzX2WpLtPKtD!hn
+JWT_ALGORITHM=HS256                    # HS256 | RS256 | ES256
+JWT_SECRET={{ .JWTSecret }}            # HS256 密钥，没有默认值
+# JWT_PRIVATE_KEY=keys/jwt.pem          # RS256 / ES256 签名私钥（PEM 文件路径或内容）
+# JWT_KEY_ID=2024-01                    # 写入 token header 的 kid，轮换密钥时区分新旧密钥
+# JWT_JWKS=https://example.com/.well-known/jwks.json  # 按 kid 校验 token 的 JWKS 文件或 URL
+# JWT_JWKS_REFRESH=1h                   # 定期重新加载 JWKS，0 时只在遇到未知 kid 时加载
+# JWT_ISSUER=                           # 签发并校验 iss
+# JWT_AUDIENCE=                         # 签发并校验 aud，多个以逗号分隔
+JWT_TTL=720h                           # GetToken 签发的 token 有效期
+JWT_ACCESS_TTL=15m                     # IssueTokenPair 签发的 access token 有效期
+JWT_REFRESH_TTL=720h                   # IssueTokenPair 签发的 refresh token 有效期
+# JWT_LEEWAY=30s                        # 校验过期时间允许的时钟误差
 MID_HEARTBEAT_PATH=/health             # 存活检查路径 (liveness)
 MID_READINESS_PATH=/ready              # 就绪检查路径 (readiness)
+# MID_ADMIN_TOKEN=                      # 管理接口的 Bearer Token，为空时不注册管理接口
+# MID_SLOW_QUERY_PATH=/admin/slow-queries
 MID_COMPRESS_LEVEL=5                   # gzip 压缩级别 (0-9)
 MID_TIMEOUT=30                         # 请求超时时间(秒)
 MID_THROTTLE=100                       # 请求限流数 (每分钟每IP)
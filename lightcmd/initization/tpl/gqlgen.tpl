@@ -58,6 +58,8 @@ models:
       - github.com/99designs/gqlgen/graphql.Int64
   DeletedAt:
     model: github.com/light-speak/lighthouse/lightcmd/scalars.DeletedAt
+  OwnDenial:
+    model: github.com/light-speak/lighthouse/routers/own.Denial
 
 directives:
   searchable:
@@ -78,6 +80,19 @@ directives:
     skip_runtime: true
   gorm:
     skip_runtime: true
+  paginate:
+    skip_runtime: true
+  version:
+    skip_runtime: true
+  softDeletes:
+    skip_runtime: true
+  tenant:
+    skip_runtime: true
+  audited:
+    skip_runtime: true
   auth:
+  cache:
+  can:
+  hasRole:
   hidden:
   own:
\ No newline at end of file
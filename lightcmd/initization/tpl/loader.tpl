@@ -5,7 +5,7 @@ func main() {
 		DisableForeignKeyConstraintWhenMigrating: true,
 		IgnoreRelationshipsWhenMigrating:         true,
 	})
-	stmts, err := gormschema.New("mysql", option).Load(migrateModels...)
+	stmts, err := gormschema.New("{{.Driver}}", option).Load(migrateModels...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load gorm schema: %v\n", err)
 		os.Exit(1)
@@ -19,7 +19,16 @@ directive @goTag(
 ) on INPUT_FIELD_DEFINITION | FIELD_DEFINITION
 
 directive @auth(msg: String) on FIELD_DEFINITION
-directive @own on FIELD_DEFINITION
+directive @hasRole(roles: [String!]!, msg: String) on FIELD_DEFINITION
+directive @can(ability: String!, model: String, msg: String) on FIELD_DEFINITION
+directive @cache(ttl: Int!) on FIELD_DEFINITION
+enum OwnDenial {
+    MASK
+    NULL
+    ERROR
+}
+
+directive @own(fields: [String!]! = ["userId"], bypass: [String!]! = [], deny: OwnDenial! = MASK) on FIELD_DEFINITION
 directive @hidden on FIELD_DEFINITION
 
 directive @longtext on FIELD_DEFINITION
@@ -29,4 +38,14 @@ directive @index(name: String) on FIELD_DEFINITION
 directive @unique on FIELD_DEFINITION
 directive @default(value: String!) on FIELD_DEFINITION
 directive @gorm(value: String!) on FIELD_DEFINITION
+directive @version on FIELD_DEFINITION
+directive @softDeletes on OBJECT
+directive @tenant(field: String = "tenantId") on OBJECT
+directive @audited(exclude: [String!]) on OBJECT
+enum PaginateType {
+    CURSOR
+    OFFSET
+}
+
+directive @paginate(type: PaginateType = CURSOR, defaultSize: Int = 15, maxSize: Int = 100) on FIELD_DEFINITION
 directive @loader(keys: [String!], morphKey: String, unionTypes: [String!], extraKeys: [String!]) on OBJECT
\ No newline at end of file
@@ -16,6 +16,10 @@ func StartService() {
 		},
 	}
 	cfg.Directives.Auth = auth.AuthDirective
+	cfg.Directives.Cache = querycache.CacheDirective
+	cfg.Directives.HasRole = policy.HasRoleDirective
+	cfg.Directives.Can = policy.CanDirective
+	cfg.Directives.Own = own.OwnDirective
 
 	srv := handler.New(graph.NewExecutableSchema(cfg))
 	srv.AddTransport(transport.Websocket{KeepAlivePingInterval: 10 * time.Second})
@@ -37,6 +41,7 @@ func StartService() {
 
 	router := routers.NewRouter()
 	router.Use(auth.Middleware())
+	router.Use(databases.StickyMiddleware(nil))
 	router.Use(dataloader.Middleware(db))
 	router.Handle("/", playground.ApolloSandboxHandler("GraphQL playground", "/query"))
 	router.Handle("/query", srv)
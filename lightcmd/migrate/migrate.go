@@ -0,0 +1,46 @@
+package migrate
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+
+	"github.com/light-speak/lighthouse/templates"
+)
+
+//go:embed tpl
+var tpl embed.FS
+
+// InitMigrate 在项目 commands 目录下生成 migrate:* 命令
+func InitMigrate() error {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	return Render(filepath.Join(currentDir, "commands"))
+}
+
+// Render 在 path 下生成 migrate:up / migrate:down / migrate:status / migrate:create / migrate:diff 命令
+func Render(path string) error {
+	migrateTpl, err := tpl.ReadFile("tpl/migrate.tpl")
+	if err != nil {
+		return err
+	}
+	templates.AddImportRegex(`(^|[^A-Za-z])cmd\.`, "github.com/light-speak/lighthouse/lightcmd/cmd", "")
+	templates.AddImportRegex(`(^|[^A-Za-z])migrate\.`, "github.com/light-speak/lighthouse/migrate", "")
+	templates.AddImportRegex(`databases\.`, "github.com/light-speak/lighthouse/databases", "")
+	templates.AddImportRegex(`logs\.`, "github.com/light-speak/lighthouse/logs", "")
+	templates.AddImportRegex(`context\.`, "context", "")
+	templates.AddImportRegex(`fmt\.`, "fmt", "")
+	templates.AddImportRegex(`time\.`, "time", "")
+	options := &templates.Options{
+		Path:         path,
+		Template:     string(migrateTpl),
+		FileName:     "migrate",
+		Package:      "commands",
+		FileExt:      "go",
+		Editable:     false,
+		SkipIfExists: false,
+	}
+	return templates.Render(options)
+}
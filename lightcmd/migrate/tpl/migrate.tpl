@@ -0,0 +1,232 @@
+type MigrateUp struct{}
+
+func (c *MigrateUp) Name() string {
+	return "migrate:up"
+}
+
+func (c *MigrateUp) Usage() string {
+	return "This is a command to apply pending database migrations"
+}
+
+func (c *MigrateUp) Args() []*cmd.CommandArg {
+	return []*cmd.CommandArg{
+		{
+			Name:    "steps",
+			Type:    cmd.Int,
+			Usage:   "The number of migrations to apply, 0 means all",
+			Default: 0,
+		},
+	}
+}
+
+func (c *MigrateUp) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		m, err := newMigrator()
+		if err != nil {
+			return err
+		}
+		steps := 0
+		if s, err := cmd.GetIntArg(flagValues, "steps"); err == nil {
+			steps = *s
+		}
+		applied, err := m.Up(context.Background(), steps)
+		if err != nil {
+			return err
+		}
+		logs.Info().Msgf("Applied %d migrations", len(applied))
+		return nil
+	}
+}
+
+func (c *MigrateUp) OnExit() func() {
+	return func() {}
+}
+
+type MigrateDown struct{}
+
+func (c *MigrateDown) Name() string {
+	return "migrate:down"
+}
+
+func (c *MigrateDown) Usage() string {
+	return "This is a command to roll back applied database migrations"
+}
+
+func (c *MigrateDown) Args() []*cmd.CommandArg {
+	return []*cmd.CommandArg{
+		{
+			Name:    "steps",
+			Type:    cmd.Int,
+			Usage:   "The number of migrations to roll back",
+			Default: 1,
+		},
+	}
+}
+
+func (c *MigrateDown) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		m, err := newMigrator()
+		if err != nil {
+			return err
+		}
+		steps := 1
+		if s, err := cmd.GetIntArg(flagValues, "steps"); err == nil {
+			steps = *s
+		}
+		rolledBack, err := m.Down(context.Background(), steps)
+		if err != nil {
+			return err
+		}
+		logs.Info().Msgf("Rolled back %d migrations", len(rolledBack))
+		return nil
+	}
+}
+
+func (c *MigrateDown) OnExit() func() {
+	return func() {}
+}
+
+type MigrateStatus struct{}
+
+func (c *MigrateStatus) Name() string {
+	return "migrate:status"
+}
+
+func (c *MigrateStatus) Usage() string {
+	return "This is a command to show the status of database migrations"
+}
+
+func (c *MigrateStatus) Args() []*cmd.CommandArg {
+	return []*cmd.CommandArg{}
+}
+
+func (c *MigrateStatus) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		m, err := newMigrator()
+		if err != nil {
+			return err
+		}
+		status, err := m.Status(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			appliedAt := ""
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.DateTime)
+			}
+			fmt.Printf("%-16s %-9s %-20s %s\n", s.Version, s.State, appliedAt, s.Name)
+		}
+		return nil
+	}
+}
+
+func (c *MigrateStatus) OnExit() func() {
+	return func() {}
+}
+
+type MigrateCreate struct{}
+
+func (c *MigrateCreate) Name() string {
+	return "migrate:create"
+}
+
+func (c *MigrateCreate) Usage() string {
+	return "This is a command to create empty up and down migration files"
+}
+
+func (c *MigrateCreate) Args() []*cmd.CommandArg {
+	return []*cmd.CommandArg{
+		{
+			Name:     "name",
+			Type:     cmd.String,
+			Usage:    "The name of the migration, like: create_users",
+			Required: true,
+		},
+	}
+}
+
+func (c *MigrateCreate) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		name, err := cmd.GetStringArg(flagValues, "name")
+		if err != nil {
+			return err
+		}
+		up, down, err := migrate.Create(migrate.DefaultDir, *name)
+		if err != nil {
+			return err
+		}
+		logs.Info().Msgf("Created %s", up)
+		logs.Info().Msgf("Created %s", down)
+		return nil
+	}
+}
+
+func (c *MigrateCreate) OnExit() func() {
+	return func() {}
+}
+
+type MigrateDiff struct{}
+
+func (c *MigrateDiff) Name() string {
+	return "migrate:diff"
+}
+
+func (c *MigrateDiff) Usage() string {
+	return "This is a command to generate a migration from the differences between models and the database"
+}
+
+func (c *MigrateDiff) Args() []*cmd.CommandArg {
+	return []*cmd.CommandArg{
+		{
+			Name:     "name",
+			Type:     cmd.String,
+			Usage:    "The name of the migration, like: add_users_email",
+			Required: true,
+		},
+	}
+}
+
+func (c *MigrateDiff) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		name, err := cmd.GetStringArg(flagValues, "name")
+		if err != nil {
+			return err
+		}
+		m, err := newMigrator()
+		if err != nil {
+			return err
+		}
+		up, down, err := m.Diff(context.Background(), *name, migrate.Models()...)
+		if err != nil {
+			return err
+		}
+		if up == "" {
+			logs.Info().Msg("Database is up to date with models, no migration created")
+			return nil
+		}
+		logs.Info().Msgf("Created %s", up)
+		logs.Info().Msgf("Created %s", down)
+		return nil
+	}
+}
+
+func (c *MigrateDiff) OnExit() func() {
+	return func() {}
+}
+
+func newMigrator() (*migrate.Migrator, error) {
+	db, err := databases.LightDatabaseClient.GetDB(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db), nil
+}
+
+func init() {
+	AddCommand(&MigrateUp{})
+	AddCommand(&MigrateDown{})
+	AddCommand(&MigrateStatus{})
+	AddCommand(&MigrateCreate{})
+	AddCommand(&MigrateDiff{})
+}
@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+
+	"github.com/light-speak/lighthouse/templates"
+)
+
+//go:embed tpl
+var tpl embed.FS
+
+// InitOutbox 在项目 commands 目录下生成 outbox:relay 命令
+func InitOutbox() error {
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	relayTpl, err := tpl.ReadFile("tpl/relay.tpl")
+	if err != nil {
+		return err
+	}
+	templates.AddImportRegex(`(^|[^A-Za-z])cmd\.`, "github.com/light-speak/lighthouse/lightcmd/cmd", "")
+	templates.AddImportRegex(`outbox\.`, "github.com/light-speak/lighthouse/outbox", "")
+	templates.AddImportRegex(`context\.`, "context", "")
+	options := &templates.Options{
+		Path:         filepath.Join(currentDir, "commands"),
+		Template:     string(relayTpl),
+		FileName:     "outbox-relay",
+		Package:      "commands",
+		FileExt:      "go",
+		Editable:     false,
+		SkipIfExists: false,
+	}
+	return templates.Render(options)
+}
@@ -0,0 +1,65 @@
+type OutboxRelay struct {
+	cancel context.CancelFunc
+}
+
+func (c *OutboxRelay) Name() string {
+	return "outbox:relay"
+}
+
+func (c *OutboxRelay) Usage() string {
+	return "This is a command to relay outbox messages to the broker and queue"
+}
+
+func (c *OutboxRelay) Args() []*cmd.CommandArg {
+	return []*cmd.CommandArg{
+		{
+			Name:    "batch",
+			Type:    cmd.Int,
+			Usage:   "The number of messages relayed per batch",
+			Default: 100,
+		},
+		{
+			Name:    "interval",
+			Type:    cmd.Int,
+			Usage:   "The polling interval in milliseconds",
+			Default: 1000,
+		},
+		{
+			Name:    "attempts",
+			Type:    cmd.Int,
+			Usage:   "The maximum delivery attempts before a message is marked as failed",
+			Default: 10,
+		},
+	}
+}
+
+func (c *OutboxRelay) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		relay := outbox.NewRelay()
+		if batch, err := cmd.GetIntArg(flagValues, "batch"); err == nil && *batch > 0 {
+			relay.BatchSize = *batch
+		}
+		if interval, err := cmd.GetIntArg(flagValues, "interval"); err == nil && *interval > 0 {
+			relay.PollInterval = time.Duration(*interval) * time.Millisecond
+		}
+		if attempts, err := cmd.GetIntArg(flagValues, "attempts"); err == nil {
+			relay.MaxAttempts = *attempts
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		c.cancel = cancel
+		return relay.Run(ctx)
+	}
+}
+
+func (c *OutboxRelay) OnExit() func() {
+	return func() {
+		if c.cancel != nil {
+			c.cancel()
+		}
+	}
+}
+
+func init() {
+	AddCommand(&OutboxRelay{})
+}
@@ -0,0 +1,73 @@
+package seeder
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/light-speak/lighthouse/templates"
+	"github.com/light-speak/lighthouse/utils"
+)
+
+//go:embed tpl
+var tpl embed.FS
+
+// GenSeeder 在项目 seeders 目录生成 Seeder，并在 commands 目录生成 db:seed 命令
+// model 不为空时 Seeder 使用 factory 创建 10 个该模型
+func GenSeeder(name string, model string) error {
+	name = strings.TrimSuffix(utils.UcFirst(name), "Seeder")
+	curPath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	module, err := utils.GetModPath(nil)
+	if err != nil {
+		return err
+	}
+
+	seederTpl, err := tpl.ReadFile("tpl/seeder.tpl")
+	if err != nil {
+		return err
+	}
+	templates.AddImportRegex(`(^|[^A-Za-z])factory\.`, "github.com/light-speak/lighthouse/factory", "")
+	templates.AddImportRegex(`(^|[^A-Za-z])models\.`, module+"/models", "")
+	templates.AddImportRegex(`context\.`, "context", "")
+	templates.AddImportRegex(`gorm\.`, "gorm.io/gorm", "")
+	options := &templates.Options{
+		Path:         filepath.Join(curPath, "seeders"),
+		Template:     string(seederTpl),
+		FileName:     utils.SnakeCase(name) + "_seeder",
+		FileExt:      "go",
+		Package:      "seeders",
+		Editable:     true,
+		SkipIfExists: true,
+		Data: map[string]any{
+			"Name":  name,
+			"Model": utils.UcFirst(model),
+		},
+	}
+	if err := templates.Render(options); err != nil {
+		return err
+	}
+
+	seedTpl, err := tpl.ReadFile("tpl/seed.tpl")
+	if err != nil {
+		return err
+	}
+	templates.AddImportRegex(`(^|[^A-Za-z])cmd\.`, "github.com/light-speak/lighthouse/lightcmd/cmd", "")
+	templates.AddImportRegex(`logs\.`, "github.com/light-speak/lighthouse/logs", "")
+	options = &templates.Options{
+		Path:         filepath.Join(curPath, "commands"),
+		Template:     string(seedTpl),
+		FileName:     "db-seed",
+		FileExt:      "go",
+		Package:      "commands",
+		Editable:     false,
+		SkipIfExists: false,
+		Imports: []*templates.Import{
+			{Path: module + "/seeders", Alias: "_"},
+		},
+	}
+	return templates.Render(options)
+}
@@ -0,0 +1,44 @@
+type DbSeed struct{}
+
+func (c *DbSeed) Name() string {
+	return "db:seed"
+}
+
+func (c *DbSeed) Usage() string {
+	return "This is a command to populate the database with seeders"
+}
+
+func (c *DbSeed) Args() []*cmd.CommandArg {
+	return []*cmd.CommandArg{
+		{
+			Name:    "seeder",
+			Type:    cmd.String,
+			Usage:   "The seeders to run, separated by commas, like: UserSeeder,PostSeeder. Empty means all",
+			Default: "",
+		},
+	}
+}
+
+func (c *DbSeed) Action() func(flagValues map[string]interface{}) error {
+	return func(flagValues map[string]interface{}) error {
+		names := make([]string, 0)
+		if s, err := cmd.GetStringArg(flagValues, "seeder"); err == nil && *s != "" {
+			for _, name := range strings.Split(*s, ",") {
+				names = append(names, strings.TrimSpace(name))
+			}
+		}
+		if err := factory.RunSeeders(context.Background(), names...); err != nil {
+			return err
+		}
+		logs.Info().Msg("Database seeding completed")
+		return nil
+	}
+}
+
+func (c *DbSeed) OnExit() func() {
+	return func() {}
+}
+
+func init() {
+	AddCommand(&DbSeed{})
+}
@@ -0,0 +1,19 @@
+type {{ .Name }}Seeder struct{}
+
+func (s *{{ .Name }}Seeder) Name() string {
+	return "{{ .Name }}Seeder"
+}
+
+func (s *{{ .Name }}Seeder) Run(ctx context.Context, db *gorm.DB) error {
+{{- if .Model }}
+	_, err := factory.New[models.{{ .Model }}]().Using(db).Create(ctx, 10)
+	return err
+{{- else }}
+	// 在这里填充数据
+	return nil
+{{- end }}
+}
+
+func init() {
+	factory.AddSeeder(&{{ .Name }}Seeder{})
+}
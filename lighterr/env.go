@@ -1,16 +1,12 @@
 package lighterr
 
 import (
-	"os"
-	"path/filepath"
-
-	"github.com/joho/godotenv"
+	"github.com/light-speak/lighthouse/config"
 	"github.com/light-speak/lighthouse/logs"
-	"github.com/light-speak/lighthouse/utils"
 )
 
 type ErrorConfig struct {
-	Env Env
+	Env Env `env:"APP_ENV"`
 }
 
 type Env string
@@ -20,17 +16,14 @@ const (
 	EnvProduction  Env = "production"
 )
 
-var config *ErrorConfig
+var errorConfig *ErrorConfig
 
 func init() {
-	config = &ErrorConfig{
+	errorConfig = &ErrorConfig{
 		Env: EnvDevelopment,
 	}
-
-	if cp, err := os.Getwd(); err == nil {
-		_ = godotenv.Load(filepath.Join(cp, ".env"))
+	if err := config.Bind(errorConfig); err != nil {
+		logs.Error().Err(err).Msg("invalid error config")
 	}
-
-	config.Env = Env(utils.GetEnv("APP_ENV", string(config.Env)))
-	logs.Debug().Msgf("env: %s", config.Env)
+	logs.Debug().Msgf("env: %s", errorConfig.Env)
 }
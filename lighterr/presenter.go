@@ -29,7 +29,7 @@ func ErrorPresenter(ctx context.Context, e error) *gqlerror.Error {
 			"info": GetCodeInfo(myErr.Code),
 		}
 
-		if config.Env != EnvProduction {
+		if errorConfig.Env != EnvProduction {
 			// Capture stack trace with proper formatting
 			stackTrace := captureStackTrace(5)
 			ext["stack"] = stackTrace
@@ -47,7 +47,7 @@ func ErrorPresenter(ctx context.Context, e error) *gqlerror.Error {
 	}
 
 	// Add stack trace to other errors in development mode
-	if config.Env != EnvProduction {
+	if errorConfig.Env != EnvProduction {
 		if err.Extensions == nil {
 			err.Extensions = map[string]interface{}{}
 		}
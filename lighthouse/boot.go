@@ -0,0 +1,185 @@
+// Package lighthouse 负责按需初始化框架的各个基础组件
+//
+// 各组件都支持首次使用时自动连接，Boot 用于在服务启动时显式连接并尽早暴露配置错误
+package lighthouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/light-speak/lighthouse/config"
+	"github.com/light-speak/lighthouse/databases"
+	"github.com/light-speak/lighthouse/logs"
+	"github.com/light-speak/lighthouse/messaging"
+	"github.com/light-speak/lighthouse/queue"
+	"github.com/light-speak/lighthouse/redis"
+	"github.com/light-speak/lighthouse/storages"
+)
+
+// Component 基础组件
+type Component string
+
+const (
+	ComponentDatabase  Component = "database"
+	ComponentRedis     Component = "redis"
+	ComponentQueue     Component = "queue"
+	ComponentStorage   Component = "storage"
+	ComponentMessaging Component = "messaging"
+)
+
+type options struct {
+	database  *databases.DatabaseConfig
+	redis     *redis.Config
+	queue     *queue.QueueConfig
+	storage   *storages.StorageConfig
+	messaging *messaging.Config
+
+	skip          map[Component]bool
+	retryAttempts int
+	retryInterval time.Duration
+}
+
+// Option Boot 选项
+type Option func(*options)
+
+// WithDatabaseConfig 使用代码中构造的数据库配置，而不是读取 .env
+func WithDatabaseConfig(cfg *databases.DatabaseConfig) Option {
+	return func(o *options) { o.database = cfg }
+}
+
+// WithRedisConfig 使用代码中构造的 Redis 配置
+func WithRedisConfig(cfg *redis.Config) Option {
+	return func(o *options) { o.redis = cfg }
+}
+
+// WithQueueConfig 使用代码中构造的队列配置
+func WithQueueConfig(cfg *queue.QueueConfig) Option {
+	return func(o *options) { o.queue = cfg }
+}
+
+// WithStorageConfig 使用代码中构造的存储配置
+func WithStorageConfig(cfg *storages.StorageConfig) Option {
+	return func(o *options) { o.storage = cfg }
+}
+
+// WithMessagingConfig 使用代码中构造的消息配置
+func WithMessagingConfig(cfg messaging.Config) Option {
+	return func(o *options) { o.messaging = &cfg }
+}
+
+// Skip 跳过指定组件，被跳过的组件仍会在首次使用时自动连接
+func Skip(components ...Component) Option {
+	return func(o *options) {
+		for _, c := range components {
+			o.skip[c] = true
+		}
+	}
+}
+
+// WithRetry 设置每个组件的连接尝试次数和间隔，attempts 小于 1 时按 1 处理
+func WithRetry(attempts int, interval time.Duration) Option {
+	return func(o *options) {
+		o.retryAttempts = attempts
+		o.retryInterval = interval
+	}
+}
+
+// Boot 依次初始化数据库、Redis、队列、存储和消息组件
+// 单个组件失败不会中断其他组件，所有错误合并后返回
+func Boot(ctx context.Context, opts ...Option) error {
+	o := &options{
+		skip:          map[Component]bool{},
+		retryAttempts: 5,
+		retryInterval: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	steps := []struct {
+		component Component
+		init      func() error
+	}{
+		{ComponentDatabase, func() error { return databases.Init(o.database) }},
+		{ComponentRedis, func() error { return redis.Init(o.redis) }},
+		{ComponentQueue, func() error { return queue.Init(o.queue) }},
+		{ComponentStorage, func() error { return storages.Init(o.storage) }},
+		{ComponentMessaging, func() error {
+			if o.messaging != nil {
+				return messaging.Init(*o.messaging)
+			}
+			cfg, err := messaging.LoadConfig()
+			if err != nil {
+				return err
+			}
+			return messaging.Init(cfg)
+		}},
+	}
+
+	var errs []error
+	if err := config.Load(); err != nil {
+		errs = append(errs, fmt.Errorf("config: %w", err))
+	}
+	for _, step := range steps {
+		if o.skip[step.component] {
+			continue
+		}
+		if err := retry(ctx, o.retryAttempts, o.retryInterval, step.component, step.init); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", step.component, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func retry(ctx context.Context, attempts int, interval time.Duration, component Component, fn func() error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for i := 1; i <= attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if i == attempts {
+			break
+		}
+		logs.Warn().Err(err).Str("component", string(component)).Msgf("init failed, retrying in %v (attempt %d/%d)", interval, i, attempts)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+	return err
+}
+
+// Shutdown 关闭所有已建立的连接
+func Shutdown() {
+	// 关闭数据库连接
+	databases.LightDatabaseClient.CloseConnections()
+
+	// 关闭 Redis 连接
+	if redis.LightRedisClient != nil {
+		if err := redis.LightRedisClient.Close(); err != nil {
+			logs.Error().Err(err).Msg("failed to close redis")
+		} else {
+			logs.Info().Msg("redis connection closed")
+		}
+	}
+
+	// 关闭队列客户端
+	if err := queue.CloseClient(); err != nil {
+		logs.Error().Err(err).Msg("failed to close queue client")
+	} else {
+		logs.Info().Msg("queue client closed")
+	}
+
+	// 关闭消息中间件连接
+	if err := messaging.Close(); err != nil {
+		logs.Error().Err(err).Msg("failed to close messaging broker")
+	} else {
+		logs.Info().Msg("messaging broker closed")
+	}
+}
@@ -0,0 +1,99 @@
+package lighthouse
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/light-speak/lighthouse/databases"
+	"gorm.io/gorm/logger"
+)
+
+var others = Skip(ComponentRedis, ComponentQueue, ComponentStorage, ComponentMessaging)
+
+func TestBoot(t *testing.T) {
+	t.Run("Test Boot And Shutdown", func(t *testing.T) {
+		cfg := &databases.DatabaseConfig{Driver: databases.DriverSQLite, Name: ":memory:", LogLevel: logger.Silent}
+		if err := Boot(context.Background(), WithDatabaseConfig(cfg), others, WithRetry(1, 0)); err != nil {
+			t.Fatal(err)
+		}
+		db, err := databases.LightDatabaseClient.GetDB(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Exec("SELECT 1").Error; err != nil {
+			t.Fatal(err)
+		}
+		Shutdown()
+		if err := db.Exec("SELECT 1").Error; err == nil {
+			t.Error("connection should be closed after Shutdown")
+		}
+		if _, err := databases.LightDatabaseClient.GetDB(context.Background()); !errors.Is(err, databases.ErrClosed) {
+			t.Errorf("GetDB() after Shutdown error = %v, expected ErrClosed", err)
+		}
+		if err := Boot(context.Background(), WithDatabaseConfig(cfg), others, WithRetry(1, 0)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := databases.LightDatabaseClient.GetDB(context.Background()); err != nil {
+			t.Errorf("GetDB() after Boot error = %v", err)
+		}
+		Shutdown()
+	})
+
+	t.Run("Test Boot Reports Component", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "missing", "app.db")
+		cfg := &databases.DatabaseConfig{Driver: databases.DriverSQLite, Name: name, LogLevel: logger.Silent}
+		err := Boot(context.Background(), WithDatabaseConfig(cfg), others, WithRetry(2, time.Millisecond))
+		if err == nil || !strings.HasPrefix(err.Error(), string(ComponentDatabase)+":") {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	errFail := errors.New("fail")
+
+	tests := []struct {
+		name     string
+		attempts int
+		failures int
+		expected int
+		err      bool
+	}{
+		{"Test Success", 3, 0, 1, false},
+		{"Test Success After Retry", 3, 2, 3, false},
+		{"Test Gives Up", 3, 5, 3, true},
+		{"Test At Least One Attempt", 0, 5, 1, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			err := retry(context.Background(), test.attempts, time.Millisecond, ComponentDatabase, func() error {
+				calls++
+				if calls <= test.failures {
+					return errFail
+				}
+				return nil
+			})
+			if calls != test.expected || (err != nil) != test.err {
+				t.Errorf("calls = %d, err = %v", calls, err)
+			}
+		})
+	}
+
+	t.Run("Test Context Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		calls := 0
+		err := retry(ctx, 5, time.Hour, ComponentDatabase, func() error {
+			calls++
+			return errFail
+		})
+		if calls != 1 || !errors.Is(err, context.Canceled) || !errors.Is(err, errFail) {
+			t.Errorf("calls = %d, err = %v", calls, err)
+		}
+	})
+}
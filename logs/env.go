@@ -1,30 +1,26 @@
 package logs
 
 import (
-	"log"
-	"os"
-	"path/filepath"
-
-	"github.com/joho/godotenv"
-	"github.com/light-speak/lighthouse/utils"
+	"github.com/light-speak/lighthouse/config"
 	"github.com/rs/zerolog"
 )
 
 type LoggerConfig struct {
 	Level      zerolog.Level
-	TimeFormat string
-	Caller     bool
-	Console    bool
-	File       bool
-	Pretty     bool
-	FilePath   string
+	LevelName  string `env:"LOG_LEVEL"`
+	TimeFormat string `env:"LOG_TIME_FORMAT"`
+	Caller     bool   `env:"LOG_CALLER"`
+	Console    bool   `env:"LOG_CONSOLE"`
+	File       bool   `env:"LOG_FILE"`
+	Pretty     bool   `env:"LOG_PRETTY"`
+	FilePath   string `env:"LOG_FILE_PATH"`
 }
 
 var loggerConfig *LoggerConfig
 
-func InitLogger() error {
-	loggerConfig = &LoggerConfig{
-		Level:      zerolog.InfoLevel,
+func loadConfig() (*LoggerConfig, error) {
+	cfg := &LoggerConfig{
+		LevelName:  "info",
 		TimeFormat: "2006-01-02 15:04:05",
 		Caller:     false,
 		Console:    true,
@@ -32,23 +28,28 @@ func InitLogger() error {
 		FilePath:   "logs/logs.log",
 		Pretty:     true,
 	}
+	err := config.Bind(cfg)
+	cfg.Level = getLogLevel(cfg.LevelName)
+	return cfg, err
+}
 
-	if curPath, err := os.Getwd(); err == nil {
-		err = godotenv.Load(filepath.Join(curPath, ".env"))
-		if err != nil {
-			log.Println("Error loading .env file:", err)
-		}
-	}
-	loggerConfig.Level = getLogLevel(utils.GetEnv("LOG_LEVEL", "info"))
-	loggerConfig.TimeFormat = utils.GetEnv("LOG_TIME_FORMAT", loggerConfig.TimeFormat)
-	loggerConfig.Caller = utils.GetEnvBool("LOG_CALLER", loggerConfig.Caller)
-	loggerConfig.Console = utils.GetEnvBool("LOG_CONSOLE", loggerConfig.Console)
-	loggerConfig.File = utils.GetEnvBool("LOG_FILE", loggerConfig.File)
-	loggerConfig.FilePath = utils.GetEnv("LOG_FILE_PATH", loggerConfig.FilePath)
-	loggerConfig.Pretty = utils.GetEnvBool("LOG_PRETTY", loggerConfig.Pretty)
+func InitLogger() error {
+	cfg, err := loadConfig()
+	loggerConfig = cfg
 
 	currentOutputs = nil // Reset outputs on init
-	return setupLogger()
+	if setupErr := setupLogger(); setupErr != nil {
+		return setupErr
+	}
+	// 配置错误不影响日志输出，无效的配置项保留默认值
+	if err != nil {
+		Log.Error().Err(err).Msg("invalid log config")
+	}
+	return nil
+}
+
+func init() {
+	config.Register("log", func() (any, error) { return loadConfig() })
 }
 
 func getLogLevel(level string) zerolog.Level {
@@ -12,6 +12,11 @@ type Broker interface {
 	Close() error
 }
 
+// IdempotentBroker 支持按消息 ID 去重的 Broker，重复投递同一个 ID 的消息只会被保留一次
+type IdempotentBroker interface {
+	PublishWithID(topic string, msgID string, payload []byte) error
+}
+
 func resolveSubscriberOption(topic string, opts ...SubscriberOption) SubscriberOption {
 	if len(opts) > 0 {
 		return opts[0]
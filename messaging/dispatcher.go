@@ -25,21 +25,23 @@ func SubscribeTyped[T any](ctx context.Context, topic string, handler func(T) er
 }
 
 func subscribe(ctx context.Context, topic string, handler func([]byte) error, opt SubscriberOption) error {
-	if broker == nil {
-		return lighterr.NewServiceUnavailableError("broker not initialized")
+	b, err := getBroker()
+	if err != nil {
+		return err
 	}
-	_, err := broker.Subscribe(ctx, topic, handler, opt)
+	_, err = b.Subscribe(ctx, topic, handler, opt)
 	return err
 }
 
 func PublishTyped[T any](ctx context.Context, topic string, msg T) error {
-	if broker == nil {
-		return lighterr.NewServiceUnavailableError("broker not initialized")
+	b, err := getBroker()
+	if err != nil {
+		return err
 	}
 	raw, err := sonic.Marshal(msg)
 	if err != nil {
 		logs.Error().Err(err).Msg("failed to marshal message")
 		return lighterr.NewInternalError("failed to marshal message", err)
 	}
-	return broker.Publish(topic, raw)
+	return b.Publish(topic, raw)
 }
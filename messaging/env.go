@@ -1,15 +1,6 @@
 package messaging
 
-import (
-	"log"
-	"os"
-	"path/filepath"
-
-	"github.com/joho/godotenv"
-	"github.com/light-speak/lighthouse/utils"
-)
-
-var cfg Config
+import "github.com/light-speak/lighthouse/config"
 
 type Driver string
 
@@ -19,30 +10,32 @@ const (
 )
 
 type Config struct {
-	Driver     Driver
-	URL        string
-	InstanceID string
+	// Enable 为 false 时不连接消息中间件，GetBroker 返回 nil
+	Enable     bool   `env:"MESSAGING_ENABLE"`
+	Driver     Driver `env:"MESSAGING_DRIVER"`
+	URL        string `env:"MESSAGING_URL"`
+	InstanceID string `env:"HOSTNAME"`
 }
 
-func init() {
-	cfg = Config{
+// DefaultConfig 返回默认配置
+func DefaultConfig() Config {
+	return Config{
+		Enable:     true,
 		Driver:     DriverNats,
 		URL:        "localhost:4222",
 		InstanceID: "hostname",
 	}
+}
 
-	if curPath, err := os.Getwd(); err == nil {
-		err = godotenv.Load(filepath.Join(curPath, ".env"))
-		if err != nil {
-			log.Println("Error loading .env file:", err)
-		}
+// LoadConfig 从配置文件和环境变量读取配置
+func LoadConfig() (Config, error) {
+	cfg := DefaultConfig()
+	if err := config.Bind(&cfg); err != nil {
+		return cfg, err
 	}
+	return cfg, nil
+}
 
-	cfg.Driver = Driver(utils.GetEnv("MESSAGING_DRIVER", string(cfg.Driver)))
-	cfg.URL = utils.GetEnv("MESSAGING_URL", cfg.URL)
-	cfg.InstanceID = utils.GetEnv("HOSTNAME", cfg.InstanceID)
-
-	if cfg.Driver == "" {
-		log.Fatal("MESSAGING_DRIVER is not set")
-	}
+func init() {
+	config.Register("messaging", func() (any, error) { return LoadConfig() })
 }
@@ -1,24 +1,129 @@
 package messaging
 
-import "log"
+import (
+	"fmt"
+	"sync"
+	"time"
 
-var broker Broker
+	"github.com/light-speak/lighthouse/lighterr"
+	"github.com/light-speak/lighthouse/logs"
+)
 
-func init() {
-	var b Broker
+// lazyRetryInterval 首次使用时连接失败后，再次尝试连接的最小间隔
+const lazyRetryInterval = 5 * time.Second
+
+var errDisabled = lighterr.NewServiceUnavailableError("messaging is not enabled")
+
+// ErrClosed 调用 Close（lighthouse.Shutdown）之后获取 Broker 时返回，再次调用 Init 后恢复
+var ErrClosed = lighterr.NewServiceUnavailableError("messaging is closed")
+
+var (
+	broker  Broker
+	current Config
+
+	mu          sync.Mutex
+	loaded      bool
+	closed      bool // 已关闭，不再自动重连
+	lastAttempt time.Time
+	lastErr     error
+)
+
+// Init 使用给定配置连接消息中间件，已有连接会先关闭
+func Init(cfg Config) error {
+	mu.Lock()
+	defer mu.Unlock()
+	closed = false
+	return initBroker(cfg)
+}
+
+// initBroker 调用方需持有 mu
+func initBroker(cfg Config) error {
+	current = cfg
+	loaded = true
+	lastAttempt = time.Now()
+	if broker != nil {
+		_ = broker.Close()
+		broker = nil
+	}
+	if !cfg.Enable {
+		lastErr = nil
+		return nil
+	}
 
+	var b Broker
 	switch cfg.Driver {
 	case DriverNats:
 		b = &NatsBroker{}
 	case DriverKafka:
-		log.Fatal("Kafka is not implemented")
+		lastErr = lighterr.NewServiceUnavailableError("kafka broker is not implemented")
+		return lastErr
+	default:
+		lastErr = fmt.Errorf("unsupported messaging driver: %s", cfg.Driver)
+		return lastErr
 	}
 	if err := b.Init(cfg); err != nil {
-		log.Fatal("failed to initialize broker", err)
+		lastErr = err
+		return err
 	}
 	broker = b
+	lastErr = nil
+	return nil
+}
+
+// getBroker 首次使用时按 .env 连接，失败后按 lazyRetryInterval 限制重试频率
+func getBroker() (Broker, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if closed {
+		return nil, ErrClosed
+	}
+	if broker != nil {
+		return broker, nil
+	}
+
+	cfg := current
+	if !loaded {
+		loadedCfg, err := LoadConfig()
+		if err != nil {
+			return nil, err
+		}
+		cfg = loadedCfg
+	} else if lastErr != nil && time.Since(lastAttempt) < lazyRetryInterval {
+		return nil, lastErr
+	}
+	if !loaded || lastErr != nil {
+		if err := initBroker(cfg); err != nil {
+			return nil, err
+		}
+	}
+	if broker == nil {
+		return nil, errDisabled
+	}
+	return broker, nil
 }
 
+// GetBroker 返回当前 Broker，未启用、已关闭或连接失败时返回 nil
 func GetBroker() Broker {
-	return broker
+	b, err := getBroker()
+	if err == errDisabled || err == ErrClosed {
+		return nil
+	}
+	if err != nil {
+		logs.Error().Err(err).Msg("failed to initialize broker")
+		return nil
+	}
+	return b
+}
+
+// Close 关闭当前 Broker，之后不再自动重连，直到再次调用 Init
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	closed = true
+	if broker == nil {
+		return nil
+	}
+	err := broker.Close()
+	broker = nil
+	return err
 }
@@ -52,6 +52,12 @@ func (n *NatsBroker) Publish(topic string, payload []byte) error {
 	return err
 }
 
+// PublishWithID 发布消息并设置 Nats-Msg-Id，JetStream 在去重窗口内会丢弃相同 ID 的消息
+func (n *NatsBroker) PublishWithID(topic string, msgID string, payload []byte) error {
+	_, err := n.js.Publish(fullSubject(topic), payload, nats.MsgId(msgID))
+	return err
+}
+
 func (n *NatsBroker) Subscribe(ctx context.Context, topic string, handler func(msg []byte) error, opts ...SubscriberOption) (func(), error) {
 	subId := resolveSubscriptionID(fullSubject(topic), n.instanceID, opts...)
 	var sub *nats.Subscription
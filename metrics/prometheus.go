@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -26,11 +28,51 @@ var (
 		},
 		[]string{"operation", "type"},
 	)
+
+	// DBQueryDuration 按操作类型、连接与 SQL 指纹统计的查询耗时
+	// 只有出现在慢查询记录中的指纹会作为标签，其他查询为 other，指纹移出慢查询记录时删除对应的序列
+	DBQueryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "lighthouse",
+			Subsystem: "database",
+			Name:      "query_duration_seconds",
+			Help:      "Database query latency by operation, connection and slow query fingerprint",
+			Buckets:   []float64{.001, .005, .01, .025, .05, .1, .2, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"operation", "connection", "fingerprint"},
+	)
 )
 
+var (
+	collectorMu sync.Mutex
+	collectors  []prometheus.Collector
+	initialized bool
+)
+
+// RegisterCollector 注册自定义采集器，Init 之前注册的会在 Init 时统一注册，之后注册的立即生效
+// 各组件在 init 中调用，只有开启 metrics 的服务才会真正暴露这些指标
+func RegisterCollector(c prometheus.Collector) {
+	collectorMu.Lock()
+	defer collectorMu.Unlock()
+	if initialized {
+		prometheus.MustRegister(c)
+		return
+	}
+	collectors = append(collectors, c)
+}
+
 func Init() {
+	collectorMu.Lock()
+	defer collectorMu.Unlock()
+	if initialized {
+		return
+	}
+	initialized = true
+
 	prometheus.MustRegister(
 		GQLResolverDuration,
 		GQLOperationTotal,
+		DBQueryDuration,
 	)
+	prometheus.MustRegister(collectors...)
 }
@@ -0,0 +1,194 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// 模型与数据库结构对比
+// 复用 gorm AutoMigrate 的对比逻辑：查询语句（information_schema、sqlite_master 等）在数据库上执行，
+// DDL 语句只记录不执行，记录下来的语句即为 up 迁移；新建的表、列和索引生成对应的 down 迁移
+// 与 AutoMigrate 一样不会删除模型中已不存在的表、列和索引
+
+var (
+	modelsMu sync.RWMutex
+	models   = make([]any, 0)
+)
+
+// RegisterModels 登记 migrate:diff 对比的模型，同一类型重复登记时只保留一个
+// generate:schema 生成的 models/migrate_gen.go 会登记所有带 id 字段的类型
+func RegisterModels(values ...any) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	for _, value := range values {
+		if !registeredModel(value) {
+			models = append(models, value)
+		}
+	}
+}
+
+// registeredModel 调用方需持有 modelsMu
+func registeredModel(value any) bool {
+	t := reflect.TypeOf(value)
+	for _, m := range models {
+		if reflect.TypeOf(m) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Models 返回登记的模型
+func Models() []any {
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+	return append([]any(nil), models...)
+}
+
+// Plan 模型与数据库的差异
+type Plan struct {
+	Up   []string
+	Down []string
+}
+
+// Empty 模型与数据库一致
+func (p *Plan) Empty() bool {
+	return len(p.Up) == 0
+}
+
+// Diff 对比模型与数据库，返回使数据库与模型一致的语句
+func Diff(ctx context.Context, db *gorm.DB, values ...any) (*Plan, error) {
+	live := db.Session(&gorm.Session{NewDB: true, Context: ctx})
+	rec := &recorder{ConnPool: live.Statement.ConnPool, dialector: db.Dialector}
+	dry := db.Session(&gorm.Session{NewDB: true, Context: ctx, Logger: logger.Discard})
+	dry.Statement.ConnPool = rec
+
+	// down：先删除新建的索引和列，再按依赖倒序删除新建的表
+	tables := make([]any, 0)
+	for _, value := range values {
+		stmt := &gorm.Statement{DB: live}
+		if err := stmt.Parse(value); err != nil {
+			return nil, fmt.Errorf("parse model %T: %w", value, err)
+		}
+		if !live.Migrator().HasTable(value) {
+			tables = append(tables, value)
+			continue
+		}
+		columnTypes, err := live.Migrator().ColumnTypes(value)
+		if err != nil {
+			return nil, err
+		}
+		columns := make(map[string]bool, len(columnTypes))
+		for _, columnType := range columnTypes {
+			columns[strings.ToLower(columnType.Name())] = true
+		}
+		for _, index := range stmt.Schema.ParseIndexes() {
+			if !live.Migrator().HasIndex(value, index.Name) {
+				if err := dry.Migrator().DropIndex(value, index.Name); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for _, name := range stmt.Schema.DBNames {
+			if !columns[strings.ToLower(name)] {
+				if err := dry.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: stmt.Table}, clause.Column{Name: name}).Error; err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if len(tables) > 0 {
+		if err := dry.Migrator().DropTable(tables...); err != nil {
+			return nil, err
+		}
+	}
+	down := rec.take()
+
+	if err := dry.Migrator().AutoMigrate(values...); err != nil {
+		return nil, err
+	}
+	return &Plan{Up: rec.take(), Down: down}, nil
+}
+
+// Diff 对比模型与数据库，有差异时在迁移目录生成 up / down 文件，没有差异时返回空路径
+func (m *Migrator) Diff(ctx context.Context, name string, values ...any) (string, string, error) {
+	plan, err := Diff(ctx, m.db, values...)
+	if err != nil {
+		return "", "", err
+	}
+	if plan.Empty() {
+		return "", "", nil
+	}
+	up, down, err := Create(m.opts.dir, name)
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(up, []byte(join(plan.Up)), 0644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(down, []byte("-- 修改列的语句不会自动生成回滚，请检查\n"+join(plan.Down)), 0644); err != nil {
+		return "", "", err
+	}
+	return up, down, nil
+}
+
+func join(statements []string) string {
+	var sb strings.Builder
+	for _, stmt := range statements {
+		sb.WriteString(strings.TrimRight(strings.TrimSpace(stmt), ";"))
+		sb.WriteString(";\n")
+	}
+	return sb.String()
+}
+
+// recorder 查询透传到数据库，执行的语句只记录
+type recorder struct {
+	gorm.ConnPool
+	dialector  gorm.Dialector
+	mu         sync.Mutex
+	statements []string
+}
+
+func (r *recorder) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if len(args) > 0 {
+		query = r.dialector.Explain(query, args...)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, query)
+	return driverResult{}, nil
+}
+
+// BeginTx sqlite 修改列时会在事务中重建表，事务中的语句同样只记录
+func (r *recorder) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &recorderTx{r}, nil
+}
+
+func (r *recorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statements := r.statements
+	r.statements = nil
+	return statements
+}
+
+type recorderTx struct {
+	*recorder
+}
+
+func (t *recorderTx) Commit() error   { return nil }
+func (t *recorderTx) Rollback() error { return nil }
+
+type driverResult struct{}
+
+func (driverResult) LastInsertId() (int64, error) { return 0, nil }
+func (driverResult) RowsAffected() (int64, error) { return 0, nil }
@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lock 获取数据库级别的咨询锁，防止多个 Pod 同时执行迁移
+// 锁与连接绑定，conn 需要是同一个连接（gorm.DB.Connection）
+// MySQL 使用 GET_LOCK，PostgreSQL 使用 pg_advisory_lock，SQLite 为单文件数据库，不加锁
+func lock(ctx context.Context, conn *gorm.DB, name string, timeout time.Duration) (func(), error) {
+	switch conn.Dialector.Name() {
+	case "mysql":
+		var acquired *int
+		seconds := int(timeout.Seconds())
+		if err := conn.WithContext(ctx).Raw("SELECT GET_LOCK(?, ?)", name, seconds).Scan(&acquired).Error; err != nil {
+			return nil, err
+		}
+		if acquired == nil || *acquired != 1 {
+			return nil, fmt.Errorf("failed to acquire migration lock %s within %s, another migration may be running", name, timeout)
+		}
+		return func() {
+			conn.Exec("SELECT RELEASE_LOCK(?)", name)
+		}, nil
+	case "postgres":
+		key := int64(crc32.ChecksumIEEE([]byte(name)))
+		deadline := time.Now().Add(timeout)
+		for {
+			var acquired bool
+			if err := conn.WithContext(ctx).Raw("SELECT pg_try_advisory_lock(?)", key).Scan(&acquired).Error; err != nil {
+				return nil, err
+			}
+			if acquired {
+				return func() {
+					conn.Exec("SELECT pg_advisory_unlock(?)", key)
+				}, nil
+			}
+			if time.Now().After(deadline) {
+				return nil, fmt.Errorf("failed to acquire migration lock %s within %s, another migration may be running", name, timeout)
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+	default:
+		return func() {}, nil
+	}
+}
@@ -0,0 +1,256 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/light-speak/lighthouse/logs"
+	"gorm.io/gorm"
+)
+
+// 数据库迁移
+// 按版本执行 migrations 目录中的 SQL 文件，执行记录保存在 schema_migrations 表，
+// 已执行的文件被修改（校验和不一致）时拒绝继续迁移；执行期间持有数据库锁，多个 Pod 同时启动时只有一个会执行
+
+const (
+	DefaultDir         = "migrations"
+	DefaultTable       = "schema_migrations"
+	DefaultLockTimeout = time.Minute
+)
+
+// State 迁移状态
+type State string
+
+const (
+	StateApplied  State = "applied"  // 已执行
+	StatePending  State = "pending"  // 未执行
+	StateModified State = "modified" // 已执行，但文件在执行后被修改
+	StateMissing  State = "missing"  // 已执行，但文件已不存在
+)
+
+// History 迁移记录
+type History struct {
+	Version   string    `gorm:"type:varchar(64);primaryKey"`
+	Name      string    `gorm:"type:varchar(191);not null;default:''"`
+	Checksum  string    `gorm:"type:varchar(64);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// Status 一个版本的迁移状态
+type Status struct {
+	Version   string
+	Name      string
+	State     State
+	AppliedAt *time.Time
+}
+
+type options struct {
+	dir         string
+	table       string
+	lockTimeout time.Duration
+}
+
+// Option 迁移选项
+type Option func(o *options)
+
+// WithDir 迁移文件目录，默认 migrations
+func WithDir(dir string) Option {
+	return func(o *options) {
+		o.dir = dir
+	}
+}
+
+// WithTable 迁移记录表，默认 schema_migrations
+func WithTable(table string) Option {
+	return func(o *options) {
+		o.table = table
+	}
+}
+
+// WithLockTimeout 等待其他迁移释放锁的最长时间，默认 1 分钟
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.lockTimeout = timeout
+	}
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db   *gorm.DB
+	opts *options
+}
+
+// New 创建迁移执行器
+func New(db *gorm.DB, opts ...Option) *Migrator {
+	o := &options{dir: DefaultDir, table: DefaultTable, lockTimeout: DefaultLockTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Migrator{db: db, opts: o}
+}
+
+// Up 执行未执行的迁移，steps <= 0 时全部执行，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context, steps int) ([]*Migration, error) {
+	applied := make([]*Migration, 0)
+	err := m.run(ctx, func(conn *gorm.DB, migrations []*Migration, history map[string]*History) error {
+		for _, migration := range migrations {
+			if h, ok := history[migration.Version]; ok && h.Checksum != migration.Checksum {
+				return fmt.Errorf("migration %s has been modified after it was applied", migration.Version)
+			}
+		}
+		for _, migration := range migrations {
+			if _, ok := history[migration.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(applied) >= steps {
+				break
+			}
+			logs.Info().Str("version", migration.Version).Str("name", migration.Name).Msg("applying migration")
+			err := m.exec(ctx, conn, migration.Up, func(tx *gorm.DB) error {
+				return tx.Table(m.opts.table).Create(&History{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					Checksum:  migration.Checksum,
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("apply migration %s: %w", migration.Version, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 按版本倒序回滚已执行的迁移，steps <= 0 时回滚 1 个，返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	rolledBack := make([]*Migration, 0)
+	err := m.run(ctx, func(conn *gorm.DB, migrations []*Migration, history map[string]*History) error {
+		files := make(map[string]*Migration, len(migrations))
+		for _, migration := range migrations {
+			files[migration.Version] = migration
+		}
+		versions := make([]string, 0, len(history))
+		for version := range history {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versionLess(versions[j], versions[i]) })
+
+		for _, version := range versions {
+			if len(rolledBack) >= steps {
+				break
+			}
+			migration, ok := files[version]
+			if !ok {
+				return fmt.Errorf("migration %s is applied but its file is missing", version)
+			}
+			if !migration.HasDown {
+				return fmt.Errorf("migration %s has no down file", version)
+			}
+			logs.Info().Str("version", migration.Version).Str("name", migration.Name).Msg("rolling back migration")
+			err := m.exec(ctx, conn, migration.Down, func(tx *gorm.DB) error {
+				return tx.Table(m.opts.table).Where("version = ?", version).Delete(&History{}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("roll back migration %s: %w", version, err)
+			}
+			rolledBack = append(rolledBack, migration)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status 返回所有迁移的状态，按版本排序
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	migrations, err := Load(m.opts.dir)
+	if err != nil {
+		return nil, err
+	}
+	history, err := m.history(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Status, 0, len(migrations))
+	seen := make(map[string]bool, len(migrations))
+	for _, migration := range migrations {
+		seen[migration.Version] = true
+		status := &Status{Version: migration.Version, Name: migration.Name, State: StatePending}
+		if h, ok := history[migration.Version]; ok {
+			status.State = StateApplied
+			if h.Checksum != migration.Checksum {
+				status.State = StateModified
+			}
+			status.AppliedAt = &h.AppliedAt
+		}
+		result = append(result, status)
+	}
+	for version, h := range history {
+		if !seen[version] {
+			result = append(result, &Status{Version: version, Name: h.Name, State: StateMissing, AppliedAt: &h.AppliedAt})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return versionLess(result[i].Version, result[j].Version) })
+	return result, nil
+}
+
+// run 在同一个连接上加锁、读取迁移文件和记录后执行 fn
+func (m *Migrator) run(ctx context.Context, fn func(conn *gorm.DB, migrations []*Migration, history map[string]*History) error) error {
+	migrations, err := Load(m.opts.dir)
+	if err != nil {
+		return err
+	}
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		unlock, err := lock(ctx, conn, "lighthouse:migrate:"+m.opts.table, m.opts.lockTimeout)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		history, err := m.history(conn)
+		if err != nil {
+			return err
+		}
+		return fn(conn, migrations, history)
+	})
+}
+
+// history 读取迁移记录，记录表不存在时创建
+func (m *Migrator) history(db *gorm.DB) (map[string]*History, error) {
+	if !db.Migrator().HasTable(m.opts.table) {
+		if err := db.Table(m.opts.table).AutoMigrate(&History{}); err != nil {
+			return nil, fmt.Errorf("create migration table %s: %w", m.opts.table, err)
+		}
+	}
+	records := make([]*History, 0)
+	if err := db.Table(m.opts.table).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	history := make(map[string]*History, len(records))
+	for _, record := range records {
+		history[record.Version] = record
+	}
+	return history, nil
+}
+
+// exec 在事务中逐条执行迁移语句并更新记录
+// MySQL 的 DDL 会隐式提交，失败时已执行的语句无法回滚，需要手动处理
+func (m *Migrator) exec(ctx context.Context, conn *gorm.DB, sql string, record func(tx *gorm.DB) error) error {
+	return conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range split(sql) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("%w\n%s", err, strings.TrimSpace(stmt))
+			}
+		}
+		return record(tx)
+	})
+}
@@ -0,0 +1,188 @@
+package migrate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected []string
+	}{
+		{"Test Split Statements", "CREATE TABLE a (id int);\nCREATE TABLE b (id int);\n", []string{"CREATE TABLE a (id int)", "CREATE TABLE b (id int)"}},
+		{"Test Split Quoted Semicolon", "INSERT INTO a VALUES ('x;y');", []string{"INSERT INTO a VALUES ('x;y')"}},
+		{"Test Split Comments", "-- drop; later\nDROP TABLE a; /* ; */", []string{"-- drop; later\nDROP TABLE a"}},
+		{"Test Split Dollar Quote", "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;", []string{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql"}},
+		{"Test Split Only Comments", "-- nothing here\n", []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := split(test.sql); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("split() = %q; expected %q", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"20240101000000_create_users.up.sql":   "CREATE TABLE users (id integer primary key, name text);\nINSERT INTO users (name) VALUES ('alice');",
+		"20240101000000_create_users.down.sql": "DROP TABLE users;",
+		"20240102000000_create_posts.up.sql":   "CREATE TABLE posts (id integer primary key);",
+		"20240102000000_create_posts.down.sql": "DROP TABLE posts;",
+		"20240103000000.sql":                   "CREATE TABLE tags (id integer primary key);",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+	m := New(db, WithDir(dir))
+
+	states := func() []State {
+		status, err := m.Status(ctx)
+		if err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		result := make([]State, len(status))
+		for i, s := range status {
+			result[i] = s.State
+		}
+		return result
+	}
+
+	t.Run("Test Up Steps", func(t *testing.T) {
+		applied, err := m.Up(ctx, 2)
+		if err != nil {
+			t.Fatalf("Up() error = %v", err)
+		}
+		if len(applied) != 2 {
+			t.Errorf("applied = %d; expected 2", len(applied))
+		}
+		if actual := states(); !reflect.DeepEqual(actual, []State{StateApplied, StateApplied, StatePending}) {
+			t.Errorf("states = %v", actual)
+		}
+	})
+
+	t.Run("Test Down", func(t *testing.T) {
+		if _, err := m.Down(ctx, 1); err != nil {
+			t.Fatalf("Down() error = %v", err)
+		}
+		if db.Migrator().HasTable("posts") {
+			t.Error("posts should be dropped")
+		}
+		if actual := states(); !reflect.DeepEqual(actual, []State{StateApplied, StatePending, StatePending}) {
+			t.Errorf("states = %v", actual)
+		}
+	})
+
+	t.Run("Test Up All", func(t *testing.T) {
+		applied, err := m.Up(ctx, 0)
+		if err != nil {
+			t.Fatalf("Up() error = %v", err)
+		}
+		if len(applied) != 2 || !db.Migrator().HasTable("tags") {
+			t.Errorf("applied = %d; expected 2", len(applied))
+		}
+	})
+
+	t.Run("Test Down Without Down File", func(t *testing.T) {
+		if _, err := m.Down(ctx, 1); err == nil {
+			t.Error("Down() expected error for migration without down file")
+		}
+	})
+
+	t.Run("Test Checksum Mismatch", func(t *testing.T) {
+		path := filepath.Join(dir, "20240101000000_create_users.up.sql")
+		if err := os.WriteFile(path, []byte("CREATE TABLE users (id integer primary key);"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Up(ctx, 0); err == nil {
+			t.Error("Up() expected error for modified migration")
+		}
+		if actual := states()[0]; actual != StateModified {
+			t.Errorf("state = %s; expected %s", actual, StateModified)
+		}
+	})
+}
+
+type diffUser struct {
+	ID    uint   `gorm:"primaryKey"`
+	Name  string `gorm:"type:varchar(100);not null;default:''"`
+	Email string `gorm:"type:varchar(191);uniqueIndex"`
+}
+
+type diffPost struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"index"`
+}
+
+func TestDiff(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.Exec("CREATE TABLE diff_users (id integer primary key, name varchar(100) NOT NULL DEFAULT '')").Error; err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	m := New(db, WithDir(t.TempDir()))
+
+	t.Run("Test Diff Plan", func(t *testing.T) {
+		plan, err := Diff(ctx, db, &diffUser{}, &diffPost{})
+		if err != nil {
+			t.Fatalf("Diff() error = %v", err)
+		}
+		up := strings.Join(plan.Up, "\n")
+		for _, expected := range []string{"ADD `email`", "CREATE UNIQUE INDEX `idx_diff_users_email`", "CREATE TABLE `diff_posts`", "CREATE INDEX `idx_diff_posts_user_id`"} {
+			if !strings.Contains(up, expected) {
+				t.Errorf("up = %s; expected to contain %s", up, expected)
+			}
+		}
+		if !db.Migrator().HasTable("diff_users") || db.Migrator().HasTable("diff_posts") || db.Migrator().HasColumn("diff_users", "email") {
+			t.Error("Diff() should not change the database")
+		}
+	})
+
+	t.Run("Test Diff Apply And Rollback", func(t *testing.T) {
+		up, _, err := m.Diff(ctx, "add email", &diffUser{}, &diffPost{})
+		if err != nil || up == "" {
+			t.Fatalf("Diff() = %q, %v", up, err)
+		}
+		if _, err := m.Up(ctx, 0); err != nil {
+			t.Fatalf("Up() error = %v", err)
+		}
+		if !db.Migrator().HasColumn("diff_users", "email") || !db.Migrator().HasTable("diff_posts") {
+			t.Error("Up() should apply the diff")
+		}
+		plan, err := Diff(ctx, db, &diffUser{}, &diffPost{})
+		if err != nil || !plan.Empty() {
+			t.Errorf("Diff() after up = %v, %v; expected empty", plan.Up, err)
+		}
+		if _, err := m.Down(ctx, 1); err != nil {
+			t.Fatalf("Down() error = %v", err)
+		}
+		if db.Migrator().HasColumn("diff_users", "email") || db.Migrator().HasTable("diff_posts") {
+			t.Error("Down() should revert the diff")
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Migration 一个版本的迁移
+// 文件名为 <版本>_<名称>.up.sql / <版本>_<名称>.down.sql，
+// 只有 <版本>[_<名称>].sql 时视为只有 up 的迁移（如 Atlas 生成的文件），不能回滚
+type Migration struct {
+	Version  string
+	Name     string
+	Up       string
+	Down     string
+	HasDown  bool
+	Checksum string
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)(?:_([^.]+))?(\.up|\.down)?\.sql$`)
+
+// Load 读取目录中的迁移，按版本排序
+func Load(dir string) ([]*Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Migration{}, nil
+		}
+		return nil, err
+	}
+
+	byVersion := make(map[string]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, name, direction := match[1], match[2], match[3]
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %s has conflicting names %q and %q", version, m.Name, name)
+		}
+		switch direction {
+		case ".down":
+			if m.HasDown {
+				return nil, fmt.Errorf("duplicate down migration %s", version)
+			}
+			m.Down = string(content)
+			m.HasDown = true
+		default:
+			if m.Checksum != "" {
+				return nil, fmt.Errorf("duplicate up migration %s", version)
+			}
+			m.Up = string(content)
+			m.Checksum = checksum(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Checksum == "" {
+			return nil, fmt.Errorf("migration %s has no up file", m.Version)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return versionLess(migrations[i].Version, migrations[j].Version)
+	})
+	return migrations, nil
+}
+
+// Create 在目录中创建新的 up / down 迁移文件，版本为当前 UTC 时间，返回两个文件的路径
+func Create(dir string, name string) (string, string, error) {
+	name = normalizeName(name)
+	if name == "" {
+		return "", "", fmt.Errorf("migration name is required")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+	version := time.Now().UTC().Format("20060102150405")
+	up := filepath.Join(dir, fmt.Sprintf("%s_%s.up.sql", version, name))
+	down := filepath.Join(dir, fmt.Sprintf("%s_%s.down.sql", version, name))
+	for _, path := range []string{up, down} {
+		if _, err := os.Stat(path); err == nil {
+			return "", "", fmt.Errorf("migration file %s already exists", path)
+		}
+	}
+	if err := os.WriteFile(up, []byte("-- "+name+"\n"), 0644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(down, []byte("-- rollback "+name+"\n"), 0644); err != nil {
+		return "", "", err
+	}
+	return up, down, nil
+}
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalizeName 转为 snake_case，如 "Add users table" -> add_users_table
+func normalizeName(name string) string {
+	return strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// versionLess 按数值比较版本，版本长度不同时较短的在前
+func versionLess(a, b string) bool {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
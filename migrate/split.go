@@ -0,0 +1,116 @@
+package migrate
+
+import "strings"
+
+// split 按分号拆分 SQL 文件中的语句，忽略引号、注释和 PostgreSQL $$ 块中的分号
+// 驱动默认不支持一次执行多条语句，迁移按语句逐条执行
+func split(sql string) []string {
+	statements := make([]string, 0)
+	var current strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" && !onlyComments(stmt) {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			current.WriteString(sql[i : i+end])
+			i += end - 1
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i - 2
+			} else {
+				end += 2
+			}
+			current.WriteString(sql[i : i+2+end])
+			i += 1 + end
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(sql) {
+				if sql[end] == '\\' && c != '`' {
+					end += 2
+					continue
+				}
+				if sql[end] == c {
+					// 连续两个引号是转义
+					if end+1 < len(sql) && sql[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end >= len(sql) {
+				end = len(sql) - 1
+			}
+			current.WriteString(sql[i : end+1])
+			i = end
+		case c == '$':
+			tag := dollarTag(sql[i:])
+			if tag == "" {
+				current.WriteByte(c)
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				end = len(sql) - i - len(tag)
+			} else {
+				end += len(tag)
+			}
+			current.WriteString(sql[i : i+len(tag)+end])
+			i += len(tag) + end - 1
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+// dollarTag 返回 $$ 或 $tag$ 开头的标记，不是时返回空
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1]
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+func onlyComments(stmt string) bool {
+	for {
+		start := strings.Index(stmt, "/*")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(stmt[start:], "*/")
+		if end < 0 {
+			stmt = stmt[:start]
+			break
+		}
+		stmt = stmt[:start] + stmt[start+end+2:]
+	}
+	for _, line := range strings.Split(stmt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package outbox
+
+import "time"
+
+// Kind 消息投递目标
+type Kind string
+
+const (
+	KindMessage Kind = "message" // 投递到 messaging.Broker
+	KindTask    Kind = "task"    // 投递到 asynq 队列
+)
+
+// Status 消息状态
+type Status string
+
+const (
+	StatusPending    Status = "pending"    // 等待投递（包括等待重试）
+	StatusProcessing Status = "processing" // 已被 Relay 领取，正在投递
+	StatusPublished  Status = "published"  // 已投递
+	StatusFailed     Status = "failed"     // 超过最大重试次数，需要人工处理
+)
+
+// Message outbox 表模型，需要加入项目的 migrateModels 中迁移
+type Message struct {
+	ID           uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	DedupeID     string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"dedupeId"`
+	AggregateKey string     `gorm:"type:varchar(191);index:idx_outbox_aggregate,priority:1;not null;default:''" json:"aggregateKey"`
+	Kind         Kind       `gorm:"type:varchar(16);not null" json:"kind"`
+	Topic        string     `gorm:"type:varchar(191);not null" json:"topic"`
+	Payload      []byte     `gorm:"not null" json:"payload"`
+	Options      string     `gorm:"type:text" json:"options"`
+	Status       Status     `gorm:"type:varchar(16);index:idx_outbox_status,priority:1;index:idx_outbox_aggregate,priority:2;not null" json:"status"`
+	Attempts     int        `gorm:"not null;default:0" json:"attempts"`
+	LastError    string     `gorm:"type:text" json:"lastError"`
+	AvailableAt  time.Time  `gorm:"index:idx_outbox_status,priority:2;not null" json:"availableAt"`
+	PublishedAt  *time.Time `json:"publishedAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+func (Message) TableName() string {
+	return "outbox_messages"
+}
@@ -0,0 +1,111 @@
+package outbox
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/hibiken/asynq"
+)
+
+// taskOption asynq.Option 的可序列化形式
+type taskOption struct {
+	Type  asynq.OptionType `json:"type"`
+	Value string           `json:"value"`
+}
+
+// encodeTaskOptions 序列化任务选项
+// ProcessIn 以写入时间为基准换算成 ProcessAt，避免 Relay 延迟投递导致任务被推迟
+func encodeTaskOptions(opts []asynq.Option, now time.Time) (string, error) {
+	encoded := make([]taskOption, 0, len(opts))
+	for _, opt := range opts {
+		o := taskOption{Type: opt.Type()}
+		switch v := opt.Value().(type) {
+		case int:
+			o.Value = strconv.Itoa(v)
+		case string:
+			o.Value = v
+		case time.Duration:
+			if o.Type == asynq.ProcessInOpt {
+				o.Type = asynq.ProcessAtOpt
+				o.Value = now.Add(v).Format(time.RFC3339Nano)
+			} else {
+				o.Value = v.String()
+			}
+		case time.Time:
+			o.Value = v.Format(time.RFC3339Nano)
+		default:
+			return "", fmt.Errorf("unsupported task option %s", opt.String())
+		}
+		encoded = append(encoded, o)
+	}
+	raw, err := sonic.MarshalString(encoded)
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// decodeTaskOptions 还原任务选项
+func decodeTaskOptions(raw string) ([]asynq.Option, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var encoded []taskOption
+	if err := sonic.UnmarshalString(raw, &encoded); err != nil {
+		return nil, err
+	}
+
+	opts := make([]asynq.Option, 0, len(encoded))
+	for _, o := range encoded {
+		opt, err := decodeTaskOption(o)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	}
+	return opts, nil
+}
+
+func decodeTaskOption(o taskOption) (asynq.Option, error) {
+	switch o.Type {
+	case asynq.MaxRetryOpt:
+		n, err := strconv.Atoi(o.Value)
+		if err != nil {
+			return nil, err
+		}
+		return asynq.MaxRetry(n), nil
+	case asynq.QueueOpt:
+		return asynq.Queue(o.Value), nil
+	case asynq.TaskIDOpt:
+		return asynq.TaskID(o.Value), nil
+	case asynq.GroupOpt:
+		return asynq.Group(o.Value), nil
+	case asynq.TimeoutOpt, asynq.UniqueOpt, asynq.ProcessInOpt, asynq.RetentionOpt:
+		d, err := time.ParseDuration(o.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch o.Type {
+		case asynq.TimeoutOpt:
+			return asynq.Timeout(d), nil
+		case asynq.UniqueOpt:
+			return asynq.Unique(d), nil
+		case asynq.ProcessInOpt:
+			return asynq.ProcessIn(d), nil
+		default:
+			return asynq.Retention(d), nil
+		}
+	case asynq.DeadlineOpt, asynq.ProcessAtOpt:
+		t, err := time.Parse(time.RFC3339Nano, o.Value)
+		if err != nil {
+			return nil, err
+		}
+		if o.Type == asynq.DeadlineOpt {
+			return asynq.Deadline(t), nil
+		}
+		return asynq.ProcessAt(t), nil
+	}
+	return nil, fmt.Errorf("unknown task option type %d", o.Type)
+}
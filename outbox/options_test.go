@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+func TestTaskOptionsRoundTrip(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	deadline := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		option   asynq.Option
+		expected string
+	}{
+		{"MaxRetry", asynq.MaxRetry(3), asynq.MaxRetry(3).String()},
+		{"Queue", asynq.Queue("critical"), asynq.Queue("critical").String()},
+		{"TaskID", asynq.TaskID("order-1"), asynq.TaskID("order-1").String()},
+		{"Group", asynq.Group("report"), asynq.Group("report").String()},
+		{"Timeout", asynq.Timeout(30 * time.Second), asynq.Timeout(30 * time.Second).String()},
+		{"Unique", asynq.Unique(time.Minute), asynq.Unique(time.Minute).String()},
+		{"Retention", asynq.Retention(24 * time.Hour), asynq.Retention(24 * time.Hour).String()},
+		{"Deadline", asynq.Deadline(deadline), asynq.Deadline(deadline).String()},
+		{"ProcessAt", asynq.ProcessAt(deadline), asynq.ProcessAt(deadline).String()},
+		{"ProcessIn", asynq.ProcessIn(time.Minute), asynq.ProcessAt(now.Add(time.Minute)).String()},
+	}
+
+	for _, test := range tests {
+		t.Run("Test TaskOption "+test.name, func(t *testing.T) {
+			raw, err := encodeTaskOptions([]asynq.Option{test.option}, now)
+			if err != nil {
+				t.Fatalf("encodeTaskOptions(%s) error: %v", test.option, err)
+			}
+			opts, err := decodeTaskOptions(raw)
+			if err != nil {
+				t.Fatalf("decodeTaskOptions(%q) error: %v", raw, err)
+			}
+			if len(opts) != 1 {
+				t.Fatalf("decodeTaskOptions(%q) returned %d options; expected 1", raw, len(opts))
+			}
+			if opts[0].String() != test.expected {
+				t.Errorf("round trip of %s = %s; expected %s", test.option, opts[0], test.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeEmptyTaskOptions(t *testing.T) {
+	opts, err := decodeTaskOptions("")
+	if err != nil || opts != nil {
+		t.Errorf("decodeTaskOptions(\"\") = %v, %v; expected nil, nil", opts, err)
+	}
+}
@@ -0,0 +1,140 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/light-speak/lighthouse/databases"
+	"github.com/light-speak/lighthouse/lighterr"
+	"github.com/light-speak/lighthouse/logs"
+)
+
+// 事务性 outbox
+// 在业务事务中把待发送的消息 / 队列任务写入 outbox_messages 表，由 Relay 在事务提交后投递，
+// 保证「数据落库」与「消息发出」要么都发生，要么都不发生
+
+type entry struct {
+	msg      *Message
+	taskOpts []asynq.Option
+}
+
+// Option outbox 消息选项
+type Option func(e *entry)
+
+// WithAggregateKey 设置聚合键，同一个聚合键的消息按写入顺序依次投递，
+// 前一条投递成功之前后面的消息不会发出
+func WithAggregateKey(key string) Option {
+	return func(e *entry) {
+		e.msg.AggregateKey = key
+	}
+}
+
+// WithDedupeID 设置去重 ID，默认随机生成
+// 投递到 NATS 时作为 Nats-Msg-Id，投递到队列时作为 asynq TaskID，重复投递会被下游丢弃
+func WithDedupeID(id string) Option {
+	return func(e *entry) {
+		e.msg.DedupeID = id
+	}
+}
+
+// WithTaskOptions 设置队列任务选项，仅对 Enqueue 生效
+func WithTaskOptions(opts ...asynq.Option) Option {
+	return func(e *entry) {
+		e.taskOpts = append(e.taskOpts, opts...)
+	}
+}
+
+// Publish 把消息写入 outbox，ctx 处于 databases.WithTx 事务中时随事务一起提交或回滚
+func Publish(ctx context.Context, topic string, payload []byte, opts ...Option) error {
+	return write(ctx, KindMessage, topic, payload, opts...)
+}
+
+// PublishTyped 序列化消息后写入 outbox，对应 messaging.PublishTyped
+func PublishTyped[T any](ctx context.Context, topic string, msg T, opts ...Option) error {
+	raw, err := sonic.Marshal(msg)
+	if err != nil {
+		logs.Error().Err(err).Msg("failed to marshal outbox message")
+		return lighterr.NewInternalError("failed to marshal outbox message", err)
+	}
+	return write(ctx, KindMessage, topic, raw, opts...)
+}
+
+// Enqueue 把队列任务写入 outbox，任务选项通过 WithTaskOptions 传入
+func Enqueue(ctx context.Context, task *asynq.Task, opts ...Option) error {
+	return write(ctx, KindTask, task.Type(), task.Payload(), opts...)
+}
+
+func write(ctx context.Context, kind Kind, topic string, payload []byte, opts ...Option) error {
+	now := time.Now()
+	e := &entry{msg: &Message{
+		Kind:        kind,
+		Topic:       topic,
+		Payload:     payload,
+		Status:      StatusPending,
+		AvailableAt: now,
+	}}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.msg.DedupeID == "" {
+		e.msg.DedupeID = uuid.NewString()
+	}
+	if len(e.taskOpts) > 0 {
+		if kind != KindTask {
+			return lighterr.NewBadRequestError("task options are only supported by Enqueue")
+		}
+		options, err := encodeTaskOptions(e.taskOpts, now)
+		if err != nil {
+			return lighterr.NewBadRequestError("invalid task options", err)
+		}
+		e.msg.Options = options
+	}
+
+	db, err := databases.LightDatabaseClient.GetDB(ctx)
+	if err != nil {
+		return err
+	}
+	if err := db.WithContext(ctx).Create(e.msg).Error; err != nil {
+		logs.Error().Err(err).Str("topic", topic).Msg("failed to write outbox message")
+		return err
+	}
+
+	// 提交后唤醒同进程内的 Relay，减少投递延迟
+	databases.AfterCommit(ctx, func(ctx context.Context) error {
+		notify()
+		return nil
+	})
+	return nil
+}
+
+// Requeue 把投递失败的消息重新放回待投递状态
+func Requeue(ctx context.Context, ids ...uint64) (int64, error) {
+	db, err := databases.LightDatabaseClient.GetDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	result := db.WithContext(ctx).Model(&Message{}).
+		Where("id IN ? AND status = ?", ids, StatusFailed).
+		Updates(map[string]any{
+			"status":       StatusPending,
+			"attempts":     0,
+			"available_at": time.Now(),
+		})
+	if result.Error == nil && result.RowsAffected > 0 {
+		notify()
+	}
+	return result.RowsAffected, result.Error
+}
+
+// Cleanup 删除 before 之前已投递的消息
+func Cleanup(ctx context.Context, before time.Time) (int64, error) {
+	db, err := databases.LightDatabaseClient.GetDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	result := db.WithContext(ctx).Where("status = ? AND published_at < ?", StatusPublished, before).Delete(&Message{})
+	return result.RowsAffected, result.Error
+}
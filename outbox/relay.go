@@ -0,0 +1,242 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/light-speak/lighthouse/databases"
+	"github.com/light-speak/lighthouse/logs"
+	"github.com/light-speak/lighthouse/messaging"
+	"github.com/light-speak/lighthouse/queue"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var wakeup = make(chan struct{}, 1)
+
+// notify 唤醒同进程内等待中的 Relay
+func notify() {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Relay 把 outbox 中的消息投递到 Broker 或队列
+// 多个 Relay 实例可以同时运行，通过 FOR UPDATE SKIP LOCKED 领取消息
+type Relay struct {
+	BatchSize    int                              // 每批处理的消息数
+	PollInterval time.Duration                    // 没有消息时的轮询间隔
+	MaxAttempts  int                              // 最大投递次数，超过后标记为 failed
+	Backoff      func(attempts int) time.Duration // 第 attempts 次失败后的重试间隔
+	ClaimTimeout time.Duration                    // 领取后多久未记录结果视为 Relay 已退出，消息重新可被领取
+}
+
+// NewRelay 使用默认配置创建 Relay
+func NewRelay() *Relay {
+	return &Relay{
+		BatchSize:    100,
+		PollInterval: time.Second,
+		MaxAttempts:  10,
+		Backoff:      ExponentialBackoff,
+		ClaimTimeout: 5 * time.Minute,
+	}
+}
+
+// withDefaults 补全未设置的配置，直接使用 &Relay{} 时也能运行
+func (r *Relay) withDefaults() *Relay {
+	c := *r
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.Backoff == nil {
+		c.Backoff = ExponentialBackoff
+	}
+	if c.ClaimTimeout <= 0 {
+		c.ClaimTimeout = 5 * time.Minute
+	}
+	return &c
+}
+
+// ExponentialBackoff 指数退避：1s、2s、4s ... 最长 10 分钟
+func ExponentialBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 10 {
+		return 10 * time.Minute
+	}
+	d := time.Second << (attempts - 1)
+	return min(d, 10*time.Minute)
+}
+
+// Run 持续投递 outbox 消息，直到 ctx 结束
+func (r *Relay) Run(ctx context.Context) error {
+	r = r.withDefaults()
+	logs.Info().Int("batch", r.BatchSize).Dur("interval", r.PollInterval).Msg("outbox relay started")
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logs.Error().Err(err).Msg("outbox relay batch failed")
+		}
+		// 积压未处理完时立即处理下一批
+		if err == nil && n >= r.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			logs.Info().Msg("outbox relay stopped")
+			return nil
+		case <-ticker.C:
+		case <-wakeup:
+		}
+	}
+}
+
+// RelayOnce 投递一批到期的消息，返回处理的消息数
+// 消息先在短事务中领取，再在事务外逐条投递并各自记录结果，投递期间不持有行锁
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	r = r.withDefaults()
+	db, err := databases.LightDatabaseClient.GetDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	db = db.WithContext(ctx)
+
+	messages, err := r.claim(db)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, msg := range messages {
+		if err := r.process(ctx, db, msg); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// claim 领取一批到期的消息并标记为 processing，领取超时的消息可以被重新领取
+// 每个聚合键只取最早一条未完成的消息，前一条成功之前后面的消息不会被取出，以此保证顺序
+func (r *Relay) claim(db *gorm.DB) ([]*Message, error) {
+	var messages []*Message
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		table := Message{}.TableName()
+		unfinished := []Status{StatusPending, StatusProcessing}
+		query := tx.Where("status IN ? AND available_at <= ?", unfinished, now).
+			Where("aggregate_key = '' OR NOT EXISTS (SELECT 1 FROM "+table+" AS prev WHERE prev.aggregate_key = "+table+".aggregate_key AND prev.status IN ? AND prev.id < "+table+".id)", unfinished).
+			Order("id").
+			Limit(r.BatchSize)
+		// SQLite 没有行锁，单实例运行即可
+		if tx.Dialector.Name() != "sqlite" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := query.Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		ids := make([]uint64, len(messages))
+		for i, msg := range messages {
+			ids[i] = msg.ID
+		}
+		return tx.Model(&Message{}).Where("id IN ?", ids).Updates(map[string]any{
+			"status":       StatusProcessing,
+			"available_at": now.Add(r.ClaimTimeout),
+		}).Error
+	})
+	return messages, err
+}
+
+// process 投递单条消息并单独记录结果，只有写库失败才返回错误
+func (r *Relay) process(ctx context.Context, db *gorm.DB, msg *Message) error {
+	dispatchErr := dispatch(ctx, msg)
+	now := time.Now()
+	attempts := msg.Attempts + 1
+	// 只更新仍处于 processing 的消息，避免覆盖人工处理后的状态
+	query := db.Model(&Message{}).Where("id = ? AND status = ?", msg.ID, StatusProcessing)
+	if dispatchErr == nil {
+		return query.Updates(map[string]any{
+			"status":       StatusPublished,
+			"attempts":     attempts,
+			"last_error":   "",
+			"published_at": now,
+		}).Error
+	}
+
+	updates := map[string]any{
+		"status":       StatusPending,
+		"attempts":     attempts,
+		"last_error":   dispatchErr.Error(),
+		"available_at": now.Add(r.Backoff(attempts)),
+	}
+	event := logs.Warn()
+	if r.MaxAttempts > 0 && attempts >= r.MaxAttempts {
+		updates["status"] = StatusFailed
+		event = logs.Error()
+	}
+	event.Err(dispatchErr).
+		Uint64("id", msg.ID).
+		Str("topic", msg.Topic).
+		Str("aggregate", msg.AggregateKey).
+		Int("attempts", attempts).
+		Msg("outbox message dispatch failed")
+	return query.Updates(updates).Error
+}
+
+// dispatch 按消息类型投递，使用 DedupeID 让下游丢弃重复投递
+func dispatch(ctx context.Context, msg *Message) error {
+	switch msg.Kind {
+	case KindMessage:
+		broker := messaging.GetBroker()
+		if broker == nil {
+			return errors.New("broker not initialized")
+		}
+		if b, ok := broker.(messaging.IdempotentBroker); ok {
+			return b.PublishWithID(msg.Topic, msg.DedupeID, msg.Payload)
+		}
+		return broker.Publish(msg.Topic, msg.Payload)
+	case KindTask:
+		client, err := queue.GetClient()
+		if err != nil {
+			return err
+		}
+		opts, err := decodeTaskOptions(msg.Options)
+		if err != nil {
+			return err
+		}
+		if !hasTaskID(opts) {
+			opts = append(opts, asynq.TaskID(msg.DedupeID))
+		}
+		_, err = client.EnqueueContext(ctx, asynq.NewTask(msg.Topic, msg.Payload), opts...)
+		// 任务已经投递过
+		if errors.Is(err, asynq.ErrTaskIDConflict) || errors.Is(err, asynq.ErrDuplicateTask) {
+			return nil
+		}
+		return err
+	}
+	return errors.New("unknown outbox message kind: " + string(msg.Kind))
+}
+
+func hasTaskID(opts []asynq.Option) bool {
+	for _, opt := range opts {
+		if opt.Type() == asynq.TaskIDOpt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/light-speak/lighthouse/databases"
+	"gorm.io/gorm/logger"
+)
+
+func TestRelayOnce(t *testing.T) {
+	ctx := context.Background()
+	cfg := &databases.DatabaseConfig{Driver: databases.DriverSQLite, Name: filepath.Join(t.TempDir(), "outbox.db"), LogLevel: logger.Silent}
+	if err := databases.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer databases.LightDatabaseClient.CloseConnections()
+	db, err := databases.LightDatabaseClient.GetDB(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Message{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未启用 messaging，所有投递都会失败
+	past := time.Now().Add(-time.Second)
+	messages := []*Message{
+		{DedupeID: "a1", AggregateKey: "a", Kind: KindMessage, Topic: "t", Payload: []byte("1"), Status: StatusPending, AvailableAt: past},
+		{DedupeID: "a2", AggregateKey: "a", Kind: KindMessage, Topic: "t", Payload: []byte("2"), Status: StatusPending, AvailableAt: past},
+		{DedupeID: "b", Kind: KindMessage, Topic: "t", Payload: []byte("3"), Status: StatusPending, AvailableAt: past},
+	}
+	if err := db.Create(&messages).Error; err != nil {
+		t.Fatal(err)
+	}
+	load := func(id uint64) *Message {
+		var msg Message
+		if err := db.First(&msg, id).Error; err != nil {
+			t.Fatal(err)
+		}
+		return &msg
+	}
+
+	t.Run("Test Zero Relay Uses Defaults", func(t *testing.T) {
+		n, err := (&Relay{}).RelayOnce(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Errorf("processed = %d, expected 2", n)
+		}
+		a1 := load(messages[0].ID)
+		if a1.Status != StatusPending || a1.Attempts != 1 || a1.LastError == "" || !a1.AvailableAt.After(time.Now()) {
+			t.Errorf("a1 = %+v", a1)
+		}
+		if a2 := load(messages[1].ID); a2.Attempts != 0 {
+			t.Errorf("a2 should wait for a1, attempts = %d", a2.Attempts)
+		}
+	})
+
+	t.Run("Test Expired Claim Is Reclaimed", func(t *testing.T) {
+		if err := db.Model(&Message{}).Where("id = ?", messages[0].ID).
+			Updates(map[string]any{"status": StatusProcessing, "available_at": past}).Error; err != nil {
+			t.Fatal(err)
+		}
+		n, err := (&Relay{MaxAttempts: 2}).RelayOnce(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("processed = %d, expected 1", n)
+		}
+		if a1 := load(messages[0].ID); a1.Status != StatusFailed || a1.Attempts != 2 {
+			t.Errorf("a1 = %+v", a1)
+		}
+	})
+
+	t.Run("Test Failed Message Unblocks Aggregate", func(t *testing.T) {
+		n, err := NewRelay().RelayOnce(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("processed = %d, expected 1", n)
+		}
+		if a2 := load(messages[1].ID); a2.Attempts != 1 {
+			t.Errorf("a2 attempts = %d, expected 1", a2.Attempts)
+		}
+	})
+}
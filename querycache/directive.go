@@ -0,0 +1,14 @@
+package querycache
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// CacheDirective @cache(ttl: Int!) 指令，ttl 单位为秒
+// 字段的 resolver 中通过 ctx 执行的查询会按 ttl 缓存
+func CacheDirective(ctx context.Context, obj interface{}, next graphql.Resolver, ttl int32) (interface{}, error) {
+	return next(WithTTL(ctx, time.Duration(ttl)*time.Second))
+}
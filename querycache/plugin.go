@@ -0,0 +1,257 @@
+package querycache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/light-speak/lighthouse/databases"
+	"github.com/light-speak/lighthouse/logs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+)
+
+const keyPrefix = "lighthouse:querycache:"
+
+type plugin struct{}
+
+func (plugin) Name() string {
+	return "lighthouse:querycache"
+}
+
+// Initialize 缓存键和标签按数据库名区分，主从库共用同一份缓存，不同数据库（如每个租户独立数据库）互不影响
+func (plugin) Initialize(db *gorm.DB) error {
+	scope := db.Migrator().CurrentDatabase()
+	cb := db.Callback()
+	if err := cb.Query().Replace("gorm:query", query(scope)); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("lighthouse:querycache_create", invalidate(scope)); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("lighthouse:querycache_update", invalidate(scope)); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register("lighthouse:querycache_delete", invalidate(scope))
+}
+
+// entry 缓存的查询结果，每行按列名保存字段的 JSON
+type entry struct {
+	Rows []map[string]json.RawMessage `json:"rows"`
+}
+
+// query 替换 gorm:query，命中缓存时直接填充结果，否则查询后写入缓存
+func query(scope string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ttl := ttlOf(db)
+		if ttl <= 0 || db.Error != nil || db.DryRun || inTx(db) || !cacheable(db.Statement) {
+			callbacks.Query(db)
+			return
+		}
+		callbacks.BuildQuerySQL(db)
+		if db.Error != nil {
+			return
+		}
+
+		ctx := db.Statement.Context
+		s := currentStore()
+		versions, err := s.Versions(ctx, tagKeys(scope, tables(db.Statement)))
+		if err != nil {
+			logs.Debug().Err(err).Msg("query cache unavailable")
+			callbacks.Query(db)
+			return
+		}
+		key := cacheKey(db, scope, versions)
+		if data, err := s.Get(ctx, key); err == nil && data != nil {
+			if err := restore(db, data); err == nil {
+				return
+			}
+			logs.Warn().Err(err).Str("key", key).Msg("failed to restore cached query")
+		}
+
+		callbacks.Query(db)
+		if db.Error != nil {
+			return
+		}
+		data, err := snapshot(db)
+		if err != nil {
+			logs.Warn().Err(err).Str("table", db.Statement.Table).Msg("failed to encode query result")
+			return
+		}
+		if err := s.Set(ctx, key, data, ttl); err != nil {
+			logs.Debug().Err(err).Str("key", key).Msg("failed to cache query result")
+		}
+	}
+}
+
+// invalidate 写操作后使表的缓存失效；在事务中时提交后再失效一次，避免提交前读到旧数据的请求重新写入缓存
+func invalidate(scope string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.RowsAffected == 0 || db.Statement.Table == "" {
+			return
+		}
+		ctx := db.Statement.Context
+		tags := tagKeys(scope, []string{db.Statement.Table})
+		if err := currentStore().Invalidate(ctx, tags...); err != nil {
+			logs.Debug().Err(err).Strs("tags", tags).Msg("failed to invalidate query cache")
+		}
+		if databases.InTx(ctx) {
+			databases.AfterCommit(ctx, func(ctx context.Context) error {
+				return currentStore().Invalidate(ctx, tags...)
+			})
+		}
+	}
+}
+
+// inTx 事务中可能读到未提交的数据，回滚后缓存就是错的，因此事务中既不读也不写缓存
+func inTx(db *gorm.DB) bool {
+	if databases.InTx(db.Statement.Context) {
+		return true
+	}
+	_, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
+
+// cacheable 只缓存查询到模型结构体（或其切片）的语句，Pluck、Scan 到 map 等不缓存
+func cacheable(stmt *gorm.Statement) bool {
+	if stmt.Schema == nil || !stmt.ReflectValue.IsValid() {
+		return false
+	}
+	t := stmt.ReflectValue.Type()
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == stmt.Schema.ModelType
+}
+
+// tables 语句涉及的表：主表以及 Joins 关联的表
+func tables(stmt *gorm.Statement) []string {
+	result := []string{stmt.Table}
+	for _, join := range stmt.Joins {
+		if rel, ok := stmt.Schema.Relationships.Relations[join.Name]; ok && rel.FieldSchema != nil {
+			result = append(result, rel.FieldSchema.Table)
+		}
+	}
+	return result
+}
+
+func tagKeys(scope string, tables []string) []string {
+	keys := make([]string, len(tables))
+	for i, table := range tables {
+		keys[i] = keyPrefix + "tag:" + scope + ":" + table
+	}
+	return keys
+}
+
+// cacheKey 由完整 SQL（参数已代入）和各表标签的版本计算，标签失效后生成新的键
+func cacheKey(db *gorm.DB, scope string, versions []string) string {
+	sql := db.Dialector.Explain(db.Statement.SQL.String(), db.Statement.Vars...)
+	sum := sha1.Sum([]byte(strings.Join(strings.Fields(sql), " ") + "|" + strings.Join(versions, ",")))
+	return keyPrefix + scope + ":" + hex.EncodeToString(sum[:])
+}
+
+// snapshot 按字段编码查询结果，不经过模型的 json tag，json:"-" 的字段也能缓存
+func snapshot(db *gorm.DB) ([]byte, error) {
+	stmt := db.Statement
+	e := entry{Rows: make([]map[string]json.RawMessage, 0)}
+	var err error
+	encode := func(rv reflect.Value) {
+		row := make(map[string]json.RawMessage, len(stmt.Schema.Fields))
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName == "" || err != nil {
+				continue
+			}
+			value, _ := f.ValueOf(stmt.Context, rv)
+			var data []byte
+			if data, err = sonic.Marshal(value); err == nil {
+				row[f.DBName] = data
+			}
+		}
+		e.Rows = append(e.Rows, row)
+	}
+
+	rv := stmt.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			encode(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		if db.RowsAffected > 0 {
+			encode(rv)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sonic.Marshal(e)
+}
+
+// restore 把缓存的结果填充到语句的 Dest，与 gorm:query 一样设置 RowsAffected 和 ErrRecordNotFound
+func restore(db *gorm.DB, data []byte) error {
+	stmt := db.Statement
+	var e entry
+	if err := sonic.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	decode := func(rv reflect.Value, row map[string]json.RawMessage) error {
+		for _, f := range stmt.Schema.Fields {
+			raw, ok := row[f.DBName]
+			if f.DBName == "" || !ok {
+				continue
+			}
+			value := reflect.New(f.FieldType)
+			if err := sonic.Unmarshal(raw, value.Interface()); err != nil {
+				return err
+			}
+			if err := f.Set(stmt.Context, rv, value.Elem().Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rv := stmt.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice:
+		elemType := rv.Type().Elem()
+		isPtr := elemType.Kind() == reflect.Pointer
+		rows := reflect.MakeSlice(rv.Type(), 0, len(e.Rows))
+		for _, row := range e.Rows {
+			elem := reflect.New(stmt.Schema.ModelType)
+			if err := decode(elem.Elem(), row); err != nil {
+				return err
+			}
+			if isPtr {
+				rows = reflect.Append(rows, elem)
+			} else {
+				rows = reflect.Append(rows, elem.Elem())
+			}
+		}
+		rv.Set(rows)
+	case reflect.Struct:
+		if len(e.Rows) > 0 {
+			if err := decode(rv, e.Rows[0]); err != nil {
+				return err
+			}
+		}
+	default:
+		return gorm.ErrInvalidData
+	}
+
+	db.RowsAffected = int64(len(e.Rows))
+	if stmt.Result != nil {
+		stmt.Result.RowsAffected = db.RowsAffected
+	}
+	if db.RowsAffected == 0 && stmt.RaiseErrorOnNotFound {
+		_ = db.AddError(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
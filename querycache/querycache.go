@@ -0,0 +1,84 @@
+package querycache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/light-speak/lighthouse/databases"
+	"gorm.io/gorm"
+)
+
+// 查询结果缓存
+// 开启缓存的查询（ctx 中带有 WithTTL，或使用 TTL scope，GraphQL 中使用 @cache）按 SQL 和参数缓存结果，
+// 缓存按表打标签，对这些表的创建、更新、删除会使标签失效，之前缓存的结果不再命中；
+// 没有开启缓存的查询不受影响，Redis 不可用时直接查询数据库
+
+// ttlSetting 语句级缓存时间保存在 Statement.Settings 中
+const ttlSetting = "lighthouse:querycache_ttl"
+
+type ttlContextKey struct{}
+
+// WithTTL 开启 ctx 下查询的缓存，ttl <= 0 时关闭
+func WithTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, ttlContextKey{}, ttl)
+}
+
+// TTL 开启单条查询的缓存，如 db.Scopes(querycache.TTL(time.Minute)).Find(&users)
+func TTL(ttl time.Duration) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(ttlSetting, ttl)
+	}
+}
+
+// ttlOf 返回语句的缓存时间，scope 优先于 ctx
+func ttlOf(db *gorm.DB) time.Duration {
+	if v, ok := db.Get(ttlSetting); ok {
+		ttl, _ := v.(time.Duration)
+		return ttl
+	}
+	if db.Statement.Context == nil {
+		return 0
+	}
+	ttl, _ := db.Statement.Context.Value(ttlContextKey{}).(time.Duration)
+	return ttl
+}
+
+// Store 缓存存储，默认使用 Redis
+type Store interface {
+	// Get 读取缓存，不存在时返回 nil
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set 写入缓存
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Versions 返回标签的当前版本，标签失效后版本变化
+	Versions(ctx context.Context, tags []string) ([]string, error)
+	// Invalidate 使标签失效
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+var (
+	storeMu sync.RWMutex
+	store   Store = redisStore{}
+)
+
+// SetStore 替换缓存存储
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+func currentStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+func init() {
+	databases.RegisterPlugin(plugin{})
+}
+
+// Invalidate 手动使 db 所在数据库中表的缓存失效，用于 Raw / Exec 等无法识别表的写操作
+func Invalidate(ctx context.Context, db *gorm.DB, tables ...string) error {
+	return currentStore().Invalidate(ctx, tagKeys(db.Migrator().CurrentDatabase(), tables)...)
+}
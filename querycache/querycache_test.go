@@ -0,0 +1,152 @@
+package querycache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/light-speak/lighthouse/databases"
+	"gorm.io/gorm"
+)
+
+// memoryStore 测试用的内存存储
+type memoryStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	tags map[string]int
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key], nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+func (s *memoryStore) Versions(ctx context.Context, tags []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions := make([]string, len(tags))
+	for i, tag := range tags {
+		versions[i] = strconv.Itoa(s.tags[tag])
+	}
+	return versions, nil
+}
+
+func (s *memoryStore) Invalidate(ctx context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range tags {
+		s.tags[tag]++
+	}
+	return nil
+}
+
+type cacheUser struct {
+	ID       uint `gorm:"primaryKey"`
+	Name     string
+	Password string `json:"-"`
+}
+
+func TestQueryCache(t *testing.T) {
+	SetStore(&memoryStore{data: map[string][]byte{}, tags: map[string]int{}})
+	t.Cleanup(func() { SetStore(redisStore{}) })
+
+	cfg := databases.DefaultConfig()
+	cfg.Driver = databases.DriverSQLite
+	cfg.Name = ":memory:"
+	if err := databases.Init(cfg); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	t.Cleanup(databases.LightDatabaseClient.CloseConnections)
+	db, _ := databases.LightDatabaseClient.GetDB(context.Background())
+	if err := db.AutoMigrate(&cacheUser{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	db.Create(&cacheUser{Name: "alice", Password: "secret"})
+
+	cached := WithTTL(context.Background(), time.Minute)
+	names := func(ctx context.Context) []string {
+		users := make([]*cacheUser, 0)
+		if err := db.WithContext(ctx).Order("id").Find(&users).Error; err != nil {
+			t.Fatalf("Find() error = %v", err)
+		}
+		result := make([]string, len(users))
+		for i, u := range users {
+			result[i] = u.Name + ":" + u.Password
+		}
+		return result
+	}
+	names(cached)
+	// 绕过 gorm 回调修改数据，缓存不会失效
+	db.Exec("UPDATE cache_users SET name = ?", "bob")
+
+	tests := []struct {
+		name     string
+		run      func() []string
+		expected string
+	}{
+		{"Test Cache Hit", func() []string { return names(cached) }, "[alice:secret]"},
+		{"Test Cache Disabled", func() []string { return names(context.Background()) }, "[bob:secret]"},
+		{"Test Cache Scope", func() []string {
+			var users []cacheUser
+			db.Scopes(TTL(time.Minute)).Order("id").Find(&users)
+			return []string{users[0].Name + ":" + users[0].Password}
+		}, "[alice:secret]"},
+		{"Test Cache Invalidated By Write", func() []string {
+			db.Create(&cacheUser{Name: "carol"})
+			return names(cached)
+		}, "[bob:secret carol:]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.run(); fmt.Sprint(actual) != test.expected {
+				t.Errorf("names = %s; expected %s", fmt.Sprint(actual), test.expected)
+			}
+		})
+	}
+
+	t.Run("Test Cache Record Not Found", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			var user cacheUser
+			err := db.WithContext(cached).First(&user, 100).Error
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("First() error = %v; expected ErrRecordNotFound", err)
+			}
+		}
+	})
+
+	t.Run("Test Cache Skipped In Transaction", func(t *testing.T) {
+		errRollback := errors.New("rollback")
+		read := func(tx *gorm.DB) {
+			users := make([]*cacheUser, 0)
+			if err := tx.Order("id").Find(&users).Error; err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+		}
+		_ = databases.WithTx(cached, func(ctx context.Context) error {
+			tx, _ := databases.LightDatabaseClient.GetDB(ctx)
+			tx.Create(&cacheUser{Name: "dave"})
+			read(tx)
+			return errRollback
+		})
+		_ = db.WithContext(cached).Transaction(func(tx *gorm.DB) error {
+			tx.Create(&cacheUser{Name: "erin"})
+			read(tx)
+			return errRollback
+		})
+		if actual := fmt.Sprint(names(cached)); actual != "[bob:secret carol:]" {
+			t.Errorf("names = %s; expected rolled back rows not to be cached", actual)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package querycache
+
+import (
+	"context"
+	"time"
+
+	"github.com/light-speak/lighthouse/redis"
+	goRedis "github.com/redis/go-redis/v9"
+)
+
+// redisStore 使用 redis.LightRedisClient 存储缓存，标签版本为计数器，失效时加 1
+type redisStore struct{}
+
+func (redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	client, err := redis.GetClient()
+	if err != nil {
+		return nil, err
+	}
+	data, err := client.Get(ctx, key).Bytes()
+	if err == goRedis.Nil {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	client, err := redis.GetClient()
+	if err != nil {
+		return err
+	}
+	return client.Set(ctx, key, value, ttl).Err()
+}
+
+func (redisStore) Versions(ctx context.Context, tags []string) ([]string, error) {
+	client, err := redis.GetClient()
+	if err != nil {
+		return nil, err
+	}
+	values, err := client.MGet(ctx, tags...).Result()
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			versions[i] = s
+		} else {
+			versions[i] = "0"
+		}
+	}
+	return versions, nil
+}
+
+func (redisStore) Invalidate(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	client, err := redis.GetClient()
+	if err != nil {
+		return err
+	}
+	pipe := client.Pipeline()
+	for _, tag := range tags {
+		pipe.Incr(ctx, tag)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
@@ -1,50 +1,90 @@
 package queue
 
 import (
-	"log"
-	"os"
-	"path/filepath"
+	"errors"
+	"sync"
 
-	"github.com/joho/godotenv"
-	"github.com/light-speak/lighthouse/utils"
+	"github.com/light-speak/lighthouse/config"
 )
 
 type QueueConfig struct {
-	Enable   bool
-	Host     string
-	Port     string
-	Password string
-	DB       int
+	Enable   bool   `env:"QUEUE_ENABLE"`
+	Host     string `env:"QUEUE_REDIS_HOST"`
+	Port     string `env:"QUEUE_REDIS_PORT"`
+	Password string `env:"QUEUE_REDIS_PASSWORD" secret:"true"`
+	DB       int    `env:"QUEUE_REDIS_DB"`
 }
 
+// LightQueueConfig 当前生效的配置，由 Init 设置或首次使用时通过 LoadConfig 加载
 var LightQueueConfig *QueueConfig
 
-func init() {
-	LightQueueConfig = &QueueConfig{
+var configMu sync.Mutex
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *QueueConfig {
+	return &QueueConfig{
 		Enable:   false,
 		Host:     "localhost",
 		Port:     "6379",
 		Password: "",
 		DB:       0,
 	}
+}
 
-	if curPath, err := os.Getwd(); err == nil {
-		err = godotenv.Load(filepath.Join(curPath, ".env"))
+// LoadConfig 从配置文件和环境变量读取配置
+func LoadConfig() (*QueueConfig, error) {
+	cfg := DefaultConfig()
+	if err := config.Bind(cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func init() {
+	config.Register("queue", func() (any, error) { return LoadConfig() })
+}
+
+func (c *QueueConfig) validate() error {
+	if c.Enable && (c.Host == "" || c.Port == "") {
+		return errors.New("queue config is invalid, please check QUEUE_REDIS_HOST and QUEUE_REDIS_PORT")
+	}
+	return nil
+}
+
+// Init 使用给定配置初始化队列，cfg 为 nil 时通过 LoadConfig 加载
+// 已创建的客户端会被关闭，下次 GetClient 时按新配置重建
+func Init(cfg *QueueConfig) error {
+	if cfg == nil {
+		loaded, err := LoadConfig()
 		if err != nil {
-			log.Println("Error loading .env file:", err)
+			return err
 		}
+		cfg = loaded
 	}
-
-	LightQueueConfig.Enable = utils.GetEnvBool("QUEUE_ENABLE", LightQueueConfig.Enable)
-	if LightQueueConfig.Enable {
-		LightQueueConfig.Host = utils.GetEnv("QUEUE_REDIS_HOST", LightQueueConfig.Host)
-		LightQueueConfig.Port = utils.GetEnv("QUEUE_REDIS_PORT", LightQueueConfig.Port)
-		LightQueueConfig.Password = utils.GetEnv("QUEUE_REDIS_PASSWORD", LightQueueConfig.Password)
-		LightQueueConfig.DB = utils.GetEnvInt("QUEUE_REDIS_DB", LightQueueConfig.DB)
+	if err := cfg.validate(); err != nil {
+		return err
 	}
 
-	if LightQueueConfig.Enable && (LightQueueConfig.Host == "" || LightQueueConfig.Port == "") {
-		log.Println("Queue config is invalid, please check the .env file")
-		panic("Queue config is invalid, please check the .env file")
+	configMu.Lock()
+	LightQueueConfig = cfg
+	configMu.Unlock()
+	return CloseClient()
+}
+
+// getConfig 返回当前配置，首次使用时通过 LoadConfig 加载
+func getConfig() (*QueueConfig, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	if LightQueueConfig != nil {
+		return LightQueueConfig, nil
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
 	}
+	LightQueueConfig = cfg
+	return cfg, nil
 }
@@ -19,7 +19,7 @@ var (
 	JobMutex     sync.RWMutex
 	JobConfigMap = map[string]JobConfig{}
 	client       *asynq.Client
-	clientOnce   sync.Once
+	clientMu     sync.Mutex
 )
 
 type Executor interface {
@@ -27,7 +27,11 @@ type Executor interface {
 }
 
 func StartQueue() error {
-	if !LightQueueConfig.Enable {
+	cfg, err := getConfig()
+	if err != nil {
+		return err
+	}
+	if !cfg.Enable {
 		return errors.New("queue is not enabled")
 	}
 
@@ -52,7 +56,7 @@ func StartQueue() error {
 	concurrency := 8
 
 	srv := asynq.NewServer(
-		getRedisConfig(),
+		getRedisConfig(cfg),
 		asynq.Config{Concurrency: concurrency, Queues: queuePriority},
 	)
 
@@ -71,24 +75,27 @@ func StartQueue() error {
 	return nil
 }
 
-func getRedisConfig() *asynq.RedisClientOpt {
+func getRedisConfig(cfg *QueueConfig) *asynq.RedisClientOpt {
 	return &asynq.RedisClientOpt{
-		Addr:     LightQueueConfig.Host + ":" + LightQueueConfig.Port,
-		Password: LightQueueConfig.Password,
-		DB:       LightQueueConfig.DB,
+		Addr:     cfg.Host + ":" + cfg.Port,
+		Password: cfg.Password,
+		DB:       cfg.DB,
 	}
 }
 
 func GetClient() (*asynq.Client, error) {
-	if !LightQueueConfig.Enable {
+	cfg, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Enable {
 		return nil, errors.New("queue is not enabled")
 	}
-	clientOnce.Do(func() {
-		client = asynq.NewClient(getRedisConfig())
-		logs.Info().Msg("queue client initialized")
-	})
+	clientMu.Lock()
+	defer clientMu.Unlock()
 	if client == nil {
-		return nil, errors.New("queue client not initialized")
+		client = asynq.NewClient(getRedisConfig(cfg))
+		logs.Info().Msg("queue client initialized")
 	}
 	return client, nil
 }
@@ -100,8 +107,12 @@ func RegisterJob(name string, config JobConfig) {
 }
 
 func CloseClient() error {
-	if client != nil {
-		return client.Close()
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if client == nil {
+		return nil
 	}
-	return nil
+	err := client.Close()
+	client = nil
+	return err
 }
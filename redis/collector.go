@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/light-speak/lighthouse/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	goRedis "github.com/redis/go-redis/v9"
+)
+
+var poolLabels = []string{"addr"}
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName("lighthouse", "redis_pool", name), help, poolLabels, nil)
+}
+
+// poolCollector 采集 LightRedisClient 的连接池状态，只读取已建立的连接，不会触发连接
+type poolCollector struct {
+	total        *prometheus.Desc
+	idle         *prometheus.Desc
+	stale        *prometheus.Desc
+	hits         *prometheus.Desc
+	misses       *prometheus.Desc
+	timeouts     *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+}
+
+func newPoolCollector() *poolCollector {
+	return &poolCollector{
+		total:        poolDesc("total", "Number of total connections in the pool"),
+		idle:         poolDesc("idle", "Number of idle connections in the pool"),
+		stale:        poolDesc("stale_total", "Total number of stale connections removed from the pool"),
+		hits:         poolDesc("hits_total", "Total number of times a free connection was found in the pool"),
+		misses:       poolDesc("misses_total", "Total number of times a free connection was not found in the pool"),
+		timeouts:     poolDesc("timeouts_total", "Total number of wait timeouts"),
+		waitCount:    poolDesc("wait_count_total", "Total number of connections waited for"),
+		waitDuration: poolDesc("wait_duration_seconds_total", "Total time spent waiting for a connection"),
+	}
+}
+
+func init() {
+	metrics.RegisterCollector(newPoolCollector())
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.total
+	ch <- c.idle
+	ch <- c.stale
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.waitCount
+	ch <- c.waitDuration
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	initMu.Lock()
+	var client *goRedis.Client
+	if LightRedisClient != nil && LightRedisClient.IsEnable {
+		client = LightRedisClient.Client
+	}
+	initMu.Unlock()
+	if client == nil {
+		return
+	}
+
+	addr := client.Options().Addr
+	stats := client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stats.TotalConns), addr)
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.IdleConns), addr)
+	ch <- prometheus.MustNewConstMetric(c.stale, prometheus.CounterValue, float64(stats.StaleConns), addr)
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits), addr)
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses), addr)
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts), addr)
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount), addr)
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, time.Duration(stats.WaitDurationNs).Seconds(), addr)
+}
@@ -1,13 +1,6 @@
 package redis
 
-import (
-	"log"
-	"os"
-	"path/filepath"
-
-	"github.com/joho/godotenv"
-	"github.com/light-speak/lighthouse/utils"
-)
+import "github.com/light-speak/lighthouse/config"
 
 // # Redis settings
 // REDIS_HOST=localhost
@@ -15,44 +8,42 @@ import (
 // REDIS_PASSWORD=
 // REDIS_DB=0
 type Config struct {
-	Enable   bool
-	Host     string
-	Port     string
-	Password string
-	DB       int
+	Enable   bool   `env:"REDIS_ENABLE"`
+	Host     string `env:"REDIS_HOST"`
+	Port     string `env:"REDIS_PORT"`
+	Password string `env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `env:"REDIS_DB"`
 
 	// 连接池配置
-	PoolSize     int // 连接池大小
-	MinIdleConns int // 最小空闲连接数
+	PoolSize     int `env:"REDIS_POOL_SIZE"`      // 连接池大小
+	MinIdleConns int `env:"REDIS_MIN_IDLE_CONNS"` // 最小空闲连接数
 }
 
+// LightRedisConfig 当前生效的配置，由 Init 设置或首次使用时通过 LoadConfig 加载
 var LightRedisConfig *Config
 
-func init() {
-	LightRedisConfig = &Config{
-		Enable:   false,
-		Host:     "localhost",
-		Port:     "6379",
-		Password: "",
-		DB:       0,
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Enable:       false,
+		Host:         "localhost",
+		Port:         "6379",
+		Password:     "",
+		DB:           0,
+		PoolSize:     10,
+		MinIdleConns: 5,
 	}
+}
 
-	if curPath, err := os.Getwd(); err == nil {
-		err = godotenv.Load(filepath.Join(curPath, ".env"))
-		if err != nil {
-			log.Println("Error loading .env file:", err)
-		}
+// LoadConfig 从配置文件和环境变量读取配置
+func LoadConfig() (*Config, error) {
+	cfg := DefaultConfig()
+	if err := config.Bind(cfg); err != nil {
+		return nil, err
 	}
+	return cfg, nil
+}
 
-	LightRedisConfig.Enable = utils.GetEnvBool("REDIS_ENABLE", false)
-	LightRedisConfig.Host = utils.GetEnv("REDIS_HOST", "localhost")
-	LightRedisConfig.Port = utils.GetEnv("REDIS_PORT", "6379")
-	LightRedisConfig.Password = utils.GetEnv("REDIS_PASSWORD", "")
-	LightRedisConfig.DB = utils.GetEnvInt("REDIS_DB", 0)
-	LightRedisConfig.PoolSize = utils.GetEnvInt("REDIS_POOL_SIZE", 10)
-	LightRedisConfig.MinIdleConns = utils.GetEnvInt("REDIS_MIN_IDLE_CONNS", 5)
-
-	if LightRedisConfig.Enable {
-		initRedis()
-	}
+func init() {
+	config.Register("redis", func() (any, error) { return LoadConfig() })
 }
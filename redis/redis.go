@@ -3,6 +3,8 @@ package redis
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/bytedance/sonic"
@@ -15,16 +17,63 @@ type LightRedis struct {
 	IsEnable bool
 }
 
-var LightRedisClient *LightRedis
+var (
+	LightRedisClient *LightRedis
+
+	initMu      sync.Mutex
+	lastAttempt time.Time
+)
+
+// lazyRetryInterval 首次使用时连接失败后，再次尝试连接的最小间隔
+const lazyRetryInterval = 5 * time.Second
+
+// Init 使用给定配置连接 Redis，cfg 为 nil 时通过 LoadConfig 加载
+// 未启用 Redis 时只记录配置，连接失败时返回错误
+func Init(cfg *Config) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	return initRedis(cfg)
+}
+
+// ensure 首次使用时按 .env 连接，失败后按 lazyRetryInterval 限制重试频率
+func ensure() {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if LightRedisConfig != nil {
+		if !LightRedisConfig.Enable || (LightRedisClient != nil && LightRedisClient.IsEnable) {
+			return
+		}
+		if time.Since(lastAttempt) < lazyRetryInterval {
+			return
+		}
+	}
+	if err := initRedis(LightRedisConfig); err != nil {
+		logs.Error().Err(err).Msg("failed to connect redis")
+	}
+}
+
+// initRedis 调用方需持有 initMu
+func initRedis(cfg *Config) error {
+	if cfg == nil {
+		loaded, err := LoadConfig()
+		if err != nil {
+			return err
+		}
+		cfg = loaded
+	}
+	LightRedisConfig = cfg
+	if !cfg.Enable {
+		return nil
+	}
+	lastAttempt = time.Now()
 
-func initRedis() {
 	client := goRedis.NewClient(&goRedis.Options{
-		Addr:     LightRedisConfig.Host + ":" + LightRedisConfig.Port,
-		Password: LightRedisConfig.Password,
-		DB:       LightRedisConfig.DB,
+		Addr:     cfg.Host + ":" + cfg.Port,
+		Password: cfg.Password,
+		DB:       cfg.DB,
 
-		PoolSize:     LightRedisConfig.PoolSize,
-		MinIdleConns: LightRedisConfig.MinIdleConns,
+		PoolSize:     cfg.PoolSize,
+		MinIdleConns: cfg.MinIdleConns,
 		MaxRetries:   3,
 		DialTimeout:  5 * time.Second,
 		ReadTimeout:  3 * time.Second,
@@ -33,23 +82,27 @@ func initRedis() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	_, err := client.Ping(ctx).Result()
-	if err != nil {
-		logs.Error().Err(err).Msg("failed to connect redis")
+	if _, err := client.Ping(ctx).Result(); err != nil {
+		_ = client.Close()
 		LightRedisClient = &LightRedis{
 			Client:   nil,
 			IsEnable: false,
 		}
-		return
+		return fmt.Errorf("failed to connect redis: %w", err)
 	}
 	logs.Info().Msg("redis connected")
+	if LightRedisClient != nil {
+		_ = LightRedisClient.Close()
+	}
 	LightRedisClient = &LightRedis{
 		Client:   client,
 		IsEnable: true,
 	}
+	return nil
 }
 
 func GetLightRedis() (*LightRedis, error) {
+	ensure()
 	if !LightRedisConfig.Enable {
 		return nil, errors.New("redis is not enabled")
 	}
@@ -63,7 +116,7 @@ func GetLightRedis() (*LightRedis, error) {
 }
 
 func GetClient() (*goRedis.Client, error) {
-
+	ensure()
 	if LightRedisClient == nil {
 		return nil, errors.New("redis is not initialized")
 	}
@@ -74,10 +127,10 @@ func GetClient() (*goRedis.Client, error) {
 }
 
 func (lr *LightRedis) GetClient() (*goRedis.Client, error) {
-	if !LightRedisConfig.Enable {
+	if LightRedisConfig == nil || !LightRedisConfig.Enable {
 		return nil, errors.New("redis is not enabled")
 	}
-	if LightRedisClient == nil {
+	if lr == nil {
 		return nil, errors.New("redis is not initialized")
 	}
 	if !lr.IsEnable {
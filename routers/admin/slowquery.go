@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/light-speak/lighthouse/databases"
+)
+
+// RequireToken 校验 Authorization: Bearer <token>，token 为空时拒绝所有请求
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type slowQueryResponse struct {
+	Timestamp time.Time             `json:"timestamp"`
+	Queries   []databases.SlowQuery `json:"queries"`
+}
+
+// SlowQueriesHandler 返回最近的慢查询，按耗时从高到低排序
+// GET ?limit=20 限制条数；DELETE 清空记录
+func SlowQueriesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		databases.ResetSlowQueries()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&slowQueryResponse{
+		Timestamp: time.Now(),
+		Queries:   databases.SlowQueries(limit),
+	})
+}
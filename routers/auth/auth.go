@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/light-speak/lighthouse/logs"
@@ -21,26 +22,32 @@ type contextKey struct {
 func Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			token := r.Header.Get("Authorization")
-			if token != "" {
-				// Remove "Bearer " prefix if present
-				if len(token) > 7 && token[:7] == "Bearer " {
-					token = token[7:]
-				}
-				userId, err := GetUserId(token)
-				logs.Debug().Msgf("request token: %s, user id: %d", token, userId)
+			if token := r.Header.Get("Authorization"); token != "" {
+				principal, err := authenticate(r.Context(), token)
 				if err != nil {
 					http.Error(w, err.Error(), http.StatusUnauthorized)
 					return
 				}
-				ctx := context.WithValue(r.Context(), userContextKey, uint(userId))
-				r = r.WithContext(ctx)
+				logs.Debug().Msgf("request user id: %d", principal.UserId)
+				r = r.WithContext(WithPrincipal(r.Context(), principal))
 			}
-			next.ServeHTTP(w, r)
+			// ClientIP / UserAgent 供审计日志等使用
+			ctx := context.WithValue(r.Context(), clientIPKey, r.RemoteAddr)
+			ctx = context.WithValue(ctx, userAgentKey, r.Header.Get("User-Agent"))
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// authenticate 去掉 Bearer 前缀后校验 token，已吊销的 token 和 refresh token 不能通过
+func authenticate(ctx context.Context, token string) (*Principal, error) {
+	claims, err := Authenticate(ctx, strings.TrimPrefix(token, "Bearer "))
+	if err != nil {
+		return nil, err
+	}
+	return NewPrincipal(claims)
+}
+
 func AdminAuthMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -75,8 +82,7 @@ func XUserMiddleware() func(http.Handler) http.Handler {
 					http.Error(w, err.Error(), http.StatusUnauthorized)
 					return
 				}
-				ctx := context.WithValue(r.Context(), userContextKey, uint(userId))
-				r = r.WithContext(ctx)
+				r = r.WithContext(WithPrincipal(r.Context(), &Principal{UserId: uint(userId)}))
 			}
 			next.ServeHTTP(w, r)
 		})
@@ -85,16 +91,12 @@ func XUserMiddleware() func(http.Handler) http.Handler {
 
 func WebSocketInitFunc(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
 	if authHeader, ok := initPayload["Authorization"].(string); ok {
-		token := authHeader
-		if len(token) > 7 && token[:7] == "Bearer " {
-			token = token[7:]
-		}
-		userId, err := GetUserId(token)
+		principal, err := authenticate(ctx, authHeader)
 		if err != nil {
 			return ctx, nil, err
 		}
-		logs.Debug().Msgf("init payload: %v, user id: %d", initPayload, userId)
-		ctx = context.WithValue(ctx, userContextKey, uint(userId))
+		logs.Debug().Msgf("init payload: %v, user id: %d", initPayload, principal.UserId)
+		ctx = WithPrincipal(ctx, principal)
 	}
 	if userIdStr, ok := initPayload["X-User-Id"].(string); ok {
 		userId, err := strconv.ParseUint(userIdStr, 10, 64)
@@ -102,14 +104,14 @@ func WebSocketInitFunc(ctx context.Context, initPayload transport.InitPayload) (
 			return ctx, nil, err
 		}
 		logs.Debug().Msgf("init payload: %v, user id: %d", initPayload, userId)
-		ctx = context.WithValue(ctx, userContextKey, uint(userId))
+		ctx = WithPrincipal(ctx, &Principal{UserId: uint(userId)})
 	}
 	return ctx, &initPayload, nil
 }
 
 func GetCtxUserId(ctx context.Context) uint {
-	if userId, ok := ctx.Value(userContextKey).(uint); ok {
-		return userId
+	if p := GetPrincipal(ctx); p != nil {
+		return p.UserId
 	}
 	return 0
 }
@@ -0,0 +1,356 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/light-speak/lighthouse/logs"
+	"github.com/light-speak/lighthouse/routers"
+)
+
+// Authenticator 签发和校验用户 token，Middleware、WebSocketInitFunc、GetToken 都通过它完成
+// 默认按 JWT_* 配置创建，使用 SetAuthenticator 替换
+type Authenticator interface {
+	// Issue 为用户签发 token，extra 为额外的 claim（如 tenant_id），extra 中的 exp、jti 优先于默认值
+	Issue(userId uint, extra map[string]any) (string, error)
+	// Verify 校验 token 的签名、过期时间、issuer、audience，返回全部 claim
+	Verify(token string) (jwt.MapClaims, error)
+}
+
+// DefaultTTL 默认的 token 有效期
+const DefaultTTL = 30 * 24 * time.Hour
+
+type options struct {
+	kid      string
+	keys     KeySet
+	issuer   string
+	audience []string
+	ttl      time.Duration
+	leeway   time.Duration
+}
+
+// Option Authenticator 选项
+type Option func(o *options)
+
+// WithKeyID 签发 token 时写入 header 的 kid，轮换密钥时用于区分新旧密钥
+func WithKeyID(kid string) Option {
+	return func(o *options) {
+		o.kid = kid
+	}
+}
+
+// WithKeySet 校验 token 时按 kid 查找密钥，用于密钥轮换或只校验不签发的服务
+func WithKeySet(keys KeySet) Option {
+	return func(o *options) {
+		o.keys = keys
+	}
+}
+
+// WithIssuer 签发时写入 iss，校验时要求 iss 一致
+func WithIssuer(issuer string) Option {
+	return func(o *options) {
+		o.issuer = issuer
+	}
+}
+
+// WithAudience 签发时写入 aud，校验时要求 aud 至少包含其中一个
+func WithAudience(audience ...string) Option {
+	return func(o *options) {
+		o.audience = audience
+	}
+}
+
+// WithTTL token 有效期，默认 30 天，<= 0 时使用默认值
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithLeeway 校验过期时间时允许的时钟误差
+func WithLeeway(leeway time.Duration) Option {
+	return func(o *options) {
+		o.leeway = leeway
+	}
+}
+
+// jwtAuthenticator 基于 JWT 的 Authenticator
+type jwtAuthenticator struct {
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+	opts      *options
+}
+
+func newJWT(method jwt.SigningMethod, signKey any, verifyKey any, opts []Option) *jwtAuthenticator {
+	o := &options{ttl: DefaultTTL}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.ttl <= 0 {
+		o.ttl = DefaultTTL
+	}
+	return &jwtAuthenticator{method: method, signKey: signKey, verifyKey: verifyKey, opts: o}
+}
+
+// NewHS256 使用共享密钥的 HS256 Authenticator
+func NewHS256(secret []byte, opts ...Option) Authenticator {
+	if len(secret) == 0 {
+		return newJWT(jwt.SigningMethodHS256, nil, nil, opts)
+	}
+	return newJWT(jwt.SigningMethodHS256, secret, secret, opts)
+}
+
+// NewRS256 使用 RSA 私钥签发的 RS256 Authenticator，key 为 nil 时只校验（需要 WithKeySet）
+func NewRS256(key *rsa.PrivateKey, opts ...Option) Authenticator {
+	if key == nil {
+		return newJWT(jwt.SigningMethodRS256, nil, nil, opts)
+	}
+	return newJWT(jwt.SigningMethodRS256, key, &key.PublicKey, opts)
+}
+
+// NewES256 使用 P-256 私钥签发的 ES256 Authenticator，key 为 nil 时只校验（需要 WithKeySet）
+func NewES256(key *ecdsa.PrivateKey, opts ...Option) Authenticator {
+	if key == nil {
+		return newJWT(jwt.SigningMethodES256, nil, nil, opts)
+	}
+	return newJWT(jwt.SigningMethodES256, key, &key.PublicKey, opts)
+}
+
+func (a *jwtAuthenticator) Issue(userId uint, extra map[string]any) (string, error) {
+	if a.signKey == nil {
+		return "", fmt.Errorf("%s authenticator has no signing key", a.method.Alg())
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	claims["user_id"] = userId
+	// iat 精确到毫秒，RevokeUser 之后立即签发的 token 不会被误判为已吊销
+	claims["iat"] = float64(now.UnixMilli()) / 1000
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = now.Add(a.opts.ttl).Unix()
+	}
+	if _, ok := claims["jti"]; !ok {
+		jti, err := newID()
+		if err != nil {
+			return "", err
+		}
+		claims["jti"] = jti
+	}
+	if a.opts.issuer != "" {
+		claims["iss"] = a.opts.issuer
+	}
+	switch len(a.opts.audience) {
+	case 0:
+	case 1:
+		claims["aud"] = a.opts.audience[0]
+	default:
+		claims["aud"] = a.opts.audience
+	}
+
+	token := jwt.NewWithClaims(a.method, claims)
+	if a.opts.kid != "" {
+		token.Header["kid"] = a.opts.kid
+	}
+	return token.SignedString(a.signKey)
+}
+
+func (a *jwtAuthenticator) Verify(token string) (jwt.MapClaims, error) {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{a.method.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(a.opts.leeway),
+	}
+	if a.opts.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(a.opts.issuer))
+	}
+	if len(a.opts.audience) > 0 {
+		parserOpts = append(parserOpts, jwt.WithAudience(a.opts.audience...))
+	}
+
+	claims := jwt.MapClaims{}
+	t, err := jwt.ParseWithClaims(token, claims, a.key, parserOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// key 按 header 中的 kid 选择校验密钥：与自己的 kid 一致时使用自己的公钥，否则从 KeySet 查找
+func (a *jwtAuthenticator) key(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	if a.verifyKey != nil && kid == a.opts.kid {
+		return a.verifyKey, nil
+	}
+	if a.opts.keys != nil {
+		return a.opts.keys.Key(kid)
+	}
+	if a.verifyKey == nil {
+		return nil, fmt.Errorf("%s authenticator has no verification key", a.method.Alg())
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// newID 随机生成 jti、token 家族 ID
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// userIdFromClaims 读取 user_id claim，没有时读取数字形式的 sub
+func userIdFromClaims(claims jwt.MapClaims) (uint, error) {
+	value, ok := claims["user_id"]
+	if !ok {
+		value = claims["sub"]
+	}
+	switch v := value.(type) {
+	case float64:
+		if v > 0 {
+			return uint(v), nil
+		}
+	case string:
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil && id > 0 {
+			return uint(id), nil
+		}
+	}
+	return 0, errors.New("token has no user id")
+}
+
+var (
+	authMu        sync.RWMutex
+	authenticator Authenticator
+)
+
+// SetAuthenticator 替换默认的 Authenticator
+func SetAuthenticator(a Authenticator) {
+	authMu.Lock()
+	defer authMu.Unlock()
+	authenticator = a
+}
+
+// GetAuthenticator 返回当前的 Authenticator，未设置时按 JWT_* 配置创建
+// 配置错误（如没有设置 JWT_SECRET、密钥文件无法读取）时返回的 Authenticator 签发和校验都会失败
+func GetAuthenticator() Authenticator {
+	authMu.RLock()
+	a := authenticator
+	authMu.RUnlock()
+	if a != nil {
+		return a
+	}
+
+	authMu.Lock()
+	defer authMu.Unlock()
+	if authenticator == nil {
+		a, err := FromConfig()
+		if err != nil {
+			logs.Error().Err(err).Msg("invalid jwt config, all tokens will be rejected")
+			a = &failedAuthenticator{err: err}
+		}
+		authenticator = a
+	}
+	return authenticator
+}
+
+// FromConfig 按 JWT_* 配置创建 Authenticator
+func FromConfig() (Authenticator, error) {
+	cfg := routers.Config
+	opts := []Option{
+		WithKeyID(cfg.JWTKeyID),
+		WithIssuer(cfg.JWTIssuer),
+		WithAudience(cfg.JWTAudience...),
+		WithTTL(cfg.JWTTTL),
+		WithLeeway(cfg.JWTLeeway),
+	}
+	if cfg.JWTJWKS != "" {
+		keys, err := NewJWKS(cfg.JWTJWKS, cfg.JWTJWKSRefresh)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithKeySet(keys))
+	}
+
+	switch strings.ToUpper(cfg.JWTAlgorithm) {
+	case "", "HS256":
+		if cfg.JWT_SECRET == "" && cfg.JWTJWKS == "" {
+			return nil, errors.New("JWT_SECRET is required for HS256")
+		}
+		return NewHS256([]byte(cfg.JWT_SECRET), opts...), nil
+	case "RS256":
+		if cfg.JWTPrivateKey == "" {
+			if cfg.JWTJWKS == "" {
+				return nil, errors.New("JWT_PRIVATE_KEY or JWT_JWKS is required for RS256")
+			}
+			return NewRS256(nil, opts...), nil
+		}
+		pem, err := readPEM(cfg.JWTPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWT_PRIVATE_KEY: %w", err)
+		}
+		return NewRS256(key, opts...), nil
+	case "ES256":
+		if cfg.JWTPrivateKey == "" {
+			if cfg.JWTJWKS == "" {
+				return nil, errors.New("JWT_PRIVATE_KEY or JWT_JWKS is required for ES256")
+			}
+			return NewES256(nil, opts...), nil
+		}
+		pem, err := readPEM(cfg.JWTPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwt.ParseECPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWT_PRIVATE_KEY: %w", err)
+		}
+		return NewES256(key, opts...), nil
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %s, expected HS256, RS256 or ES256", cfg.JWTAlgorithm)
+	}
+}
+
+// readPEM 值以 -----BEGIN 开头时为 PEM 内容，否则为文件路径
+func readPEM(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return []byte(value), nil
+	}
+	data, err := os.ReadFile(value)
+	if err != nil {
+		return nil, fmt.Errorf("read JWT_PRIVATE_KEY: %w", err)
+	}
+	return data, nil
+}
+
+// failedAuthenticator 配置错误时使用，签发和校验都返回配置错误
+type failedAuthenticator struct {
+	err error
+}
+
+func (a *failedAuthenticator) Issue(userId uint, extra map[string]any) (string, error) {
+	return "", a.err
+}
+
+func (a *failedAuthenticator) Verify(token string) (jwt.MapClaims, error) {
+	return nil, a.err
+}
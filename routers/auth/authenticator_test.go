@@ -0,0 +1,444 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/light-speak/lighthouse/redis"
+	"github.com/light-speak/lighthouse/routers"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func writeJWKS(t *testing.T, rsaKey *rsa.PublicKey, ecKey *ecdsa.PublicKey) string {
+	t.Helper()
+	set := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "oct", "kid": "hs-1", "k": b64([]byte("rotated-secret"))},
+	}}
+	data, _ := json.Marshal(set)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAuthenticator(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	jwks, err := NewJWKS(writeJWKS(t, &rsaKey.PublicKey, &ecKey.PublicKey), 0)
+	if err != nil {
+		t.Fatalf("NewJWKS() error = %v", err)
+	}
+	expired, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 1, "exp": time.Now().Add(-time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+
+	tests := []struct {
+		name    string
+		issuer  Authenticator
+		token   string
+		verify  Authenticator
+		wantErr bool
+	}{
+		{"Test HS256", NewHS256([]byte("secret")), "", NewHS256([]byte("secret")), false},
+		{"Test HS256 Wrong Secret", NewHS256([]byte("secret")), "", NewHS256([]byte("other")), true},
+		{"Test HS256 Expired", nil, expired, NewHS256([]byte("secret")), true},
+		{"Test HS256 Rotated Kid", NewHS256([]byte("rotated-secret"), WithKeyID("hs-1")), "", NewHS256([]byte("secret"), WithKeyID("hs-2"), WithKeySet(jwks)), false},
+		{"Test RS256 JWKS", NewRS256(rsaKey, WithKeyID("rsa-1")), "", NewRS256(nil, WithKeySet(jwks)), false},
+		{"Test RS256 Unknown Kid", NewRS256(rsaKey, WithKeyID("rsa-2")), "", NewRS256(nil, WithKeySet(jwks)), true},
+		{"Test ES256 JWKS", NewES256(ecKey, WithKeyID("ec-1")), "", NewES256(nil, WithKeySet(jwks)), false},
+		{"Test ES256 Own Key", NewES256(ecKey), "", NewES256(ecKey), false},
+		{"Test Algorithm Mismatch", NewHS256([]byte("secret"), WithKeyID("rsa-1")), "", NewRS256(nil, WithKeySet(jwks)), true},
+		{"Test Issuer Audience", NewHS256([]byte("secret"), WithIssuer("lighthouse"), WithAudience("api")), "", NewHS256([]byte("secret"), WithIssuer("lighthouse"), WithAudience("api", "admin")), false},
+		{"Test Issuer Mismatch", NewHS256([]byte("secret"), WithIssuer("other")), "", NewHS256([]byte("secret"), WithIssuer("lighthouse")), true},
+		{"Test Audience Mismatch", NewHS256([]byte("secret"), WithAudience("web")), "", NewHS256([]byte("secret"), WithAudience("api")), true},
+		{"Test Verify Only", NewRS256(nil, WithKeySet(jwks)), "", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token := test.token
+			if test.issuer != nil {
+				token, err = test.issuer.Issue(42, map[string]any{"tenant_id": "acme"})
+				if err != nil {
+					if !test.wantErr {
+						t.Fatalf("Issue() error = %v", err)
+					}
+					return
+				}
+			}
+			claims, err := test.verify.Verify(token)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Verify() error = %v; wantErr %v", err, test.wantErr)
+			}
+			if err == nil {
+				if id, _ := userIdFromClaims(claims); id != 42 || claims["tenant_id"] != "acme" {
+					t.Errorf("Verify() claims = %v", claims)
+				}
+			}
+		})
+	}
+}
+
+func TestJWKSReload(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"keys":[{"kty":"oct","kid":"hs-1","k":"` + b64([]byte("secret")) + `"}]}`))
+	}))
+	defer server.Close()
+
+	jwks, err := NewJWKS(server.URL, 0)
+	if err != nil {
+		t.Fatalf("NewJWKS() error = %v", err)
+	}
+	lookup := func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = jwks.Key("unknown")
+			}()
+		}
+		wg.Wait()
+	}
+
+	t.Run("Test Unknown Kid Throttled", func(t *testing.T) {
+		lookup()
+		if n := requests.Load(); n != 1 {
+			t.Errorf("requests = %d; expected 1", n)
+		}
+	})
+
+	t.Run("Test Concurrent Reload Once", func(t *testing.T) {
+		jwks.mu.Lock()
+		jwks.attempted = time.Now().Add(-time.Minute)
+		jwks.mu.Unlock()
+		lookup()
+		lookup()
+		if n := requests.Load(); n != 2 {
+			t.Errorf("requests = %d; expected 2", n)
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	SetAuthenticator(NewHS256([]byte("secret"), WithTTL(time.Minute)))
+	t.Cleanup(func() { SetAuthenticator(nil) })
+
+	var userId uint
+	handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId = GetCtxUserId(r.Context())
+	}))
+	token, err := GetToken(7)
+	if err != nil {
+		t.Fatalf("GetToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		header   string
+		status   int
+		expected uint
+	}{
+		{"Test Middleware Bearer", "Bearer " + token, http.StatusOK, 7},
+		{"Test Middleware Anonymous", "", http.StatusOK, 0},
+		{"Test Middleware Invalid", "Bearer invalid", http.StatusUnauthorized, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userId = 0
+			r := httptest.NewRequest(http.MethodPost, "/query", nil)
+			if test.header != "" {
+				r.Header.Set("Authorization", test.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != test.status || userId != test.expected {
+				t.Errorf("status = %d, user id = %d; expected %d, %d", w.Code, userId, test.status, test.expected)
+			}
+		})
+	}
+
+	t.Run("Test WebSocket Init", func(t *testing.T) {
+		ctx, _, err := WebSocketInitFunc(httptest.NewRequest(http.MethodGet, "/", nil).Context(), map[string]any{"Authorization": "Bearer " + token})
+		if err != nil || GetCtxUserId(ctx) != 7 {
+			t.Errorf("WebSocketInitFunc() = %d, %v", GetCtxUserId(ctx), err)
+		}
+	})
+
+	t.Run("Test Config Without Secret", func(t *testing.T) {
+		secret := routers.Config.JWT_SECRET
+		routers.Config.JWT_SECRET = ""
+		defer func() { routers.Config.JWT_SECRET = secret }()
+		if _, err := FromConfig(); err == nil {
+			t.Error("FromConfig() expected error without JWT_SECRET")
+		}
+	})
+}
+
+func TestSession(t *testing.T) {
+	SetAuthenticator(NewHS256([]byte("secret"), WithTTL(time.Minute)))
+	SetRevocationStore(NewMemoryStore())
+	t.Cleanup(func() {
+		SetAuthenticator(nil)
+		SetRevocationStore(nil)
+	})
+	ctx := context.Background()
+
+	t.Run("Test Refresh Rotation", func(t *testing.T) {
+		pair, err := IssueTokenPair(ctx, 1, map[string]any{"tenant_id": 3})
+		if err != nil {
+			t.Fatalf("IssueTokenPair() error = %v", err)
+		}
+		if _, err := Authenticate(ctx, pair.RefreshToken); err != ErrNotAccessToken {
+			t.Errorf("Authenticate(refresh) error = %v, expected ErrNotAccessToken", err)
+		}
+		if _, err := Refresh(ctx, pair.AccessToken); err != ErrNotRefreshToken {
+			t.Errorf("Refresh(access) error = %v, expected ErrNotRefreshToken", err)
+		}
+		next, err := Refresh(ctx, pair.RefreshToken)
+		if err != nil {
+			t.Fatalf("Refresh() error = %v", err)
+		}
+		claims, err := Authenticate(ctx, next.AccessToken)
+		if err != nil || claims["tenant_id"] != float64(3) {
+			t.Fatalf("Authenticate() = %v, %v", claims, err)
+		}
+
+		// 旧的 refresh token 再次使用，整个家族被吊销
+		if _, err := Refresh(ctx, pair.RefreshToken); err != ErrRefreshTokenReused {
+			t.Errorf("Refresh(reused) error = %v, expected ErrRefreshTokenReused", err)
+		}
+		if _, err := Authenticate(ctx, next.AccessToken); err != ErrTokenRevoked {
+			t.Errorf("Authenticate() after reuse error = %v, expected ErrTokenRevoked", err)
+		}
+		if _, err := Refresh(ctx, next.RefreshToken); err != ErrTokenRevoked {
+			t.Errorf("Refresh() after reuse error = %v, expected ErrTokenRevoked", err)
+		}
+	})
+
+	t.Run("Test Revoke", func(t *testing.T) {
+		access, err := GetToken(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, _ := GetToken(2)
+		if err := Revoke(ctx, access); err != nil {
+			t.Fatalf("Revoke() error = %v", err)
+		}
+		if _, err := GetUserId(access); err != ErrTokenRevoked {
+			t.Errorf("GetUserId() error = %v, expected ErrTokenRevoked", err)
+		}
+		if _, err := GetUserId(other); err != nil {
+			t.Errorf("GetUserId(other) error = %v", err)
+		}
+
+		pair, _ := IssueTokenPair(ctx, 2, nil)
+		if err := Revoke(ctx, pair.RefreshToken); err != nil {
+			t.Fatalf("Revoke(refresh) error = %v", err)
+		}
+		if _, err := Authenticate(ctx, pair.AccessToken); err != ErrTokenRevoked {
+			t.Errorf("Authenticate() after logout error = %v, expected ErrTokenRevoked", err)
+		}
+	})
+
+	t.Run("Test Revoke User", func(t *testing.T) {
+		pair, _ := IssueTokenPair(ctx, 4, nil)
+		token, _ := GetToken(4)
+		other, _ := GetToken(5)
+		if err := RevokeUser(ctx, 4); err != nil {
+			t.Fatalf("RevokeUser() error = %v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+		for _, revoked := range []string{pair.AccessToken, token} {
+			if _, err := Authenticate(ctx, revoked); err != ErrTokenRevoked {
+				t.Errorf("Authenticate() error = %v, expected ErrTokenRevoked", err)
+			}
+		}
+		if _, err := Refresh(ctx, pair.RefreshToken); err != ErrTokenRevoked {
+			t.Errorf("Refresh() error = %v, expected ErrTokenRevoked", err)
+		}
+		if _, err := Authenticate(ctx, other); err != nil {
+			t.Errorf("Authenticate(other user) error = %v", err)
+		}
+		fresh, _ := GetToken(4)
+		if _, err := Authenticate(ctx, fresh); err != nil {
+			t.Errorf("Authenticate(new token) error = %v", err)
+		}
+	})
+
+	t.Run("Test Middleware Rejects Revoked", func(t *testing.T) {
+		token, _ := GetToken(6)
+		_ = Revoke(ctx, token)
+		r := httptest.NewRequest(http.MethodPost, "/query", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, r)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d, expected %d", w.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("Test Redis Unavailable Fails Closed", func(t *testing.T) {
+		_ = redis.Init(&redis.Config{Enable: true, Host: "127.0.0.1", Port: "1"})
+		SetRevocationStore(nil)
+		defer func() {
+			_ = redis.Init(redis.DefaultConfig())
+			SetRevocationStore(NewMemoryStore())
+		}()
+		token, _ := GetToken(7)
+		if _, err := Authenticate(ctx, token); err == nil {
+			t.Error("Authenticate() should fail while redis is configured but unavailable")
+		}
+		if s, _ := currentStore(); s != (redisStore{}) {
+			t.Errorf("store = %T, expected redisStore", s)
+		}
+	})
+}
+
+type testClaims struct {
+	Claims
+	Plan string `json:"plan"`
+}
+
+func TestPrincipal(t *testing.T) {
+	SetAuthenticator(NewHS256([]byte("secret"), WithTTL(time.Minute)))
+	SetRevocationStore(NewMemoryStore())
+	t.Cleanup(func() {
+		SetAuthenticator(nil)
+		SetRevocationStore(nil)
+	})
+
+	var principal *Principal
+	handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal = GetPrincipal(r.Context())
+	}))
+	serve := func(token string) {
+		principal = nil
+		r := httptest.NewRequest(http.MethodPost, "/query", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	t.Run("Test Principal From Claims Struct", func(t *testing.T) {
+		token, err := GetToken(8, testClaims{Claims: Claims{Roles: []string{"admin"}, Scopes: []string{"post:write"}, TenantId: "acme"}, Plan: "pro"})
+		if err != nil {
+			t.Fatalf("GetToken() error = %v", err)
+		}
+		serve(token)
+		if principal == nil || principal.UserId != 8 || principal.TenantId != "acme" || principal.TokenId == "" {
+			t.Fatalf("GetPrincipal() = %+v", principal)
+		}
+		if !principal.HasRole("editor", "admin") || principal.HasRole("editor") || !principal.HasScope("post:write") {
+			t.Errorf("roles = %v, scopes = %v", principal.Roles, principal.Scopes)
+		}
+		var claims testClaims
+		if err := principal.Decode(&claims); err != nil || claims.Plan != "pro" || claims.Roles[0] != "admin" {
+			t.Errorf("Decode() = %+v, %v", claims, err)
+		}
+	})
+
+	t.Run("Test Principal From OAuth2 Scope", func(t *testing.T) {
+		token, _ := GetToken(9, map[string]any{"role": "user", "scope": "read write"})
+		serve(token)
+		if !principal.HasRole("user") || !principal.HasScope("write") || principal.Claim("scope") != "read write" {
+			t.Errorf("GetPrincipal() = %+v", principal)
+		}
+	})
+
+	t.Run("Test Principal Session", func(t *testing.T) {
+		pair, _ := IssueTokenPair(context.Background(), 10, Claims{Roles: []string{"admin"}})
+		serve(pair.AccessToken)
+		if principal.SessionId == "" || !principal.HasRole("admin") {
+			t.Errorf("GetPrincipal() = %+v", principal)
+		}
+	})
+
+	t.Run("Test Anonymous", func(t *testing.T) {
+		ctx := context.Background()
+		if GetPrincipal(ctx) != nil || GetPrincipal(ctx).HasRole("admin") || IsLogin(ctx) {
+			t.Error("anonymous context should have no principal")
+		}
+		ctx = WithPrincipal(ctx, &Principal{UserId: 3})
+		if GetCtxUserId(ctx) != 3 || !IsLogin(ctx) || !IsCurrentUser(ctx, 3) {
+			t.Errorf("GetCtxUserId() = %d", GetCtxUserId(ctx))
+		}
+	})
+}
+
+// countingStore 统计 MGet 的调用次数
+type countingStore struct {
+	RevocationStore
+	gets int
+}
+
+func (s *countingStore) MGet(ctx context.Context, keys ...string) ([]string, error) {
+	s.gets++
+	return s.RevocationStore.MGet(ctx, keys...)
+}
+
+func TestRevocationStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Test Memory Store", func(t *testing.T) {
+		m := NewMemoryStore().(*memoryStore)
+		_ = m.Set(ctx, "old", "1", -time.Second)
+		_ = m.Set(ctx, "a", "1", time.Minute)
+		values, err := m.MGet(ctx, "a", "old", "missing")
+		if err != nil || len(values) != 3 || values[0] != "1" || values[1] != "" || values[2] != "" {
+			t.Errorf("MGet() = %q, %v", values, err)
+		}
+		// 已写入 2 次，第 pruneEvery 次写入时清理
+		for i := 2; i < pruneEvery; i++ {
+			if _, ok := m.entries["old"]; !ok {
+				t.Fatalf("expired entry pruned after %d writes, expected %d", i, pruneEvery)
+			}
+			_ = m.Set(ctx, "b", "1", time.Minute)
+		}
+		if _, ok := m.entries["old"]; ok {
+			t.Error("expired entry should be pruned")
+		}
+	})
+
+	t.Run("Test Single Lookup Per Authentication", func(t *testing.T) {
+		s := &countingStore{RevocationStore: NewMemoryStore()}
+		SetAuthenticator(NewHS256([]byte("secret"), WithTTL(time.Minute)))
+		SetRevocationStore(s)
+		t.Cleanup(func() {
+			SetAuthenticator(nil)
+			SetRevocationStore(nil)
+		})
+		pair, err := IssueTokenPair(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Authenticate(ctx, pair.AccessToken); err != nil {
+			t.Fatal(err)
+		}
+		if s.gets != 1 {
+			t.Errorf("MGet called %d times, expected 1", s.gets)
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/light-speak/lighthouse/logs"
+	"golang.org/x/sync/singleflight"
+)
+
+// KeySet 按 kid 查找校验 token 的密钥
+type KeySet interface {
+	Key(kid string) (any, error)
+}
+
+// Keys 固定的密钥集合，值为 []byte（HS256）、*rsa.PublicKey 或 *ecdsa.PublicKey
+type Keys map[string]any
+
+func (k Keys) Key(kid string) (any, error) {
+	if key, ok := k[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// jwksMinReload 两次尝试重新加载 JWKS 的最小间隔，防止伪造的 kid 或不可用的签发方频繁触发加载
+const jwksMinReload = 10 * time.Second
+
+// JWKS 从文件或 URL 加载的 JSON Web Key Set
+// 遇到未知 kid 时重新加载，签发方轮换密钥后无需重启；refresh > 0 时还会定期重新加载
+// 并发请求同时触发时只加载一次，其余请求等待并共用结果
+type JWKS struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+	group   singleflight.Group
+
+	mu        sync.RWMutex
+	keys      map[string]any
+	fetched   time.Time
+	attempted time.Time // 最近一次尝试加载的时间，加载失败时也会更新
+}
+
+// NewJWKS 创建并立即加载 JWKS，source 为文件路径或 http(s) URL
+func NewJWKS(source string, refresh time.Duration) (*JWKS, error) {
+	j := &JWKS{source: source, refresh: refresh, client: &http.Client{Timeout: 10 * time.Second}}
+	if err := j.reload(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *JWKS) Key(kid string) (any, error) {
+	j.mu.RLock()
+	key, ok := j.keys[kid]
+	stale := j.refresh > 0 && time.Since(j.fetched) > j.refresh
+	throttled := time.Since(j.attempted) < jwksMinReload
+	j.mu.RUnlock()
+
+	if (!ok || stale) && !throttled {
+		if err := j.tryReload(); err != nil {
+			logs.Warn().Err(err).Str("source", j.source).Msg("failed to reload jwks, using cached keys")
+		}
+		j.mu.RLock()
+		key, ok = j.keys[kid]
+		j.mu.RUnlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// tryReload 合并并发的加载请求，距上次尝试不足 jwksMinReload 时跳过
+func (j *JWKS) tryReload() error {
+	_, err, _ := j.group.Do("reload", func() (any, error) {
+		j.mu.Lock()
+		if time.Since(j.attempted) < jwksMinReload {
+			j.mu.Unlock()
+			return nil, nil
+		}
+		j.attempted = time.Now()
+		j.mu.Unlock()
+		return nil, j.reload()
+	})
+	return err
+}
+
+func (j *JWKS) reload() error {
+	data, err := j.read()
+	if err != nil {
+		return fmt.Errorf("load jwks %s: %w", j.source, err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("parse jwks %s: %w", j.source, err)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.keys = keys
+	j.fetched = time.Now()
+	j.attempted = j.fetched
+	return nil
+}
+
+func (j *JWKS) read() ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		return os.ReadFile(j.source)
+	}
+	resp, err := j.client.Get(j.source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// parseJWKS 解析 RSA、P-256 EC 和 oct（HS256 共享密钥）类型的密钥，跳过 use 不是 sig 的密钥
+func parseJWKS(data []byte) (map[string]any, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.key()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) key() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if x.BitLen() > 256 || y.BitLen() > 256 {
+			return nil, fmt.Errorf("invalid P-256 point")
+		}
+		point := append([]byte{4}, x.FillBytes(make([]byte, 32))...)
+		point = append(point, y.FillBytes(make([]byte, 32))...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid P-256 point: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims 签发 token 时常用的 claim，可以嵌入到自定义结构体中扩展：
+//
+//	type UserClaims struct {
+//		auth.Claims
+//		Plan string `json:"plan"`
+//	}
+//	token, err := auth.GetToken(user.ID, UserClaims{Claims: auth.Claims{Roles: []string{"admin"}}, Plan: "pro"})
+type Claims struct {
+	Roles    []string `json:"roles,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+	TenantId string   `json:"tenant_id,omitempty"`
+}
+
+// Principal 当前请求的用户，由 Middleware、WebSocketInitFunc 按 token 的 claim 创建
+type Principal struct {
+	UserId uint
+	Roles  []string
+	Scopes []string
+	// TenantId 为 tenant_id claim，没有时为空
+	TenantId string
+	// SessionId 为 IssueTokenPair 签发的 token 家族 ID，GetToken 签发的 token 为空
+	SessionId string
+	// TokenId 为 jti
+	TokenId string
+	// Claims 为 token 的全部 claim，X-User-Id 认证时为空
+	Claims jwt.MapClaims
+}
+
+// NewPrincipal 按 claim 创建 Principal
+// roles 读取字符串数组或单个字符串；scopes 读取字符串数组，没有时读取以空格分隔的 scope（OAuth2）
+func NewPrincipal(claims jwt.MapClaims) (*Principal, error) {
+	userId, err := userIdFromClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+	p := &Principal{
+		UserId:    userId,
+		Roles:     stringsClaim(claims["roles"]),
+		Scopes:    stringsClaim(claims["scopes"]),
+		SessionId: stringClaim(claims["fid"]),
+		TokenId:   stringClaim(claims["jti"]),
+		TenantId:  stringClaim(claims["tenant_id"]),
+		Claims:    claims,
+	}
+	if len(p.Roles) == 0 {
+		p.Roles = stringsClaim(claims["role"])
+	}
+	if len(p.Scopes) == 0 {
+		if scope, ok := claims["scope"].(string); ok {
+			p.Scopes = strings.Fields(scope)
+		}
+	}
+	return p, nil
+}
+
+// HasRole 是否拥有任意一个角色，未登录时返回 false
+func (p *Principal) HasRole(roles ...string) bool {
+	if p == nil {
+		return false
+	}
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope 是否拥有 scope，未登录时返回 false
+func (p *Principal) HasScope(scope string) bool {
+	return p != nil && slices.Contains(p.Scopes, scope)
+}
+
+// Claim 读取 claim，不存在或未登录时返回 nil
+func (p *Principal) Claim(name string) any {
+	if p == nil {
+		return nil
+	}
+	return p.Claims[name]
+}
+
+// Decode 把全部 claim 解析到自定义的 claim 结构体中，与签发时传入 GetToken 的结构体对应
+func (p *Principal) Decode(v any) error {
+	if p == nil {
+		return errors.New("not logged in")
+	}
+	data, err := json.Marshal(p.Claims)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WithPrincipal 把 Principal 写入 ctx，用于测试和自定义认证中间件
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, userContextKey, p)
+}
+
+// GetPrincipal 返回当前请求的用户，未登录时返回 nil（Principal 的方法可以在 nil 上调用）
+func GetPrincipal(ctx context.Context) *Principal {
+	p, _ := ctx.Value(userContextKey).(*Principal)
+	return p
+}
+
+// claimsMap 合并签发 token 时传入的 claim，支持 map 和可以 JSON 序列化的结构体
+func claimsMap(values []any) (map[string]any, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]any)
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case map[string]any:
+			for k, item := range v {
+				result[k] = item
+			}
+		case jwt.MapClaims:
+			for k, item := range v {
+				result[k] = item
+			}
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("marshal claims %T: %w", value, err)
+			}
+			m := make(map[string]any)
+			if err := json.Unmarshal(data, &m); err != nil {
+				return nil, fmt.Errorf("claims %T must be a struct or map: %w", value, err)
+			}
+			for k, item := range m {
+				result[k] = item
+			}
+		}
+	}
+	return result, nil
+}
+
+func stringClaim(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func stringsClaim(value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []string:
+		return v
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
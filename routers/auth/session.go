@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/light-speak/lighthouse/routers"
+)
+
+// 登录会话
+// IssueTokenPair 签发有效期较短的 access token 和有效期较长的 refresh token，两者属于同一个 token 家族（fid）；
+// Refresh 使用 refresh token 换取新的一对 token，旧的 refresh token 作废，再次使用时视为被盗用，整个家族被吊销；
+// Revoke 吊销单个 token（吊销 refresh token 时同时吊销整个家族，用于退出登录），RevokeUser 吊销用户的全部会话
+
+var (
+	// ErrTokenRevoked token 已被吊销
+	ErrTokenRevoked = errors.New("token has been revoked")
+	// ErrRefreshTokenReused refresh token 已经使用过，整个 token 家族已被吊销
+	ErrRefreshTokenReused = errors.New("refresh token has been reused, all tokens of this session are revoked")
+	// ErrNotRefreshToken 不是 refresh token
+	ErrNotRefreshToken = errors.New("not a refresh token")
+	// ErrNotAccessToken refresh token 不能用于访问接口
+	ErrNotAccessToken = errors.New("refresh token can not be used as access token")
+)
+
+const (
+	typeAccess  = "access"
+	typeRefresh = "refresh"
+)
+
+// reservedClaims Refresh 时不复制到新 token 的 claim，其余的自定义 claim（如 tenant_id）原样保留
+var reservedClaims = map[string]bool{
+	"user_id": true, "sub": true, "iat": true, "exp": true, "nbf": true,
+	"jti": true, "iss": true, "aud": true, "typ": true, "fid": true,
+}
+
+// TokenPair 一对 access token 和 refresh token
+type TokenPair struct {
+	AccessToken      string    `json:"accessToken"`
+	RefreshToken     string    `json:"refreshToken"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+// IssueTokenPair 为用户开启新的会话，签发 access token 和 refresh token，claims 与 GetToken 相同
+func IssueTokenPair(ctx context.Context, userId uint, claims ...any) (*TokenPair, error) {
+	extra, err := claimsMap(claims)
+	if err != nil {
+		return nil, err
+	}
+	fid, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	return issuePair(userId, fid, extra)
+}
+
+func issuePair(userId uint, fid string, extra map[string]any) (*TokenPair, error) {
+	now := time.Now()
+	pair := &TokenPair{
+		ExpiresAt:        now.Add(routers.Config.JWTAccessTTL).Truncate(time.Second),
+		RefreshExpiresAt: now.Add(routers.Config.JWTRefreshTTL).Truncate(time.Second),
+	}
+	a := GetAuthenticator()
+	var err error
+	if pair.AccessToken, err = a.Issue(userId, pairClaims(extra, typeAccess, fid, pair.ExpiresAt)); err != nil {
+		return nil, err
+	}
+	if pair.RefreshToken, err = a.Issue(userId, pairClaims(extra, typeRefresh, fid, pair.RefreshExpiresAt)); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+func pairClaims(extra map[string]any, typ string, fid string, exp time.Time) map[string]any {
+	claims := make(map[string]any, len(extra)+3)
+	for k, v := range extra {
+		claims[k] = v
+	}
+	claims["typ"] = typ
+	claims["fid"] = fid
+	claims["exp"] = exp.Unix()
+	return claims
+}
+
+// Refresh 使用 refresh token 换取新的一对 token，新 token 与旧 token 属于同一个家族
+// 每个 refresh token 只能使用一次，再次使用时吊销整个家族并返回 ErrRefreshTokenReused
+func Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := GetAuthenticator().Verify(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if typ, _ := claims["typ"].(string); typ != typeRefresh {
+		return nil, ErrNotRefreshToken
+	}
+	if err := checkRevoked(ctx, claims); err != nil {
+		return nil, err
+	}
+	userId, err := userIdFromClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+	jti, _ := claims["jti"].(string)
+	fid, _ := claims["fid"].(string)
+	if jti == "" || fid == "" {
+		return nil, errors.New("refresh token has no jti or fid")
+	}
+
+	s, err := currentStore()
+	if err != nil {
+		return nil, err
+	}
+	first, err := s.SetNX(ctx, "used:"+jti, "1", remaining(claims))
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		if err := revokeFamily(ctx, fid); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	extra := make(map[string]any)
+	for k, v := range claims {
+		if !reservedClaims[k] {
+			extra[k] = v
+		}
+	}
+	return issuePair(userId, fid, extra)
+}
+
+// Authenticate 校验 access token 并检查是否已被吊销，Middleware 和 WebSocketInitFunc 使用它认证用户
+func Authenticate(ctx context.Context, token string) (jwt.MapClaims, error) {
+	claims, err := GetAuthenticator().Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if typ, _ := claims["typ"].(string); typ == typeRefresh {
+		return nil, ErrNotAccessToken
+	}
+	if err := checkRevoked(ctx, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Revoke 吊销 token，吊销 refresh token 时同时吊销同一家族的全部 token（退出登录）
+func Revoke(ctx context.Context, token string) error {
+	claims, err := GetAuthenticator().Verify(token)
+	if err != nil {
+		return err
+	}
+	if jti, _ := claims["jti"].(string); jti != "" {
+		s, err := currentStore()
+		if err != nil {
+			return err
+		}
+		if err := s.Set(ctx, "jti:"+jti, "1", remaining(claims)); err != nil {
+			return err
+		}
+	}
+	if typ, _ := claims["typ"].(string); typ == typeRefresh {
+		if fid, _ := claims["fid"].(string); fid != "" {
+			return revokeFamily(ctx, fid)
+		}
+	}
+	return nil
+}
+
+// RevokeUser 吊销用户在此之前签发的全部 token（退出所有设备、修改密码后调用）
+func RevokeUser(ctx context.Context, userId uint) error {
+	s, err := currentStore()
+	if err != nil {
+		return err
+	}
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	return s.Set(ctx, userKey(userId), now, maxTTL())
+}
+
+func revokeFamily(ctx context.Context, fid string) error {
+	s, err := currentStore()
+	if err != nil {
+		return err
+	}
+	return s.Set(ctx, "fid:"+fid, "1", maxTTL())
+}
+
+// checkRevoked 依次检查 token、token 家族、用户的吊销记录
+func checkRevoked(ctx context.Context, claims jwt.MapClaims) error {
+	s, err := currentStore()
+	if err != nil {
+		return err
+	}
+	userId, err := userIdFromClaims(claims)
+	if err != nil {
+		return err
+	}
+	// token、家族与用户的吊销记录一次读取
+	keys := []string{userKey(userId)}
+	if jti, _ := claims["jti"].(string); jti != "" {
+		keys = append(keys, "jti:"+jti)
+	}
+	if fid, _ := claims["fid"].(string); fid != "" {
+		keys = append(keys, "fid:"+fid)
+	}
+	values, err := s.MGet(ctx, keys...)
+	if err != nil {
+		return err
+	}
+	if len(values) != len(keys) {
+		return fmt.Errorf("revocation store returned %d values for %d keys", len(values), len(keys))
+	}
+	for _, revoked := range values[1:] {
+		if revoked != "" {
+			return ErrTokenRevoked
+		}
+	}
+
+	cutoff := values[0]
+	if cutoff == "" {
+		return nil
+	}
+	ms, err := strconv.ParseInt(cutoff, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid revocation time of user %d: %w", userId, err)
+	}
+	// 与吊销时间在同一毫秒内签发的 token 同样视为已吊销
+	iat, _ := claims["iat"].(float64)
+	if int64(math.Round(iat*1000)) <= ms {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+func userKey(userId uint) string {
+	return "user:" + strconv.FormatUint(uint64(userId), 10)
+}
+
+// remaining token 剩余的有效期，吊销记录保存到 token 过期为止
+func remaining(claims jwt.MapClaims) time.Duration {
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return maxTTL()
+	}
+	ttl := time.Until(exp.Time) + routers.Config.JWTLeeway
+	if ttl <= 0 {
+		return time.Second
+	}
+	return ttl
+}
+
+// maxTTL 最长的 token 有效期，家族和用户的吊销记录保存这么久
+func maxTTL() time.Duration {
+	ttl := max(routers.Config.JWTTTL, routers.Config.JWTAccessTTL, routers.Config.JWTRefreshTTL)
+	if ttl <= 0 {
+		return DefaultTTL
+	}
+	return ttl + routers.Config.JWTLeeway
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/light-speak/lighthouse/logs"
+	"github.com/light-speak/lighthouse/redis"
+)
+
+// storePrefix 吊销记录的 key 前缀
+const storePrefix = "lighthouse:auth:"
+
+// RevocationStore 保存已吊销的 token、token 家族、用户吊销时间和已使用的 refresh token
+// 默认在开启 Redis 时使用 Redis，否则使用内存（只在单个进程内生效）
+type RevocationStore interface {
+	// Set 写入 key，ttl 后过期
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	// MGet 按顺序读取多个 key，不存在的 key 对应空字符串，认证时一次读取 token、家族与用户的吊销记录
+	MGet(ctx context.Context, keys ...string) ([]string, error)
+	// SetNX key 不存在时写入并返回 true，已存在时返回 false
+	SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
+}
+
+var (
+	storeMu sync.RWMutex
+	store   RevocationStore
+)
+
+// SetRevocationStore 替换吊销记录存储，测试中可以使用 NewMemoryStore
+func SetRevocationStore(s RevocationStore) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+// currentStore 按 Redis 配置（而不是当前能否连上 Redis）选择存储：开启 Redis 时始终使用 Redis，
+// Redis 暂时不可用时读写返回错误，认证失败而不是退回只在本进程生效的内存存储
+func currentStore() (RevocationStore, error) {
+	storeMu.RLock()
+	s := store
+	storeMu.RUnlock()
+	if s != nil {
+		return s, nil
+	}
+
+	enabled, err := redisEnabled()
+	if err != nil {
+		return nil, err
+	}
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	if store == nil {
+		if enabled {
+			store = redisStore{}
+		} else {
+			logs.Warn().Msg("redis is not enabled, token revocation is stored in memory and only effective in this process")
+			store = NewMemoryStore()
+		}
+	}
+	return store, nil
+}
+
+// redisEnabled 读取 Redis 配置，尚未加载时由 redis 包加载（并尝试连接）
+func redisEnabled() (bool, error) {
+	if redis.LightRedisConfig == nil {
+		if _, err := redis.GetClient(); err != nil && redis.LightRedisConfig == nil {
+			return false, err
+		}
+	}
+	return redis.LightRedisConfig.Enable, nil
+}
+
+// redisStore 使用 redis.LightRedisClient 保存吊销记录，多个实例共享
+type redisStore struct{}
+
+func (redisStore) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	client, err := redis.GetClient()
+	if err != nil {
+		return err
+	}
+	return client.Set(ctx, storePrefix+key, value, ttl).Err()
+}
+
+func (redisStore) MGet(ctx context.Context, keys ...string) ([]string, error) {
+	client, err := redis.GetClient()
+	if err != nil {
+		return nil, err
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = storePrefix + key
+	}
+	values, err := client.MGet(ctx, prefixed...).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, len(keys))
+	for i, value := range values {
+		result[i], _ = value.(string)
+	}
+	return result, nil
+}
+
+func (redisStore) SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	client, err := redis.GetClient()
+	if err != nil {
+		return false, err
+	}
+	return client.SetNX(ctx, storePrefix+key, value, ttl).Result()
+}
+
+type memoryEntry struct {
+	value   string
+	expires time.Time
+}
+
+// pruneEvery 内存存储每写入多少次清理一次过期记录，读取时会忽略过期的记录
+const pruneEvery = 1024
+
+// memoryStore 内存中的吊销记录
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	writes  int
+}
+
+// NewMemoryStore 创建内存吊销记录存储，用于测试和单实例部署
+func NewMemoryStore() RevocationStore {
+	return &memoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (m *memoryStore) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.write(key, value, ttl)
+	return nil
+}
+
+func (m *memoryStore) MGet(ctx context.Context, keys ...string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	result := make([]string, len(keys))
+	for i, key := range keys {
+		if entry, ok := m.entries[key]; ok && now.Before(entry.expires) {
+			result[i] = entry.value
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryStore) SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry, ok := m.entries[key]; ok && time.Now().Before(entry.expires) {
+		return false, nil
+	}
+	m.write(key, value, ttl)
+	return true, nil
+}
+
+// write 写入记录，每 pruneEvery 次写入清理一次过期记录，调用方持有锁
+func (m *memoryStore) write(key string, value string, ttl time.Duration) {
+	m.entries[key] = memoryEntry{value: value, expires: time.Now().Add(ttl)}
+	m.writes++
+	if m.writes%pruneEvery == 0 {
+		m.prune()
+	}
+}
+
+// prune 删除过期的记录，调用方持有锁
+func (m *memoryStore) prune() {
+	now := time.Now()
+	for key, entry := range m.entries {
+		if now.After(entry.expires) {
+			delete(m.entries, key)
+		}
+	}
+}
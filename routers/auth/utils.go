@@ -1,37 +1,31 @@
 package auth
 
 import (
-	"errors"
-	"time"
+	"context"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GetToken(userId uint) (string, error) {
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, buildClaims(userId)).SignedString(key)
-}
-
-func buildClaims(userId uint) *claim {
-	now := time.Now()
-	return &claim{
-		UserId: userId,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour * 24 * 30)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			Issuer:    "",
-		},
+// GetToken 使用当前的 Authenticator 为用户签发单个 token，需要刷新和退出登录时使用 IssueTokenPair
+// claims 为额外的 claim，可以是 map 或 Claims、嵌入 Claims 的自定义结构体，校验后通过 GetPrincipal 读取
+func GetToken(userId uint, claims ...any) (string, error) {
+	extra, err := claimsMap(claims)
+	if err != nil {
+		return "", err
 	}
+	return GetAuthenticator().Issue(userId, extra)
 }
 
+// GetUserId 校验 token 并返回用户 ID，已吊销的 token 不能通过
 func GetUserId(token string) (uint, error) {
-	t, err := jwt.ParseWithClaims(token, &claim{}, func(token *jwt.Token) (interface{}, error) {
-		return key, nil
-	})
+	claims, err := Authenticate(context.Background(), token)
 	if err != nil {
 		return 0, err
 	}
-	if claims, ok := t.Claims.(*claim); ok && t.Valid {
-		return claims.UserId, nil
-	}
-	return 0, errors.New("invalid token")
+	return userIdFromClaims(claims)
+}
+
+// ParseClaims 校验 token 并返回全部 claim，用于读取 user_id 以外的自定义 claim（如 tenant_id），不检查是否已被吊销
+func ParseClaims(token string) (jwt.MapClaims, error) {
+	return GetAuthenticator().Verify(token)
 }
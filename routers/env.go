@@ -1,71 +1,85 @@
 package routers
 
 import (
-	"log"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
-	"github.com/joho/godotenv"
-	"github.com/light-speak/lighthouse/utils"
+	"github.com/light-speak/lighthouse/config"
+	"github.com/light-speak/lighthouse/logs"
 )
 
 // JWT_SECRET is the secret key for the JWT token
 type middlewareConfig struct {
-	// JWT SECRET
-	JWT_SECRET string
+	// JWT SECRET, required for HS256, there is no default value
+	JWT_SECRET string `env:"JWT_SECRET" secret:"true"`
+	// JWTAlgorithm is the signing algorithm: HS256 | RS256 | ES256
+	JWTAlgorithm string `env:"JWT_ALGORITHM"`
+	// JWTPrivateKey is the PEM file path or PEM content of the RS256 / ES256 signing key
+	JWTPrivateKey string `env:"JWT_PRIVATE_KEY" secret:"true"`
+	// JWTKeyID is the kid written to the token header
+	JWTKeyID string `env:"JWT_KEY_ID"`
+	// JWTJWKS is the JWKS file path or URL used to verify tokens by kid
+	JWTJWKS string `env:"JWT_JWKS"`
+	// JWTJWKSRefresh is the interval to reload the JWKS, 0 means only reload on unknown kid
+	JWTJWKSRefresh time.Duration `env:"JWT_JWKS_REFRESH"`
+	// JWTIssuer is the iss claim to issue and validate
+	JWTIssuer string `env:"JWT_ISSUER"`
+	// JWTAudience is the aud claim to issue and validate
+	JWTAudience []string `env:"JWT_AUDIENCE"`
+	// JWTTTL is the lifetime of the issued token
+	JWTTTL time.Duration `env:"JWT_TTL"`
+	// JWTAccessTTL is the lifetime of the access token issued by IssueTokenPair / Refresh
+	JWTAccessTTL time.Duration `env:"JWT_ACCESS_TTL"`
+	// JWTRefreshTTL is the lifetime of the refresh token issued by IssueTokenPair / Refresh
+	JWTRefreshTTL time.Duration `env:"JWT_REFRESH_TTL"`
+	// JWTLeeway is the clock skew allowed when validating exp / nbf
+	JWTLeeway time.Duration `env:"JWT_LEEWAY"`
 	// HeartbeatPath is the path for the liveness endpoint
-	HeartbeatPath string
+	HeartbeatPath string `env:"MID_HEARTBEAT_PATH"`
 	// ReadinessPath is the path for the readiness endpoint
-	ReadinessPath string
+	ReadinessPath string `env:"MID_READINESS_PATH"`
+	// AdminToken protects the admin endpoints, they are disabled when empty
+	AdminToken string `env:"MID_ADMIN_TOKEN" secret:"true"`
+	// SlowQueryPath is the path for the slow query admin endpoint
+	SlowQueryPath string `env:"MID_SLOW_QUERY_PATH"`
 	// CompressLevel is the level of compression for the response
-	CompressLevel int
+	CompressLevel int `env:"MID_COMPRESS_LEVEL"`
 	// Timeout is the timeout for the request
-	Timeout time.Duration
+	Timeout time.Duration `env:"MID_TIMEOUT"`
 	// Throttle is the throttle for the request
-	Throttle int
+	Throttle int `env:"MID_THROTTLE"`
 
-	CORSAllowOrigins []string
-	CORSAllowMethods []string
-	CORSAllowHeaders []string
+	CORSAllowOrigins []string `env:"CORS_ALLOW_ORIGINS"`
+	CORSAllowMethods []string `env:"CORS_ALLOW_METHODS"`
+	CORSAllowHeaders []string `env:"CORS_ALLOW_HEADERS"`
 }
 
 var Config *middlewareConfig
 
-func init() {
-	Config = &middlewareConfig{
-		JWT_SECRET:       "IWY@*3JUI#d309HhefzX2WpLtPKtD!hn",
+func loadConfig() (*middlewareConfig, error) {
+	cfg := &middlewareConfig{
+		JWTAlgorithm:     "HS256",
+		JWTTTL:           30 * 24 * time.Hour,
+		JWTAccessTTL:     15 * time.Minute,
+		JWTRefreshTTL:    30 * 24 * time.Hour,
 		HeartbeatPath:    "/health",
 		ReadinessPath:    "/ready",
+		SlowQueryPath:    "/admin/slow-queries",
 		CompressLevel:    5,
-		Timeout:          10 * time.Second,
+		Timeout:          30 * time.Second,
 		Throttle:         100,
 		CORSAllowOrigins: []string{"*"},
 		CORSAllowMethods: []string{"GET", "POST", "OPTIONS", "PUT", "DELETE", "PATCH"},
 		CORSAllowHeaders: []string{"*"},
 	}
+	err := config.Bind(cfg)
+	return cfg, err
+}
 
-	if curPath, err := os.Getwd(); err == nil {
-		err = godotenv.Load(filepath.Join(curPath, ".env"))
-		if err != nil {
-			log.Println("Error loading .env file:", err)
-		}
-	}
-
-	Config.JWT_SECRET = utils.GetEnv("JWT_SECRET", Config.JWT_SECRET)
-	Config.HeartbeatPath = utils.GetEnv("MID_HEARTBEAT_PATH", Config.HeartbeatPath)
-	Config.ReadinessPath = utils.GetEnv("MID_READINESS_PATH", Config.ReadinessPath)
-	Config.CompressLevel = utils.GetEnvInt("MID_COMPRESS_LEVEL", Config.CompressLevel)
-	Config.Timeout = time.Duration(utils.GetEnvInt("MID_TIMEOUT", 30)) * time.Second
-	Config.Throttle = utils.GetEnvInt("MID_THROTTLE", Config.Throttle)
-	if origins := utils.GetEnv("CORS_ALLOW_ORIGINS", ""); origins != "" {
-		Config.CORSAllowOrigins = strings.Split(origins, ",")
-	}
-	if methods := utils.GetEnv("CORS_ALLOW_METHODS", ""); methods != "" {
-		Config.CORSAllowMethods = strings.Split(methods, ",")
-	}
-	if headers := utils.GetEnv("CORS_ALLOW_HEADERS", ""); headers != "" {
-		Config.CORSAllowHeaders = strings.Split(headers, ",")
+func init() {
+	cfg, err := loadConfig()
+	if err != nil {
+		logs.Error().Err(err).Msg("invalid middleware config, falling back to defaults for invalid values")
 	}
+	Config = cfg
+	config.Register("middleware", func() (any, error) { return loadConfig() })
 }
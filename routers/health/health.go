@@ -89,12 +89,35 @@ func ReadinessHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 检查数据库
-	dbCheck := checkDatabase(cfg)
+	dbCheck := checkDatabase(cfg, databases.LightDatabaseClient)
 	status.Checks["database"] = dbCheck
 	if dbCheck.Status == "unhealthy" {
 		status.Status = "unhealthy"
 	}
 
+	// 检查命名连接
+	for _, name := range databases.ConnectionNames() {
+		conn, err := databases.Connection(name)
+		connCheck := CheckResult{Status: "unhealthy"}
+		if err != nil {
+			connCheck.Message = err.Error()
+		} else {
+			connCheck = checkDatabase(cfg, conn)
+		}
+		status.Checks["database:"+name] = connCheck
+		if connCheck.Status == "unhealthy" {
+			status.Status = "unhealthy"
+		}
+	}
+
+	// 检查从库，全部不可用时降级（读操作会回退到主库）
+	if replicaCheck, ok := checkReplicas(); ok {
+		status.Checks["replicas"] = replicaCheck
+		if replicaCheck.Status == "unhealthy" && status.Status == "healthy" {
+			status.Status = "degraded"
+		}
+	}
+
 	// 检查内存
 	memCheck := checkMemory(cfg)
 	status.Checks["memory"] = memCheck
@@ -103,11 +126,22 @@ func ReadinessHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 检查数据库连接池使用率
-	poolCheck := checkDBPool(cfg)
+	poolCheck := checkDBPool(cfg, databases.LightDatabaseClient)
 	status.Checks["db_pool"] = poolCheck
 	if poolCheck.Status == "unhealthy" && status.Status == "healthy" {
 		status.Status = "degraded"
 	}
+	for _, name := range databases.ConnectionNames() {
+		conn, err := databases.Connection(name)
+		if err != nil {
+			continue
+		}
+		poolCheck := checkDBPool(cfg, conn)
+		status.Checks["db_pool:"+name] = poolCheck
+		if poolCheck.Status == "unhealthy" && status.Status == "healthy" {
+			status.Status = "degraded"
+		}
+	}
 
 	// 设置响应
 	w.Header().Set("Content-Type", "application/json")
@@ -134,8 +168,8 @@ func LivenessHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // checkDatabase 检查数据库连接
-func checkDatabase(cfg *Config) CheckResult {
-	if databases.LightDatabaseClient == nil || !databases.LightDatabaseClient.Completed {
+func checkDatabase(cfg *Config, conn *databases.LightDatabase) CheckResult {
+	if conn == nil {
 		return CheckResult{
 			Status:  "unhealthy",
 			Message: "database not initialized",
@@ -146,7 +180,7 @@ func checkDatabase(cfg *Config) CheckResult {
 	defer cancel()
 
 	start := time.Now()
-	db, err := databases.LightDatabaseClient.GetDB(ctx)
+	db, err := conn.GetDB(ctx)
 	if err != nil {
 		return CheckResult{
 			Status:  "unhealthy",
@@ -175,6 +209,32 @@ func checkDatabase(cfg *Config) CheckResult {
 	}
 }
 
+// checkReplicas 检查从库健康状态，未配置从库时返回 false
+func checkReplicas() (CheckResult, bool) {
+	replicas := databases.LightDatabaseClient.ReplicaStatus()
+	if len(replicas) == 0 {
+		return CheckResult{}, false
+	}
+
+	healthy := 0
+	for _, r := range replicas {
+		if r.Healthy {
+			healthy++
+		}
+	}
+	if healthy == 0 {
+		return CheckResult{
+			Status:  "unhealthy",
+			Message: "no healthy replica, reads fall back to main database",
+		}, true
+	}
+
+	return CheckResult{
+		Status:  "healthy",
+		Message: fmt.Sprintf("healthy=%d total=%d", healthy, len(replicas)),
+	}, true
+}
+
 // checkMemory 检查内存使用
 func checkMemory(cfg *Config) CheckResult {
 	var m runtime.MemStats
@@ -195,8 +255,8 @@ func checkMemory(cfg *Config) CheckResult {
 }
 
 // checkDBPool 检查数据库连接池使用率
-func checkDBPool(cfg *Config) CheckResult {
-	if databases.LightDatabaseClient == nil || !databases.LightDatabaseClient.Completed {
+func checkDBPool(cfg *Config, conn *databases.LightDatabase) CheckResult {
+	if conn == nil {
 		return CheckResult{
 			Status:  "unhealthy",
 			Message: "database not initialized",
@@ -204,7 +264,7 @@ func checkDBPool(cfg *Config) CheckResult {
 	}
 
 	ctx := context.Background()
-	db, err := databases.LightDatabaseClient.GetDB(ctx)
+	db, err := conn.GetDB(ctx)
 	if err != nil {
 		return CheckResult{
 			Status:  "unhealthy",
@@ -2,82 +2,204 @@ package own
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/99designs/gqlgen/graphql"
-	"github.com/bytedance/sonic"
+	"github.com/light-speak/lighthouse/lighterr"
 	"github.com/light-speak/lighthouse/logs"
 	"github.com/light-speak/lighthouse/routers/auth"
+	"github.com/vektah/gqlparser/v2/ast"
 )
 
-func OwnDirective(ctx context.Context, obj interface{}, next graphql.Resolver, field string) (interface{}, error) {
-	// Get field context
-	fieldCtx := graphql.GetFieldContext(ctx)
-	if fieldCtx == nil {
-		return nil, errors.New("failed to get field context")
+// Denial 不是所有者时的处理方式，对应 GraphQL 枚举 OwnDenial
+type Denial string
+
+const (
+	// DenyMask 返回字段类型的零值（String 为 ""，ID 为 uint(0)，Int 为 int32(0)，Float 为 0.0，Boolean 为 false），可空字段返回 null
+	// 零值的 Go 类型与脚手架 gqlgen.yml 的 models 一致（ID → scalars.Uint，Int → graphql.Int32），其他非空类型没有可用的零值，返回错误
+	DenyMask Denial = "MASK"
+	// DenyNull 返回 null，只用于可空字段，用在非空字段上时返回错误
+	DenyNull Denial = "NULL"
+	// DenyError 返回错误：未登录为 Unauthorized，已登录为 Forbidden
+	DenyError Denial = "ERROR"
+)
+
+// UnmarshalGQL 实现 graphql.Unmarshaler
+func (d *Denial) UnmarshalGQL(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("OwnDenial must be a string, got %T", v)
+	}
+	switch Denial(s) {
+	case DenyMask, DenyNull, DenyError:
+		*d = Denial(s)
+		return nil
 	}
+	return fmt.Errorf("%s is not a valid OwnDenial", s)
+}
 
-	fieldType := fieldCtx.Field.Definition.Type
-	userId := auth.GetCtxUserId(ctx)
+// MarshalGQL 实现 graphql.Marshaler
+func (d Denial) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(string(d)))
+}
 
-	// Return early if user not logged in
-	if userId == 0 {
-		if fieldType.NonNull {
-			return "", nil
-		}
-		return nil, nil
+// OwnDirective @own(fields: [String!]! = ["userId"], bypass: [String!]! = [], deny: OwnDenial! = MASK) 指令
+// 字段所属对象的任意一个 fields 字段等于当前用户 ID，或当前用户拥有 bypass 中的任意一个角色时返回字段的值，否则按 deny 处理
+// fields 按 json tag 或字段名（不区分大小写）查找，支持任意整数、uint、字符串类型及其指针
+func OwnDirective(ctx context.Context, obj interface{}, next graphql.Resolver, fields []string, bypass []string, deny Denial) (interface{}, error) {
+	principal := auth.GetPrincipal(ctx)
+	if principal != nil && (principal.HasRole(bypass...) || isOwner(obj, fields, principal.UserId)) {
+		return next(ctx)
 	}
 
-	// Get result from next resolver
-	res, err := next(ctx)
-	if err != nil {
-		return nil, err
+	if deny == DenyError {
+		if principal == nil {
+			return nil, lighterr.NewUnauthorizedError("unauthorized")
+		}
+		return nil, lighterr.NewForbiddenError("forbidden")
 	}
-	if res == nil {
+
+	// 不调用 resolver，非所有者不会触发字段的查询
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Field.Definition == nil || !fc.Field.Definition.Type.NonNull {
 		return nil, nil
 	}
+	def := fc.Field.Definition
+	if deny == DenyNull {
+		logs.Error().Msgf("@own(deny: NULL) used on non-null field %s", def.Name)
+		return nil, lighterr.NewInternalError(fmt.Sprintf("@own(deny: NULL) cannot be used on non-null field %s", def.Name))
+	}
+	if zero, ok := zeroValue(def.Type); ok {
+		return zero, nil
+	}
+	logs.Error().Msgf("@own(deny: MASK) has no zero value for field %s of type %s", def.Name, def.Type)
+	return nil, lighterr.NewInternalError(fmt.Sprintf("@own(deny: MASK) has no zero value for field %s of type %s", def.Name, def.Type))
+}
 
-	// Convert object to map
-	var data map[string]interface{}
-	jsonBytes, err := sonic.Marshal(obj)
-	if err != nil {
-		return nil, err
+// zeroValue 返回内置标量类型的零值，列表、对象、枚举和自定义标量没有零值
+// 生成代码会对指令的返回值做类型断言，零值的类型需要与 gqlgen.yml 中映射的 Go 类型一致
+func zeroValue(t *ast.Type) (any, bool) {
+	if t.Elem != nil {
+		return nil, false
 	}
-	if err = sonic.Unmarshal(jsonBytes, &data); err != nil {
-		return nil, err
+	switch t.NamedType {
+	case "String":
+		return "", true
+	case "ID":
+		return uint(0), true
+	case "Int":
+		return int32(0), true
+	case "Float":
+		return 0.0, true
+	case "Boolean":
+		return false, true
 	}
+	return nil, false
+}
 
-	// Get owner ID from field
-	ownerId, ok := data[field]
-	if !ok {
-		if fieldType.NonNull {
-			return "", nil
+// isOwner obj 的任意一个 fields 字段是否等于 userId
+func isOwner(obj any, fields []string, userId uint) bool {
+	if obj == nil || userId == 0 {
+		return false
+	}
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	for _, field := range fields {
+		owner, ok := lookup(v, field)
+		if !ok {
+			logs.Error().Msgf("@own field %s not found in %s", field, v.Type())
+			continue
+		}
+		if equal(owner, userId) {
+			return true
 		}
-		return nil, nil
 	}
+	return false
+}
 
-	// Convert owner ID to uint
-	var ownId uint
-	switch v := ownerId.(type) {
-	case int64:
-		ownId = uint(v)
-	case float64:
-		ownId = uint(v)
-	default:
-		logs.Error().Msgf("invalid owner ID type: expected int64/float64, got %T", v)
-		if fieldType.NonNull {
-			return "", nil
+// lookup 读取结构体字段或 map 的值
+func lookup(v reflect.Value, name string) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.Struct:
+		index, ok := fieldIndex(v.Type(), name)
+		if !ok {
+			return reflect.Value{}, false
 		}
-		return nil, nil
+		f, err := v.FieldByIndexErr(index)
+		if err != nil {
+			return reflect.Value{}, true
+		}
+		return f, true
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		return v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key())), true
 	}
+	return reflect.Value{}, false
+}
+
+type fieldKey struct {
+	typ  reflect.Type
+	name string
+}
+
+// indexes 结构体类型和字段名对应的字段索引
+var indexes sync.Map
 
-	// Check ownership
-	if ownId != userId {
-		if fieldType.NonNull {
-			return "", nil
+func fieldIndex(typ reflect.Type, name string) ([]int, bool) {
+	key := fieldKey{typ: typ, name: name}
+	if index, ok := indexes.Load(key); ok {
+		return index.([]int), index.([]int) != nil
+	}
+	var index []int
+	for _, f := range reflect.VisibleFields(typ) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == name {
+			index = f.Index
+			break
+		}
+		if index == nil && strings.EqualFold(f.Name, name) {
+			index = f.Index
 		}
-		return nil, nil
 	}
+	indexes.Store(key, index)
+	return index, index != nil
+}
 
-	return res, nil
+// equal 比较所有者 ID 与用户 ID
+func equal(v reflect.Value, userId uint) bool {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() >= 0 && uint64(v.Int()) == uint64(userId)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == uint64(userId)
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == float64(userId)
+	case reflect.String:
+		return v.String() == strconv.FormatUint(uint64(userId), 10)
+	}
+	return false
 }
@@ -0,0 +1,90 @@
+package own
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/light-speak/lighthouse/lighterr"
+	"github.com/light-speak/lighthouse/routers/auth"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+type Base struct {
+	UserID uint
+}
+
+type Order struct {
+	Base
+	BuyerId  *int64 `json:"buyerId"`
+	SellerId string `json:"sellerId"`
+	Phone    string
+}
+
+func fieldCtx(ctx context.Context, typ *ast.Type) context.Context {
+	return graphql.WithFieldContext(ctx, &graphql.FieldContext{
+		Field: graphql.CollectedField{Field: &ast.Field{Definition: &ast.FieldDefinition{Name: "phone", Type: typ}}},
+	})
+}
+
+func TestOwnDirective(t *testing.T) {
+	buyer := int64(2)
+	order := &Order{Base: Base{UserID: 1}, BuyerId: &buyer, SellerId: "3", Phone: "13800000000"}
+	called := false
+	next := func(ctx context.Context) (any, error) {
+		called = true
+		return order.Phone, nil
+	}
+	user := func(id uint, roles ...string) context.Context {
+		return auth.WithPrincipal(context.Background(), &auth.Principal{UserId: id, Roles: roles})
+	}
+	fields := []string{"userId", "buyerId", "sellerId"}
+	str, nullable := ast.NonNullNamedType("String", nil), ast.NamedType("String", nil)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		typ      *ast.Type
+		fields   []string
+		deny     Denial
+		expected any
+		code     lighterr.ErrorCode
+	}{
+		{"Test Embedded Uint Owner", user(1), str, fields, DenyMask, "13800000000", -1},
+		{"Test Pointer Int64 Owner", user(2), str, fields, DenyMask, "13800000000", -1},
+		{"Test String Owner", user(3), str, fields, DenyMask, "13800000000", -1},
+		{"Test Single Field", user(2), str, []string{"userId"}, DenyMask, "", -1},
+		{"Test Bypass Role", user(9, "admin"), str, fields, DenyMask, "13800000000", -1},
+		{"Test Mask Nullable", user(9), nullable, fields, DenyMask, nil, -1},
+		{"Test Mask Int", user(9), ast.NonNullNamedType("Int", nil), fields, DenyMask, int32(0), -1},
+		{"Test Mask ID", user(9), ast.NonNullNamedType("ID", nil), fields, DenyMask, uint(0), -1},
+		{"Test Mask Float", user(9), ast.NonNullNamedType("Float", nil), fields, DenyMask, 0.0, -1},
+		{"Test Mask Boolean", user(9), ast.NonNullNamedType("Boolean", nil), fields, DenyMask, false, -1},
+		{"Test Mask Object", user(9), ast.NonNullNamedType("User", nil), fields, DenyMask, nil, lighterr.ErrorCodeInternalError},
+		{"Test Null", user(9), nullable, fields, DenyNull, nil, -1},
+		{"Test Null On Non-Null Field", user(9), str, fields, DenyNull, nil, lighterr.ErrorCodeInternalError},
+		{"Test Error Forbidden", user(9), str, fields, DenyError, nil, lighterr.ErrorCodeForbidden},
+		{"Test Error Guest", context.Background(), str, fields, DenyError, nil, lighterr.ErrorCodeUnauthorized},
+		{"Test Unknown Field", user(1), str, []string{"ownerId"}, DenyMask, "", -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			called = false
+			res, err := OwnDirective(fieldCtx(test.ctx, test.typ), order, next, test.fields, []string{"admin"}, test.deny)
+			if test.expected != "13800000000" && called {
+				t.Error("resolver should not be called for non-owners")
+			}
+			if test.code != -1 {
+				var e *lighterr.GraphQLError
+				if !errors.As(err, &e) || e.Code != test.code {
+					t.Errorf("expected error code %d, got %v", test.code, err)
+				}
+				return
+			}
+			if err != nil || res != test.expected {
+				t.Errorf("OwnDirective() = %#v, %v; expected %#v", res, err, test.expected)
+			}
+		})
+	}
+}
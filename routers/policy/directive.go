@@ -0,0 +1,33 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/light-speak/lighthouse/routers/auth"
+)
+
+// HasRoleDirective @hasRole(roles: [String!]!, msg: String) 指令，当前用户拥有任意一个角色时才能访问字段
+func HasRoleDirective(ctx context.Context, obj interface{}, next graphql.Resolver, roles []string, msg *string) (interface{}, error) {
+	if !auth.GetPrincipal(ctx).HasRole(roles...) {
+		return nil, deny(msg)
+	}
+	return next(ctx)
+}
+
+// CanDirective @can(ability: String!, model: String, msg: String) 指令，按登记的策略判断能否访问字段
+// 策略收到的对象为字段所属的对象（行级权限），model 为空时按该对象的类型名查找策略
+func CanDirective(ctx context.Context, obj interface{}, next graphql.Resolver, ability string, model *string, msg *string) (interface{}, error) {
+	name := ""
+	if model != nil {
+		name = *model
+	}
+	allowed, err := Allows(ctx, ability, name, obj)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, deny(msg)
+	}
+	return next(ctx)
+}
@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/light-speak/lighthouse/lighterr"
+	"github.com/light-speak/lighthouse/logs"
+	"github.com/light-speak/lighthouse/routers/auth"
+)
+
+// 权限策略
+// 按模型和操作登记策略函数，@can 指令和 Authorize 通过策略判断当前用户能否操作对象：
+//
+//	policy.Register("Post", "post.update", func(ctx context.Context, user *auth.Principal, obj any) (bool, error) {
+//		post, ok := obj.(*models.Post)
+//		return ok && (user.HasRole("admin") || post.UserId == int64(user.UserId)), nil
+//	})
+//
+// 没有登记策略的操作一律拒绝
+
+// Policy 判断当前用户能否对 obj 执行操作，未登录时 user 为 nil（可以直接调用 HasRole 等方法）
+// 用于字段时 obj 为字段所属的对象，Query / Mutation 字段的 obj 为 nil，参数通过 Args 读取
+type Policy func(ctx context.Context, user *auth.Principal, obj any) (bool, error)
+
+// Before 在所有策略之前执行，返回 true 时直接允许（如超级管理员），返回 false 时继续执行策略
+type Before func(ctx context.Context, user *auth.Principal, ability string) bool
+
+var (
+	mu       sync.RWMutex
+	policies = make(map[string]Policy)
+	befores  = make([]Before, 0)
+)
+
+// Register 登记 model 的 ability 策略，model 为 GraphQL 类型名（即 Go 结构体名），全局操作的 model 为空
+func Register(model string, ability string, policy Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	policies[key(model, ability)] = policy
+}
+
+// AddBefore 登记在所有策略之前执行的检查
+func AddBefore(before Before) {
+	mu.Lock()
+	defer mu.Unlock()
+	befores = append(befores, before)
+}
+
+// Reset 清空登记的策略，用于测试
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	policies = make(map[string]Policy)
+	befores = make([]Before, 0)
+}
+
+// Allows 当前用户能否对 obj 执行 ability，model 为空时按 obj 的类型名查找策略
+func Allows(ctx context.Context, ability string, model string, obj any) (bool, error) {
+	user := auth.GetPrincipal(ctx)
+	if model == "" {
+		model = ModelName(obj)
+	}
+
+	mu.RLock()
+	checks := append([]Before(nil), befores...)
+	policy, ok := policies[key(model, ability)]
+	mu.RUnlock()
+
+	for _, before := range checks {
+		if before(ctx, user, ability) {
+			return true, nil
+		}
+	}
+	if !ok {
+		logs.Warn().Str("model", model).Str("ability", ability).Msg("no policy registered, access denied")
+		return false, nil
+	}
+	return policy(ctx, user, obj)
+}
+
+// Authorize 与 Allows 相同，拒绝时返回 Forbidden 错误
+func Authorize(ctx context.Context, ability string, model string, obj any) error {
+	allowed, err := Allows(ctx, ability, model, obj)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return deny(nil)
+	}
+	return nil
+}
+
+// Args 返回当前字段的参数，用于在 Query / Mutation 字段的策略中读取 id 等参数
+func Args(ctx context.Context) map[string]any {
+	if fc := graphql.GetFieldContext(ctx); fc != nil {
+		return fc.Args
+	}
+	return nil
+}
+
+// ModelName 返回对象的类型名，指针和切片取元素类型，nil 返回空字符串
+func ModelName(obj any) string {
+	if obj == nil {
+		return ""
+	}
+	typ := reflect.TypeOf(obj)
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return typ.Name()
+}
+
+func key(model string, ability string) string {
+	return fmt.Sprintf("%s:%s", model, ability)
+}
+
+// deny 拒绝时返回 Forbidden，msg 不为空时作为错误信息
+func deny(msg *string) error {
+	if msg != nil {
+		return lighterr.NewForbiddenError(*msg)
+	}
+	return lighterr.NewForbiddenError("forbidden")
+}
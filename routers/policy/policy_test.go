@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/light-speak/lighthouse/lighterr"
+	"github.com/light-speak/lighthouse/routers/auth"
+)
+
+type Post struct {
+	Id     int64
+	UserId int64
+}
+
+func next(ctx context.Context) (any, error) {
+	return "ok", nil
+}
+
+func code(err error) lighterr.ErrorCode {
+	var e *lighterr.GraphQLError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return -1
+}
+
+func TestDirectives(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	Register("Post", "post.update", func(ctx context.Context, user *auth.Principal, obj any) (bool, error) {
+		post, ok := obj.(*Post)
+		return ok && user != nil && post.UserId == int64(user.UserId), nil
+	})
+	Register("", "report.export", func(ctx context.Context, user *auth.Principal, obj any) (bool, error) {
+		return user.HasScope("report:export"), nil
+	})
+	AddBefore(func(ctx context.Context, user *auth.Principal, ability string) bool {
+		return user.HasRole("root")
+	})
+
+	owner := auth.WithPrincipal(context.Background(), &auth.Principal{UserId: 1, Roles: []string{"editor"}})
+	other := auth.WithPrincipal(context.Background(), &auth.Principal{UserId: 2, Scopes: []string{"report:export"}})
+	root := auth.WithPrincipal(context.Background(), &auth.Principal{UserId: 3, Roles: []string{"root"}})
+	guest := context.Background()
+	post := &Post{Id: 1, UserId: 1}
+	model := "Post"
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		run      func(ctx context.Context) (any, error)
+		expected lighterr.ErrorCode
+	}{
+		{"Test HasRole Allowed", owner, func(ctx context.Context) (any, error) {
+			return HasRoleDirective(ctx, nil, next, []string{"admin", "editor"}, nil)
+		}, -1},
+		{"Test HasRole Forbidden", other, func(ctx context.Context) (any, error) {
+			return HasRoleDirective(ctx, nil, next, []string{"editor"}, nil)
+		}, lighterr.ErrorCodeForbidden},
+		{"Test HasRole Guest", guest, func(ctx context.Context) (any, error) {
+			return HasRoleDirective(ctx, nil, next, []string{"editor"}, nil)
+		}, lighterr.ErrorCodeForbidden},
+		{"Test Can Owner", owner, func(ctx context.Context) (any, error) {
+			return CanDirective(ctx, post, next, "post.update", nil, nil)
+		}, -1},
+		{"Test Can Other", other, func(ctx context.Context) (any, error) {
+			return CanDirective(ctx, post, next, "post.update", &model, nil)
+		}, lighterr.ErrorCodeForbidden},
+		{"Test Can Before", root, func(ctx context.Context) (any, error) {
+			return CanDirective(ctx, post, next, "post.update", nil, nil)
+		}, -1},
+		{"Test Can Global Ability", other, func(ctx context.Context) (any, error) {
+			return CanDirective(ctx, nil, next, "report.export", nil, nil)
+		}, -1},
+		{"Test Can Unregistered", owner, func(ctx context.Context) (any, error) {
+			return CanDirective(ctx, post, next, "post.delete", nil, nil)
+		}, lighterr.ErrorCodeForbidden},
+		{"Test Can Guest", guest, func(ctx context.Context) (any, error) {
+			return CanDirective(ctx, post, next, "post.update", nil, nil)
+		}, lighterr.ErrorCodeForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := test.run(test.ctx)
+			if test.expected == -1 {
+				if err != nil || res != "ok" {
+					t.Errorf("expected allowed, got %v, %v", res, err)
+				}
+				return
+			}
+			if res != nil || code(err) != test.expected {
+				t.Errorf("expected code %d, got %v, %v", test.expected, res, err)
+			}
+		})
+	}
+}
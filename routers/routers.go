@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/light-speak/lighthouse/routers/admin"
 	"github.com/light-speak/lighthouse/routers/health"
 	"github.com/rs/cors"
 )
@@ -40,4 +41,12 @@ func registerSystemRoutes(r *chi.Mux) {
 		w.Write([]byte("OK"))
 	})
 	r.Get(Config.ReadinessPath, health.ReadinessHandler)
+
+	// 管理接口需要配置 MID_ADMIN_TOKEN 才会注册
+	if Config.AdminToken != "" && Config.SlowQueryPath != "" {
+		r.With(admin.RequireToken(Config.AdminToken)).
+			Method(http.MethodGet, Config.SlowQueryPath, http.HandlerFunc(admin.SlowQueriesHandler))
+		r.With(admin.RequireToken(Config.AdminToken)).
+			Method(http.MethodDelete, Config.SlowQueryPath, http.HandlerFunc(admin.SlowQueriesHandler))
+	}
 }
@@ -0,0 +1,96 @@
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/light-speak/lighthouse/databases"
+	"github.com/light-speak/lighthouse/lighterr"
+	"github.com/light-speak/lighthouse/routers/auth"
+)
+
+// DefaultClaim 默认读取租户的 JWT claim
+const DefaultClaim = "tenant_id"
+
+// Resolver 从请求中解析租户 ID，返回空字符串表示当前方式无法识别租户
+type Resolver func(r *http.Request) (string, error)
+
+// Middleware 按顺序使用 resolvers 解析租户，第一个非空结果写入 ctx（databases.WithTenant）
+// resolvers 为空时从 auth.Middleware 写入的 Principal 的 tenant_id claim 读取，需要注册在 auth.Middleware 之后；
+// 解析返回 Unauthorized 错误时返回 401，其他解析失败返回 400，无法识别租户时不写入，由数据库层拒绝访问租户数据
+func Middleware(resolvers ...Resolver) func(http.Handler) http.Handler {
+	if len(resolvers) == 0 {
+		resolvers = []Resolver{FromClaim(DefaultClaim)}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, resolve := range resolvers {
+				tenant, err := resolve(r)
+				if err != nil {
+					status := http.StatusBadRequest
+					var gqlErr *lighterr.GraphQLError
+					if errors.As(err, &gqlErr) && gqlErr.Code == lighterr.ErrorCodeUnauthorized {
+						status = http.StatusUnauthorized
+					}
+					http.Error(w, err.Error(), status)
+					return
+				}
+				if tenant != "" {
+					r = r.WithContext(databases.WithTenant(r.Context(), tenant))
+					break
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// FromHeader 从请求头读取租户，请求头可以被客户端伪造，只应在网关已校验租户时使用
+func FromHeader(name string) Resolver {
+	return func(r *http.Request) (string, error) {
+		return strings.TrimSpace(r.Header.Get(name)), nil
+	}
+}
+
+// FromClaim 从 auth.Middleware 写入 ctx 的 Principal 中读取租户 claim，未登录时无法识别租户
+// token 已由 auth.Middleware 校验，已吊销的 token 与 refresh token 不会产生 Principal
+func FromClaim(name string) Resolver {
+	return func(r *http.Request) (string, error) {
+		principal := auth.GetPrincipal(r.Context())
+		if principal == nil {
+			return "", nil
+		}
+		if name == DefaultClaim {
+			return principal.TenantId, nil
+		}
+		switch v := principal.Claim(name).(type) {
+		case nil:
+			return "", nil
+		case string:
+			return v, nil
+		case float64:
+			return fmt.Sprintf("%.0f", v), nil
+		default:
+			return "", fmt.Errorf("invalid tenant claim %s", name)
+		}
+	}
+}
+
+// FromSubdomain 从子域名读取租户，如 baseDomain 为 example.com 时 acme.example.com 的租户为 acme
+func FromSubdomain(baseDomain string) Resolver {
+	suffix := "." + strings.TrimPrefix(strings.ToLower(baseDomain), ".")
+	return func(r *http.Request) (string, error) {
+		host := strings.ToLower(r.Host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		sub, ok := strings.CutSuffix(host, suffix)
+		if !ok || sub == "" || strings.Contains(sub, ".") {
+			return "", nil
+		}
+		return sub, nil
+	}
+}
@@ -0,0 +1,75 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/light-speak/lighthouse/databases"
+	"github.com/light-speak/lighthouse/routers/auth"
+)
+
+func TestMiddleware(t *testing.T) {
+	auth.SetAuthenticator(auth.NewHS256([]byte("secret"), auth.WithTTL(time.Minute)))
+	auth.SetRevocationStore(auth.NewMemoryStore())
+	t.Cleanup(func() {
+		auth.SetAuthenticator(nil)
+		auth.SetRevocationStore(nil)
+	})
+	ctx := context.Background()
+
+	pair, err := auth.IssueTokenPair(ctx, 1, map[string]any{"tenant_id": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := auth.IssueTokenPair(ctx, 2, map[string]any{"tenant_id": 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.Revoke(ctx, revoked.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		status int
+		tenant string
+	}{
+		{"Test Access Token", map[string]string{"Authorization": "Bearer " + pair.AccessToken}, http.StatusOK, "3"},
+		{"Test Refresh Token Rejected", map[string]string{"Authorization": "Bearer " + pair.RefreshToken}, http.StatusUnauthorized, ""},
+		{"Test Revoked Token Rejected", map[string]string{"Authorization": "Bearer " + revoked.AccessToken}, http.StatusUnauthorized, ""},
+		{"Test Header Ignored By Default", map[string]string{"X-Tenant-Id": "9"}, http.StatusOK, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var tenant string
+			handler := auth.Middleware()(Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tenant, _ = databases.TenantFromContext(r.Context())
+			})))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range test.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != test.status || tenant != test.tenant {
+				t.Errorf("status = %d, tenant = %q; expected %d, %q", rec.Code, tenant, test.status, test.tenant)
+			}
+		})
+	}
+
+	t.Run("Test Claim From Principal", func(t *testing.T) {
+		principal := &auth.Principal{UserId: 5, TenantId: "7", Claims: map[string]any{"org": float64(8)}}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+		for name, expected := range map[string]string{DefaultClaim: "7", "org": "8"} {
+			if tenant, err := FromClaim(name)(req); err != nil || tenant != expected {
+				t.Errorf("FromClaim(%s) = %q, %v; expected %q", name, tenant, err, expected)
+			}
+		}
+	})
+}
@@ -1,13 +1,11 @@
 package storages
 
 import (
-	"log"
-	"os"
-	"path/filepath"
+	"fmt"
+	"sync"
 
-	"github.com/joho/godotenv"
+	"github.com/light-speak/lighthouse/config"
 	"github.com/light-speak/lighthouse/logs"
-	"github.com/light-speak/lighthouse/utils"
 )
 
 // StorageDriver 存储驱动类型
@@ -20,135 +18,155 @@ const (
 
 // StorageConfig 存储配置
 type StorageConfig struct {
-	Driver StorageDriver // 存储驱动
+	Driver StorageDriver `env:"STORAGE_DRIVER"` // 存储驱动
 
-	UseCDN bool // 是否使用 CDN
+	UseCDN bool `env:"USE_CDN"` // 是否使用 CDN
 
 	// S3 配置
 	S3 struct {
-		Endpoint        string // 端点地址
-		AccessKeyID     string // 访问密钥 ID
-		SecretAccessKey string // 访问密钥
-		UseSSL          bool   // 是否使用 SSL
-		DefaultBucket   string // 默认存储桶
-		CDN             string // CDN 地址
+		Endpoint        string `env:"S3_ENDPOINT"`                 // 端点地址
+		AccessKeyID     string `env:"S3_ACCESS_KEY"`               // 访问密钥 ID
+		SecretAccessKey string `env:"S3_SECRET_KEY" secret:"true"` // 访问密钥
+		UseSSL          bool   `env:"S3_USE_SSL"`                  // 是否使用 SSL
+		DefaultBucket   string `env:"S3_DEFAULT_BUCKET"`           // 默认存储桶
+		CDN             string `env:"S3_CDN"`                      // CDN 地址
 	}
 
 	// COS 配置
 	COS struct {
-		SecretID      string // 密钥 ID
-		SecretKey     string // 密钥
-		Region        string // 地域
-		DefaultBucket string // 默认存储桶
-		CDN           string // CDN 地址
+		SecretID      string `env:"COS_SECRET_ID"`                // 密钥 ID
+		SecretKey     string `env:"COS_SECRET_KEY" secret:"true"` // 密钥
+		Region        string `env:"COS_REGION"`                   // 地域
+		DefaultBucket string `env:"COS_DEFAULT_BUCKET"`           // 默认存储桶
+		CDN           string `env:"COS_CDN"`                      // CDN 地址
 	}
 }
 
 var (
-	config  *StorageConfig
+	current *StorageConfig
 	storage Storage
+	mu      sync.Mutex
 )
 
-func init() {
-	// 初始化默认配置
-	config = &StorageConfig{
+// DefaultConfig 返回默认配置
+func DefaultConfig() *StorageConfig {
+	cfg := &StorageConfig{
 		Driver: DriverS3,
 		UseCDN: false,
 	}
-	config.S3.Endpoint = "localhost:9000"
-	config.S3.AccessKeyID = ""
-	config.S3.SecretAccessKey = ""
-	config.S3.UseSSL = false
-	config.S3.DefaultBucket = "default"
-	config.S3.CDN = ""
-
-	config.COS.SecretID = ""
-	config.COS.SecretKey = ""
-	config.COS.Region = "ap-beijing"
-	config.COS.DefaultBucket = "default"
-	config.COS.CDN = ""
-
-	// 加载 .env 文件
-	if curPath, err := os.Getwd(); err == nil {
-		err = godotenv.Load(filepath.Join(curPath, ".env"))
+	cfg.S3.Endpoint = "localhost:9000"
+	cfg.S3.AccessKeyID = ""
+	cfg.S3.SecretAccessKey = ""
+	cfg.S3.UseSSL = false
+	cfg.S3.DefaultBucket = "default"
+	cfg.S3.CDN = ""
+
+	cfg.COS.SecretID = ""
+	cfg.COS.SecretKey = ""
+	cfg.COS.Region = "ap-beijing"
+	cfg.COS.DefaultBucket = "default"
+	cfg.COS.CDN = ""
+	return cfg
+}
+
+// LoadConfig 从配置文件和环境变量读取配置
+func LoadConfig() (*StorageConfig, error) {
+	cfg := DefaultConfig()
+	if err := config.Bind(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func init() {
+	config.Register("storage", func() (any, error) { return LoadConfig() })
+}
+
+// Init 使用给定配置初始化存储实例，cfg 为 nil 时通过 LoadConfig 加载
+// 未配置密钥时跳过初始化，不视为错误
+func Init(cfg *StorageConfig) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if cfg == nil {
+		loaded, err := LoadConfig()
 		if err != nil {
-			log.Println("Error loading .env file:", err)
+			return err
 		}
+		cfg = loaded
 	}
+	current = cfg
+	storage = nil
+	return initStorage()
+}
 
-	// 读取存储驱动配置
-	config.Driver = StorageDriver(utils.GetEnv("STORAGE_DRIVER", string(config.Driver)))
-	config.UseCDN = utils.GetEnvBool("USE_CDN", config.UseCDN)
-
-	// 读取 S3 配置
-	config.S3.Endpoint = utils.GetEnv("S3_ENDPOINT", config.S3.Endpoint)
-	config.S3.AccessKeyID = utils.GetEnv("S3_ACCESS_KEY", config.S3.AccessKeyID)
-	config.S3.SecretAccessKey = utils.GetEnv("S3_SECRET_KEY", config.S3.SecretAccessKey)
-	config.S3.UseSSL = utils.GetEnvBool("S3_USE_SSL", config.S3.UseSSL)
-	config.S3.DefaultBucket = utils.GetEnv("S3_DEFAULT_BUCKET", config.S3.DefaultBucket)
-	config.S3.CDN = utils.GetEnv("S3_CDN", config.S3.CDN)
-
-	// 读取 COS 配置
-	config.COS.SecretID = utils.GetEnv("COS_SECRET_ID", config.COS.SecretID)
-	config.COS.SecretKey = utils.GetEnv("COS_SECRET_KEY", config.COS.SecretKey)
-	config.COS.Region = utils.GetEnv("COS_REGION", config.COS.Region)
-	config.COS.DefaultBucket = utils.GetEnv("COS_DEFAULT_BUCKET", config.COS.DefaultBucket)
-	config.COS.CDN = utils.GetEnv("COS_CDN", config.COS.CDN)
-
-	// 初始化存储实例
-	initStorage()
+// ensure 首次使用时按 LoadConfig 的结果初始化
+func ensure() {
+	mu.Lock()
+	defer mu.Unlock()
+	if current != nil {
+		return
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		logs.Error().Err(err).Msg("failed to load storage config")
+		cfg = DefaultConfig()
+	}
+	current = cfg
+	if err := initStorage(); err != nil {
+		logs.Error().Err(err).Msg("failed to initialize storage")
+	}
 }
 
-// initStorage 根据配置初始化存储实例
-func initStorage() {
+// initStorage 根据配置初始化存储实例，调用方需持有 mu
+func initStorage() error {
 	var err error
 
-	switch config.Driver {
+	switch current.Driver {
 	case DriverS3:
-		if config.S3.AccessKeyID == "" || config.S3.SecretAccessKey == "" {
+		if current.S3.AccessKeyID == "" || current.S3.SecretAccessKey == "" {
 			logs.Warn().Msg("S3 storage not configured properly, skipping initialization")
-			return
+			return nil
 		}
 
 		s3Config := S3Config{
-			Endpoint:        config.S3.Endpoint,
-			AccessKeyID:     config.S3.AccessKeyID,
-			SecretAccessKey: config.S3.SecretAccessKey,
-			UseSSL:          config.S3.UseSSL,
-			UseCDN:          config.UseCDN,
+			Endpoint:        current.S3.Endpoint,
+			AccessKeyID:     current.S3.AccessKeyID,
+			SecretAccessKey: current.S3.SecretAccessKey,
+			UseSSL:          current.S3.UseSSL,
+			UseCDN:          current.UseCDN,
 		}
 		storage, err = NewS3Storage(s3Config)
 		if err != nil {
-			logs.Error().Err(err).Msg("failed to initialize S3 storage")
-			return
+			return fmt.Errorf("failed to initialize S3 storage: %w", err)
 		}
-		logs.Info().Msgf("S3 storage initialized successfully, bucket: %s", config.S3.DefaultBucket)
+		logs.Info().Msgf("S3 storage initialized successfully, bucket: %s", current.S3.DefaultBucket)
 
 	case DriverCOS:
-		if config.COS.SecretID == "" || config.COS.SecretKey == "" {
+		if current.COS.SecretID == "" || current.COS.SecretKey == "" {
 			logs.Warn().Msg("COS storage not configured properly, skipping initialization")
-			return
+			return nil
 		}
 
 		cosConfig := COSConfig{
-			SecretID:  config.COS.SecretID,
-			SecretKey: config.COS.SecretKey,
-			Region:    config.COS.Region,
+			SecretID:  current.COS.SecretID,
+			SecretKey: current.COS.SecretKey,
+			Region:    current.COS.Region,
 		}
 		storage, err = NewCOSStorage(cosConfig)
 		if err != nil {
-			logs.Error().Err(err).Msg("failed to initialize COS storage")
-			return
+			return fmt.Errorf("failed to initialize COS storage: %w", err)
 		}
 		logs.Info().Msg("COS storage initialized successfully")
 
 	default:
-		logs.Error().Str("driver", string(config.Driver)).Msg("unsupported storage driver")
+		return fmt.Errorf("unsupported storage driver: %s", current.Driver)
 	}
+	return nil
 }
 
 // GetStorage 获取存储实例
 func GetStorage() (Storage, error) {
+	ensure()
 	if storage == nil {
 		return nil, ErrStorageNotInitialized
 	}
@@ -157,16 +175,17 @@ func GetStorage() (Storage, error) {
 
 // GetConfig 获取存储配置
 func GetConfig() *StorageConfig {
-	return config
+	ensure()
+	return current
 }
 
 // GetDefaultBucket 获取默认存储桶名称
 func GetDefaultBucket() string {
-	switch config.Driver {
+	switch GetConfig().Driver {
 	case DriverS3:
-		return config.S3.DefaultBucket
+		return current.S3.DefaultBucket
 	case DriverCOS:
-		return config.COS.DefaultBucket
+		return current.COS.DefaultBucket
 	default:
 		return "default"
 	}